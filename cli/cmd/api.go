@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// apiCmd is a generic passthrough for endpoints without a dedicated command.
+var apiCmd = &cobra.Command{
+	Use:   "api [method] [path]",
+	Short: "Send a raw request to any API endpoint",
+	Long: `Send a raw request to any API endpoint and stream the response to stdout.
+
+The request body is given with --data. As with curl, a value starting with
+"@" names a file to read the body from ("@-" reads stdin); the file is
+streamed rather than loaded into memory, so bulk imports of any size work.
+
+Examples:
+  sf api GET /api/scans
+  sf api POST /api/scans -d '{"target": "example.com", "scan_name": "x"}'
+  sf api POST /api/import -d @events.json
+  sf api GET /api/scans/<id>/export?format=json > export.json
+
+--dry-run prints the request that would be sent, with credentials redacted,
+and --curl prints an equivalent curl command that reads the credentials from
+$SF_API_KEY or $SF_TOKEN. Neither sends anything.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method, path := strings.ToUpper(args[0]), args[1]
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		data, _ := cmd.Flags().GetString("data")
+		dataFile, _ := cmd.Flags().GetString("data-file")
+		contentType, _ := cmd.Flags().GetString("content-type")
+
+		if data != "" && dataFile != "" {
+			return fmt.Errorf("--data and --data-file are mutually exclusive")
+		}
+		if dataFile != "" {
+			data = "@" + strings.TrimPrefix(dataFile, "@")
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		curl, _ := cmd.Flags().GetBool("curl")
+		if dryRun && curl {
+			return fmt.Errorf("--dry-run and --curl are mutually exclusive")
+		}
+		if dryRun || curl {
+			// Describe the body without reading it; stdin stays unread.
+			var body io.Reader
+			if data != "" {
+				body = strings.NewReader(data)
+			}
+			req, err := client.New().StreamRequest(method, path, body, contentType)
+			if err != nil {
+				return err
+			}
+			if curl {
+				fmt.Println(curlCommand(req, data))
+			} else {
+				printDryRun(req, data)
+			}
+			return nil
+		}
+
+		var body io.Reader
+		switch {
+		case data == "@-":
+			body = os.Stdin
+		case strings.HasPrefix(data, "@"):
+			f, err := os.Open(data[1:])
+			if err != nil {
+				return fmt.Errorf("opening request body: %w", err)
+			}
+			defer f.Close()
+			body = f
+		case data != "":
+			body = strings.NewReader(data)
+		}
+
+		c := client.New()
+		// Streamed transfers may legitimately take longer than the default
+		// whole-request timeout.
+		c.HTTPClient.Timeout = 0
+
+		resp, err := c.Stream(method, path, body, contentType)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if resp.StatusCode < 400 {
+				recordHistory(cmd, args, "")
+			}
+		}
+
+		if viper.GetBool("flatten") && !output.Structured() && resp.StatusCode < 400 && strings.Contains(resp.Header.Get("Content-Type"), "json") {
+			var v interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+				return fmt.Errorf("decoding response: %w", err)
+			}
+			output.PrintFlattened(v)
+			return nil
+		}
+		if _, err := io.Copy(os.Stdout, resp.Body); err != nil {
+			return fmt.Errorf("reading response: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			return &client.HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, RequestID: client.RequestID(resp.Header)}
+		}
+		return nil
+	},
+}
+
+// printDryRun describes req as --dry-run shows it. Credentials are redacted
+// and a file or stdin body is named rather than read.
+func printDryRun(req *http.Request, data string) {
+	fmt.Printf("%s %s\n", req.Method, req.URL)
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := req.Header.Get(name)
+		switch name {
+		case "Authorization":
+			value = "Bearer [REDACTED]"
+		case "X-Api-Key":
+			value = "[REDACTED]"
+		}
+		fmt.Printf("%s: %s\n", name, value)
+	}
+	switch {
+	case data == "@-":
+		fmt.Println("\n(body read from stdin)")
+	case strings.HasPrefix(data, "@"):
+		size := "unreadable"
+		if fi, err := os.Stat(data[1:]); err == nil {
+			size = fmt.Sprintf("%d bytes", fi.Size())
+		}
+		fmt.Printf("\n(body streamed from %s, %s)\n", data[1:], size)
+	case data != "":
+		fmt.Printf("\n%s\n", data)
+	}
+}
+
+// curlCommand returns a curl command line equivalent to req. Credentials are
+// referenced as $SF_TOKEN or $SF_API_KEY so they never appear in the output,
+// and an @file body is passed to curl to read.
+func curlCommand(req *http.Request, data string) string {
+	parts := []string{"curl", "-X", req.Method}
+	if viper.GetBool("insecure") {
+		parts = append(parts, "-k")
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch name {
+		case "Authorization":
+			parts = append(parts, "-H", `"Authorization: Bearer $SF_TOKEN"`)
+		case "X-Api-Key":
+			parts = append(parts, "-H", `"X-API-Key: $SF_API_KEY"`)
+		default:
+			parts = append(parts, "-H", shellQuote(name+": "+req.Header.Get(name)))
+		}
+	}
+	if data != "" {
+		parts = append(parts, "--data-binary", shellQuote(data))
+	}
+	parts = append(parts, shellQuote(req.URL.String()))
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	apiCmd.Flags().Bool("dry-run", false, "Print the request (method, URL, headers with credentials redacted, body) instead of sending it")
+	apiCmd.Flags().Bool("curl", false, "Print an equivalent curl command instead of sending the request")
+	apiCmd.Flags().StringP("data", "d", "", "Request body, or @file / @- to stream from a file or stdin")
+	apiCmd.Flags().String("data-file", "", "Stream the request body from this file (same as --data @file)")
+	apiCmd.Flags().String("content-type", "application/json", "Content-Type of the request body")
+
+	rootCmd.AddCommand(apiCmd)
+}
@@ -53,8 +53,8 @@ var asmAssetsCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Type", "Value", "Risk", "Status"}
@@ -92,6 +92,9 @@ var asmAssetGetCmd = &cobra.Command{
 		if err := c.Get(fmt.Sprintf("/api/asm/assets/%s", args[0]), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -116,6 +119,7 @@ var asmRisksCmd = &cobra.Command{
 }
 
 func init() {
+	addJSONPathFlag(asmAssetGetCmd)
 	asmAssetsCmd.Flags().String("type", "", "Filter by asset type")
 	asmAssetsCmd.Flags().String("risk", "", "Filter by risk level")
 	asmAssetsCmd.Flags().String("status", "", "Filter by status")
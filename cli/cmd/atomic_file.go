@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes path through write into a temporary file next to
+// it, then renames the file into place, so a failed or interrupted write
+// never leaves a truncated file behind or clobbers an older one. Errors from
+// write are returned as is.
+func writeFileAtomic(path string, write func(f *os.File) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	defer onInterrupt(func() { os.Remove(tmp.Name()) })()
+
+	err = write(tmp)
+	if cerr := tmp.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("writing file: %w", cerr)
+	}
+	if err == nil {
+		if rerr := os.Rename(tmp.Name(), path); rerr != nil {
+			err = fmt.Errorf("writing file: %w", rerr)
+		}
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// writeBytesAtomic is writeFileAtomic for data already in memory.
+func writeBytesAtomic(path string, data []byte) error {
+	return writeFileAtomic(path, func(f *os.File) error {
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		return nil
+	})
+}
@@ -61,8 +61,8 @@ var auditListCmd = &cobra.Command{
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"Time", "Action", "Actor", "Resource", "Severity"}
@@ -109,8 +109,8 @@ var auditStatsCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
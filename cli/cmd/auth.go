@@ -33,9 +33,10 @@ var authLoginCmd = &cobra.Command{
 		if err := c.Post("/api/auth/login", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, "")
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if token, ok := resp["access_token"].(string); ok {
 				output.Success("Login successful")
@@ -74,8 +75,8 @@ var authUsersCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Username", "Role", "Status"}
@@ -107,6 +108,7 @@ var authLogoutCmd = &cobra.Command{
 		if err := c.Post("/api/auth/logout", nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, "")
 		output.Success("Logged out")
 		return nil
 	},
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+// addCacheFlags registers --offline and --refresh on a command that reads
+// through the response cache.
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("offline", false, "Serve the response from the local cache only, failing if it is not cached")
+	cmd.Flags().Bool("refresh", false, "Ignore cached responses and fetch fresh data (still updates the cache)")
+}
+
+// responseCache returns the response cache for cmd, or nil if caching is
+// disabled. The cache is enabled by a positive cache_ttl config value; --offline
+// reads it regardless of age.
+func responseCache(cmd *cobra.Command) (*client.Cache, error) {
+	offline, _ := cmd.Flags().GetBool("offline")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	if offline && refresh {
+		return nil, fmt.Errorf("--offline and --refresh are mutually exclusive")
+	}
+	ttl := viper.GetDuration("cache_ttl")
+	if ttl <= 0 && !offline {
+		return nil, nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return &client.Cache{Dir: filepath.Join(dir, "cache"), TTL: ttl, Offline: offline, Refresh: refresh}, nil
+}
@@ -2,6 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,14 +24,14 @@ var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current CLI configuration",
 	Run: func(cmd *cobra.Command, args []string) {
-		keys := []string{"server", "api_key", "token", "output", "no_color", "insecure"}
+		keys := []string{"profile", "server", "api_key", "token", "output", "auto_output", "no_color", "timeout", "insecure"}
 		switch output.Current() {
-		case output.JSON:
+		case output.JSON, output.YAML:
 			m := make(map[string]interface{})
 			for _, k := range keys {
 				m[k] = viper.Get(k)
 			}
-			output.PrintJSON(m)
+			output.PrintStructured(m)
 		default:
 			for _, k := range keys {
 				val := viper.GetString(k)
@@ -48,25 +52,189 @@ var configSetCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key, value := args[0], args[1]
-		viper.Set(key, value)
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile != "" {
+			key = "profiles." + profile + "." + key
+		}
+
+		v, configFile, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		v.Set(key, value)
+		if err := writeConfigFile(v, configFile, detectConfigType(configFile)); err != nil {
+			return err
+		}
+		output.Success("Set %s=%s in %s", key, value, configFile)
+		return nil
+	},
+}
+
+var configCopyProfileCmd = &cobra.Command{
+	Use:   "copy-profile [src] [dst]",
+	Short: "Clone an existing profile under a new name",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, dst := args[0], args[1]
+		force, _ := cmd.Flags().GetBool("force")
+
+		v, configFile, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		settings := v.GetStringMap("profiles." + src)
+		if len(settings) == 0 {
+			return fmt.Errorf("profile %q not found in %s", src, configFile)
+		}
+		if v.IsSet("profiles."+dst) && !force {
+			return fmt.Errorf("profile %q already exists (use --force to overwrite)", dst)
+		}
+
+		clone := make(map[string]interface{}, len(settings))
+		for k, val := range settings {
+			clone[k] = val
+		}
+		v.Set("profiles."+dst, clone)
+		if err := writeConfigFile(v, configFile, detectConfigType(configFile)); err != nil {
+			return err
+		}
+		output.Success("Copied profile %s to %s in %s", src, dst, configFile)
+		return nil
+	},
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a starter CLI config file",
+	Long: `Create a starter CLI config file.
+
+The file is written to --config if given, otherwise to ~/.spiderfoot.<format>.
+The format comes from --format, else the --config file extension, else YAML.
+A --format that contradicts a recognized --config extension is an error.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		force, _ := cmd.Flags().GetBool("force")
+
+		path := cfgFile
+		if format == "" {
+			format = "yaml"
+			if path != "" {
+				format = detectConfigType(path)
+			}
+		}
+		if !validConfigFormat(format) {
+			return fmt.Errorf("invalid --format %q (expected %s)", format, strings.Join(configFormats, ", "))
+		}
+		if ext := configExtType(path); ext != "" && ext != format {
+			return fmt.Errorf("--format %s does not match the %s extension of --config %s", format, ext, path)
+		}
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(home, ".spiderfoot."+format)
+		}
 
-		configFile := viper.ConfigFileUsed()
-		if configFile == "" {
-			home, _ := cmd.Root().PersistentFlags().GetString("config")
-			if home == "" {
-				return fmt.Errorf("no config file found — use --config flag or create ~/.spiderfoot.yaml")
+		if !force {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+			if used := viper.ConfigFileUsed(); used != "" && used != path {
+				return fmt.Errorf("config file %s already exists (use --force to create another)", used)
 			}
-			configFile = home
 		}
 
-		if err := viper.WriteConfigAs(configFile); err != nil {
-			return fmt.Errorf("writing config: %w", err)
+		v := viper.New()
+		v.Set("server", viper.GetString("server"))
+		v.Set("output", "auto")
+		if err := writeConfigFile(v, path, format); err != nil {
+			return err
 		}
-		output.Success("Set %s=%s in %s", key, value, configFile)
+		output.Success("Wrote %s config to %s", format, path)
 		return nil
 	},
 }
 
+// configFormats are the config file formats the CLI reads and writes.
+var configFormats = []string{"yaml", "json", "toml"}
+
+var tomlLineRe = regexp.MustCompile(`^(\[[^\]]+\]|[A-Za-z0-9_.\-"]+\s*=)`)
+
+func validConfigFormat(format string) bool {
+	for _, f := range configFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// configExtType returns the config format named by the extension of path, or
+// "" if the extension is not one the CLI recognizes.
+func configExtType(path string) string {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "yaml", "yml":
+		return "yaml"
+	case "json", "toml":
+		return ext
+	}
+	return ""
+}
+
+// detectConfigType returns the format of the config file at path, taken from
+// its extension when recognized and otherwise sniffed from its content.
+// Missing or unrecognizable files are treated as YAML.
+func detectConfigType(path string) string {
+	if format := configExtType(path); format != "" {
+		return format
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "yaml"
+	}
+	return sniffConfigType(data)
+}
+
+// sniffConfigType guesses a config format from file content: JSON starts with
+// '{', TOML's first statement is a [table] header or key = value pair.
+func sniffConfigType(data []byte) string {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		return "json"
+	}
+	for _, line := range strings.Split(trimmed, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if tomlLineRe.MatchString(line) {
+			return "toml"
+		}
+		break
+	}
+	return "yaml"
+}
+
+// writeConfigFile writes v to path in format. Viper picks the encoding from
+// the file extension, so the file is written under a temporary name with the
+// right extension and renamed into place; this also keeps the write atomic.
+func writeConfigFile(v *viper.Viper, path, format string) error {
+	tmp := fmt.Sprintf("%s.tmp.%s", path, format)
+	if err := v.WriteConfigAs(tmp); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := os.Chmod(tmp, 0600); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
 // --- Remote server config subcommands (via /api/config/*) ---
 
 var configRemoteCmd = &cobra.Command{
@@ -112,6 +280,7 @@ var configRemoteReloadCmd = &cobra.Command{
 		if err := c.Post("/api/config/reload", nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, "")
 		output.Success("Server configuration reloaded")
 		return nil
 	},
@@ -142,6 +311,11 @@ var configRemoteEnvironmentCmd = &cobra.Command{
 }
 
 func init() {
+	configSetCmd.Flags().String("profile", "", "Write the value into this profile instead of the top level")
+	configCopyProfileCmd.Flags().Bool("force", false, "Overwrite the destination profile if it exists")
+	configInitCmd.Flags().String("format", "", "Config file format: yaml, json, or toml")
+	configInitCmd.Flags().Bool("force", false, "Overwrite an existing config file")
+
 	configRemoteCmd.AddCommand(configRemoteShowCmd)
 	configRemoteCmd.AddCommand(configRemoteModulesCmd)
 	configRemoteCmd.AddCommand(configRemoteKeysCmd)
@@ -155,6 +329,8 @@ func init() {
 
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configCopyProfileCmd)
 	configCmd.AddCommand(configRemoteCmd)
 	rootCmd.AddCommand(configCmd)
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+type authCheck struct {
+	Credential string `json:"credential"`
+	Configured bool   `json:"configured"`
+	Valid      bool   `json:"valid"`
+	Result     string `json:"result"`
+	User       string `json:"user,omitempty"`
+}
+
+var configTestAuthCmd = &cobra.Command{
+	Use:   "test-auth",
+	Short: "Check which configured credentials the server accepts",
+	Long: `Check which configured credentials the server accepts.
+
+The token and the API key are each tried on their own against /api/auth/me.
+When both are configured, requests send only the token, so a stale token
+hides a working API key; this command shows which one to keep.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base := client.New()
+		if base.Token == "" && base.APIKey == "" {
+			return fmt.Errorf("no token or API key configured (set --token/--api-key, SF_TOKEN/SF_API_KEY, or the config file)")
+		}
+
+		tokenOnly, keyOnly := *base, *base
+		tokenOnly.APIKey, keyOnly.Token = "", ""
+		checks := []authCheck{
+			checkCredential(&tokenOnly, "token", base.Token != ""),
+			checkCredential(&keyOnly, "api_key", base.APIKey != ""),
+		}
+		inUse := "none"
+		switch {
+		case base.Token != "":
+			inUse = "token"
+		case base.APIKey != "":
+			inUse = "api_key"
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(map[string]interface{}{"in_use": inUse, "credentials": checks})
+		default:
+			rows := make([][]string, 0, len(checks))
+			for _, ch := range checks {
+				result := ch.Result
+				switch {
+				case ch.Valid:
+					result = color.GreenString(result)
+				case ch.Configured:
+					result = color.RedString(result)
+				}
+				rows = append(rows, []string{ch.Credential, result, ch.User})
+			}
+			output.PrintTable([]string{"Credential", "Result", "User"}, rows)
+			fmt.Println()
+			fmt.Println("The token takes precedence: when both are set, only the token is sent.")
+			fmt.Println(authAdvice(checks[0], checks[1]))
+		}
+		return nil
+	},
+}
+
+// checkCredential tries a single credential against /api/auth/me.
+func checkCredential(c *client.Client, name string, configured bool) authCheck {
+	check := authCheck{Credential: name, Configured: configured}
+	if !configured {
+		check.Result = "not configured"
+		return check
+	}
+	var resp struct {
+		Authenticated bool                   `json:"authenticated"`
+		User          map[string]interface{} `json:"user"`
+	}
+	err := c.Get("/api/auth/me", &resp)
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		check.Result = "rejected"
+	case err != nil:
+		check.Result = "error: " + err.Error()
+	case !resp.Authenticated:
+		check.Result = "not authenticated (server may not enforce auth)"
+	default:
+		check.Valid = true
+		check.Result = "valid"
+		for _, k := range []string{"username", "email", "user_id"} {
+			if v, ok := resp.User[k].(string); ok && v != "" {
+				check.User = v
+				break
+			}
+		}
+	}
+	return check
+}
+
+// authAdvice suggests what to do about the credential check results.
+func authAdvice(token, apiKey authCheck) string {
+	switch {
+	case token.Configured && !token.Valid && apiKey.Valid:
+		return "The token is stale and hides a working API key; remove the token to fall back to the key."
+	case token.Configured && apiKey.Configured && token.Valid && !apiKey.Valid:
+		return "Only the token works; the API key can be removed."
+	case token.Valid:
+		return "Requests are authenticated with the token."
+	case apiKey.Valid:
+		return "Requests are authenticated with the API key."
+	}
+	return "No configured credential is accepted; log in again with 'sf auth login' or check the API key."
+}
+
+func init() {
+	configCmd.AddCommand(configTestAuthCmd)
+}
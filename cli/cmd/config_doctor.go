@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// Outcomes of a doctor check. A skipped check depends on one that failed.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// Clock skew beyond which config doctor warns, and fails.
+const (
+	doctorSkewWarn = 30 * time.Second
+	doctorSkewFail = 5 * time.Minute
+)
+
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// checkConfigFile reports whether the config file in use, if any, can be read
+// and parsed. Startup ignores a broken config file, so this is where it shows.
+func checkConfigFile(path string) doctorCheck {
+	check := doctorCheck{Name: "Config file"}
+	if path == "" {
+		check.Status, check.Detail = doctorWarn, "none found; using flags, SF_* environment variables and defaults"
+		check.Hint = "run 'sf config init' to save the server and credentials"
+		return check
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		check.Status, check.Detail = doctorFail, err.Error()
+		check.Hint = "check the --config path and the file's permissions"
+		return check
+	}
+	v := viper.New()
+	v.SetConfigType(detectConfigType(path))
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("%s: %v", path, err)
+		check.Hint = "fix the syntax error; until then the file is ignored"
+		return check
+	}
+	check.Status, check.Detail = doctorPass, path
+	return check
+}
+
+// checkServerURL reports whether server is an absolute http(s) URL.
+func checkServerURL(server string) (doctorCheck, *url.URL) {
+	check := doctorCheck{Name: "Server URL"}
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("%q is not an http:// or https:// URL", server)
+		check.Hint = "set --server, SF_SERVER or server in the config file, e.g. https://sf.example.com"
+		return check, nil
+	}
+	check.Status, check.Detail = doctorPass, server
+	return check, u
+}
+
+// checkTLS reports how the connection to u is secured.
+func checkTLS(u *url.URL, hasCredentials bool) doctorCheck {
+	check := doctorCheck{Name: "TLS"}
+	if u.Scheme == "http" {
+		host := u.Hostname()
+		ip := net.ParseIP(host)
+		if strings.EqualFold(host, "localhost") || (ip != nil && ip.IsLoopback()) {
+			check.Status, check.Detail = doctorPass, "plain HTTP to a local server"
+			return check
+		}
+		check.Status, check.Detail = doctorWarn, "plain HTTP; traffic is not encrypted"
+		if hasCredentials {
+			check.Hint = "use https:// so the API key or token cannot be read on the network"
+		} else {
+			check.Hint = "use https:// if the server supports it"
+		}
+		return check
+	}
+	switch {
+	case viper.GetBool("insecure"):
+		check.Status, check.Detail = doctorWarn, "certificate verification is disabled (--insecure)"
+		check.Hint = "trust the server's CA with --ca-cert instead of --insecure"
+	case viper.GetBool("skip_hostname_verification"):
+		check.Status, check.Detail = doctorWarn, "certificate chain verified, hostname not checked"
+		check.Hint = "connect by the name on the certificate, or use --resolve to reach it by IP"
+	default:
+		check.Status, check.Detail = doctorPass, "certificate verified"
+	}
+	return check
+}
+
+// checkClockSkew compares the server's Date header with the local time of the
+// request's midpoint.
+func checkClockSkew(date string, sent, received time.Time) doctorCheck {
+	check := doctorCheck{Name: "Clock skew"}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		check.Status, check.Detail = doctorSkip, "server did not report its time"
+		return check
+	}
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := local.Sub(serverTime)
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	// The Date header only has whole seconds.
+	if abs <= time.Second {
+		check.Status, check.Detail = doctorPass, "in sync with the server"
+		return check
+	}
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+	check.Detail = fmt.Sprintf("local clock is %s %s the server", humanDuration(abs), direction)
+	switch {
+	case abs > doctorSkewFail:
+		check.Status = doctorFail
+	case abs > doctorSkewWarn:
+		check.Status = doctorWarn
+	default:
+		check.Status = doctorPass
+		return check
+	}
+	check.Hint = "enable NTP; skew breaks token expiry and makes --since and --after windows shift"
+	return check
+}
+
+// checkVersions compares the CLI with the server version and the API version
+// the server reports serving.
+func checkVersions(cliVersion, serverVersion, requestedAPI, servedAPI string) doctorCheck {
+	check := doctorCheck{Name: "Versions"}
+	check.Detail = fmt.Sprintf("CLI %s, server %s", cliVersion, serverVersion)
+	if serverVersion == "" {
+		check.Detail = fmt.Sprintf("CLI %s, server version not reported", cliVersion)
+	}
+	switch {
+	case requestedAPI != "" && servedAPI != "" && requestedAPI != servedAPI:
+		check.Status = doctorWarn
+		check.Detail += fmt.Sprintf("; server serves API %s, CLI requests %s", servedAPI, requestedAPI)
+		check.Hint = "upgrade the CLI or server, or pass --api-version " + servedAPI
+	case serverVersion != "" && majorVersion(cliVersion) != "" && majorVersion(serverVersion) != "" &&
+		majorVersion(cliVersion) != majorVersion(serverVersion):
+		check.Status = doctorWarn
+		check.Hint = "use a CLI release matching the server's major version"
+	default:
+		check.Status = doctorPass
+	}
+	return check
+}
+
+// majorVersion returns the major version of a "v6.0.1"-style version, or ""
+// if it has none (e.g. a development build).
+func majorVersion(v string) string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	for _, r := range major {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return major
+}
+
+// runDoctor runs every check against the configured server. Checks that need
+// the server are skipped when it cannot be reached.
+func runDoctor(c *client.Client) []doctorCheck {
+	checks := []doctorCheck{checkConfigFile(viper.ConfigFileUsed())}
+	urlCheck, u := checkServerURL(c.BaseURL)
+	checks = append(checks, urlCheck)
+	skip := func(reason string, names ...string) {
+		for _, name := range names {
+			checks = append(checks, doctorCheck{Name: name, Status: doctorSkip, Detail: reason})
+		}
+	}
+	if u == nil {
+		skip("needs a valid server URL", "Connectivity", "Authentication", "TLS", "Clock skew", "Versions")
+		return checks
+	}
+
+	connect := doctorCheck{Name: "Connectivity"}
+	sent := time.Now()
+	resp, err := c.Stream(http.MethodGet, "/health", nil, "")
+	received := time.Now()
+	var health healthResp
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			err = &client.HTTPError{StatusCode: resp.StatusCode, RequestID: client.RequestID(resp.Header)}
+		} else if derr := json.NewDecoder(resp.Body).Decode(&health); derr != nil {
+			err = fmt.Errorf("decoding health response: %w", derr)
+		}
+	}
+	if err != nil {
+		connect.Status, connect.Detail = doctorFail, err.Error()
+		connect.Hint = "check that the server is running and --server points at it; for TLS errors see --ca-cert"
+		checks = append(checks, connect)
+		skip("server not reachable", "Authentication", "TLS", "Clock skew", "Versions")
+		return checks
+	}
+	connect.Status = doctorPass
+	connect.Detail = fmt.Sprintf("%s answered in %dms", c.BaseURL, received.Sub(sent).Milliseconds())
+	if status := strings.ToLower(health.Status); status != "" && status != "ok" && status != "healthy" && status != "up" {
+		connect.Status = doctorWarn
+		connect.Detail += fmt.Sprintf(", reporting %s", health.Status)
+		connect.Hint = "see 'sf health --components' for what is unhealthy"
+	}
+	checks = append(checks, connect, checkDoctorAuth(c), checkTLS(u, c.Token != "" || c.APIKey != ""),
+		checkClockSkew(resp.Header.Get("Date"), sent, received),
+		checkVersions(version, health.Version, c.APIVersion, resp.Header.Get("X-API-Version")))
+	return checks
+}
+
+// checkDoctorAuth checks the credential requests are sent with.
+func checkDoctorAuth(c *client.Client) doctorCheck {
+	check := doctorCheck{Name: "Authentication"}
+	if err := c.CheckAuthTransport(); err != nil {
+		check.Status, check.Detail = doctorFail, err.Error()
+		return check
+	}
+	name := "token"
+	switch {
+	case c.Token != "":
+	case c.APIKey != "":
+		name = "api_key"
+	default:
+		check.Status, check.Detail = doctorWarn, "no token or API key configured"
+		check.Hint = "if the server requires auth, set --api-key or run 'sf auth login'"
+		return check
+	}
+	result := checkCredential(c, name, true)
+	check.Detail = name + " " + result.Result
+	switch {
+	case result.Valid:
+		check.Status = doctorPass
+		if result.User != "" {
+			check.Detail += " for " + result.User
+		}
+	case strings.HasPrefix(result.Result, "not authenticated"):
+		check.Status = doctorWarn
+	default:
+		check.Status = doctorFail
+		check.Hint = "log in again with 'sf auth login' or check the API key; 'sf config test-auth' tries each credential"
+	}
+	return check
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the CLI configuration and server connection",
+	Long: `Diagnose the CLI configuration and server connection.
+
+Checks the config file, the server URL, that the server answers its health
+check, that the configured credential is accepted, how TLS is set up, clock
+skew against the server, and CLI and server version compatibility. Each check
+passes, warns or fails, with a hint on how to fix it. The command exits
+non-zero if any check fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks := runDoctor(client.New())
+		failed := 0
+		for _, ch := range checks {
+			if ch.Status == doctorFail {
+				failed++
+			}
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(map[string]interface{}{"ok": failed == 0, "checks": checks})
+		default:
+			for _, ch := range checks {
+				fmt.Printf("%s %-15s %s\n", doctorMark(ch.Status), ch.Name, ch.Detail)
+				if ch.Hint != "" {
+					fmt.Printf("  %-15s → %s\n", "", ch.Hint)
+				}
+			}
+		}
+		if failed > 0 {
+			// A failed check is not a usage error.
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// doctorMark is the colored checklist mark of a check status.
+func doctorMark(status string) string {
+	switch status {
+	case doctorPass:
+		return color.GreenString("✓")
+	case doctorWarn:
+		return color.YellowString("⚠")
+	case doctorFail:
+		return color.RedString("✗")
+	}
+	return color.New(color.Faint).Sprint("-")
+}
+
+func init() {
+	configCmd.AddCommand(configDoctorCmd)
+}
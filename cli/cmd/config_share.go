@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// secretPlaceholder stands in for secrets in exported configs. Import skips
+// keys still set to it, so a shared template never clears real credentials.
+const secretPlaceholder = "REPLACE_ME"
+
+// isSecretKey reports whether a dotted config key holds a secret, judged by
+// its last segment (profiles.prod.api_key is, profiles.monkey.server is not).
+func isSecretKey(key string) bool {
+	return isSecretFlag(key[strings.LastIndex(key, ".")+1:])
+}
+
+// sanitizeConfig replaces every non-empty secret in v with secretPlaceholder
+// and returns the keys replaced, sorted.
+func sanitizeConfig(v *viper.Viper) []string {
+	var replaced []string
+	for _, key := range v.AllKeys() {
+		if isSecretKey(key) && v.GetString(key) != "" {
+			v.Set(key, secretPlaceholder)
+			replaced = append(replaced, key)
+		}
+	}
+	sort.Strings(replaced)
+	return replaced
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the config file with secrets replaced, for sharing",
+	Long: `Export the config file with secrets replaced, for sharing.
+
+Only values from the config file are exported, not flags or SF_* environment
+variables. API keys, tokens, passwords and other secrets are written as
+"` + secretPlaceholder + `" unless --include-secrets is given. The export goes to stdout,
+or to --file in the format of its extension (or --format).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		format, _ := cmd.Flags().GetString("format")
+		includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+		if format == "" {
+			format = "yaml"
+			if file != "" {
+				format = detectConfigType(file)
+			}
+		}
+		if !validConfigFormat(format) {
+			return fmt.Errorf("invalid --format %q (expected %s)", format, strings.Join(configFormats, ", "))
+		}
+
+		v, configFile, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(configFile); err != nil {
+			return fmt.Errorf("no config file at %s — run 'sf config init' first", configFile)
+		}
+		var replaced []string
+		if !includeSecrets {
+			replaced = sanitizeConfig(v)
+		}
+
+		if file != "" {
+			if err := writeConfigFile(v, file, format); err != nil {
+				return err
+			}
+			output.Success("Exported %s to %s", configFile, file)
+		} else {
+			// Viper only writes to files; encode through a scratch file.
+			dir, err := os.MkdirTemp("", "sf-config-export")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+			tmp := filepath.Join(dir, "config."+format)
+			if err := writeConfigFile(v, tmp, format); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(tmp)
+			if err != nil {
+				return err
+			}
+			os.Stdout.Write(data)
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				fmt.Println()
+			}
+		}
+		if len(replaced) > 0 {
+			output.Note("Replaced %d secret(s) with %s: %s", len(replaced), secretPlaceholder, strings.Join(replaced, ", "))
+		} else if includeSecrets {
+			output.Warn("The export contains secrets; do not commit or share it")
+		}
+		return nil
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Merge a shared config file into the local config",
+	Long: `Merge a shared config file into the local config.
+
+Values from --file override the local ones key by key; keys the file does not
+mention are kept. Secrets still set to "` + secretPlaceholder + `" are skipped, so importing
+a sanitized export keeps your own credentials.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		in := viper.New()
+		in.SetConfigFile(file)
+		in.SetConfigType(detectConfigType(file))
+		if err := in.ReadInConfig(); err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+
+		v, configFile, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		changed, skipped := mergeConfig(v, in)
+
+		if dryRun {
+			for _, key := range changed {
+				fmt.Printf("  would set %s\n", key)
+			}
+		} else if len(changed) > 0 {
+			if err := writeConfigFile(v, configFile, detectConfigType(configFile)); err != nil {
+				return err
+			}
+		}
+		if len(skipped) > 0 {
+			output.Note("Skipped placeholder secrets: %s", strings.Join(skipped, ", "))
+		}
+		verb := "Imported"
+		if dryRun {
+			verb = "Would import"
+		}
+		output.Success("%s %d setting(s) from %s into %s", verb, len(changed), file, configFile)
+		return nil
+	},
+}
+
+// mergeConfig copies the settings of in into v, skipping placeholder secrets.
+// It returns the keys whose value changed and the keys skipped, sorted.
+func mergeConfig(v, in *viper.Viper) (changed, skipped []string) {
+	for _, key := range in.AllKeys() {
+		val := in.Get(key)
+		if s, ok := val.(string); ok && s == secretPlaceholder && isSecretKey(key) {
+			skipped = append(skipped, key)
+			continue
+		}
+		if v.IsSet(key) && fmt.Sprint(v.Get(key)) == fmt.Sprint(val) {
+			continue
+		}
+		v.Set(key, val)
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+	sort.Strings(skipped)
+	return changed, skipped
+}
+
+func init() {
+	configExportCmd.Flags().StringP("file", "f", "", "Write the export to this file instead of stdout")
+	configExportCmd.Flags().String("format", "", "Export format: yaml, json or toml (default: from --file, else yaml)")
+	configExportCmd.Flags().Bool("include-secrets", false, "Export API keys, tokens and other secrets as they are")
+	configImportCmd.Flags().StringP("file", "f", "", "Config file to merge in (YAML, JSON or TOML)")
+	configImportCmd.Flags().Bool("dry-run", false, "List the settings that would change without writing")
+	configImportCmd.MarkFlagRequired("file")
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+}
@@ -21,10 +21,11 @@ var correlateCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/correlations/run", args[0]), nil, &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			total, _ := resp["total"].(float64)
 			fmt.Printf("Correlations found: %.0f\n", total)
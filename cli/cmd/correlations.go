@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+type correlationRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Risk        string `json:"risk"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type correlationRulesResp struct {
+	Items []correlationRule `json:"items"`
+	Total int               `json:"total"`
+}
+
+// riskLevels orders correlation risk levels from lowest to highest.
+var riskLevels = []string{"INFO", "LOW", "MEDIUM", "HIGH"}
+
+// riskRank returns the position of risk in riskLevels, or -1 if unknown.
+func riskRank(risk string) int {
+	for i, r := range riskLevels {
+		if strings.EqualFold(r, risk) {
+			return i
+		}
+	}
+	return -1
+}
+
+func colorRisk(risk string) string {
+	switch strings.ToUpper(risk) {
+	case "HIGH":
+		return color.RedString(risk)
+	case "MEDIUM":
+		return color.YellowString(risk)
+	case "LOW":
+		return color.CyanString(risk)
+	default:
+		return risk
+	}
+}
+
+var correlationsCmd = &cobra.Command{
+	Use:   "correlations",
+	Short: "Inspect correlation rules",
+}
+
+var correlationRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List the correlation rules defined on the server",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minRisk, _ := cmd.Flags().GetString("min-risk")
+		minRank := 0
+		if minRisk != "" {
+			if minRank = riskRank(minRisk); minRank < 0 {
+				return fmt.Errorf("invalid --min-risk %q (expected %s)", minRisk, strings.Join(riskLevels, ", "))
+			}
+		}
+
+		cols, err := fieldColumns("correlations_rules", correlationRulesHeader, correlationRulesKeys)
+		if err != nil {
+			return err
+		}
+
+		c := client.New()
+		var resp correlationRulesResp
+		if err := c.Get("/api/correlation-rules?page_size=1000", &resp); err != nil {
+			return err
+		}
+
+		rules := make([]correlationRule, 0, len(resp.Items))
+		for _, r := range resp.Items {
+			if riskRank(r.Risk) >= minRank {
+				rules = append(rules, r)
+			}
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(selectJSONFields(rules, correlationRulesKeys, cols))
+		case output.CSV:
+			rows := make([][]string, 0, len(rules))
+			for _, r := range rules {
+				rows = append(rows, []string{r.ID, r.Name, r.Risk, fmt.Sprintf("%v", r.Enabled), r.Description})
+			}
+			output.PrintCSV(selectColumns(correlationRulesHeader, rows, cols))
+		default:
+			rows := make([][]string, 0, len(rules))
+			for _, r := range rules {
+				enabled := "✓"
+				if !r.Enabled {
+					enabled = "✗"
+				}
+				desc := truncateCell(r.Description, 60)
+				rows = append(rows, []string{r.ID, r.Name, colorRisk(r.Risk), enabled, desc})
+			}
+			output.PrintTable(selectColumns(correlationRulesHeader, rows, cols))
+			fmt.Printf("\nTotal: %d rules\n", len(rules))
+		}
+		return nil
+	},
+}
+
+// correlationRulesHeader and correlationRulesKeys are the correlation rules
+// columns and their JSON field names, as accepted by --fields.
+var (
+	correlationRulesHeader = []string{"ID", "Name", "Risk", "Enabled", "Description"}
+	correlationRulesKeys   = []string{"id", "name", "risk", "enabled", "description"}
+)
+
+func init() {
+	correlationRulesCmd.Flags().String("min-risk", "", "Only show rules at or above this risk (INFO, LOW, MEDIUM, HIGH)")
+
+	correlationsCmd.AddCommand(correlationRulesCmd)
+	rootCmd.AddCommand(correlationsCmd)
+}
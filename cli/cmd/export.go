@@ -1,10 +1,20 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/spiderfoot/spiderfoot-cli/internal/client"
 	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
@@ -17,78 +27,359 @@ var exportCmd = &cobra.Command{
 var exportJSONCmd = &cobra.Command{
 	Use:   "json [scan-id]",
 	Short: "Export scan results as JSON",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return doExport(args[0], "json", "json")
-	},
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runExport("json", "json"),
 }
 
 var exportCSVCmd = &cobra.Command{
 	Use:   "csv [scan-id]",
 	Short: "Export scan results as CSV",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return doExport(args[0], "csv", "csv")
-	},
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runExport("csv", "csv"),
 }
 
 var exportSTIXCmd = &cobra.Command{
 	Use:   "stix [scan-id]",
 	Short: "Export scan results as STIX 2.1 bundle",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return doExport(args[0], "stix", "json")
-	},
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runExport("stix", "json"),
 }
 
 var exportSARIFCmd = &cobra.Command{
 	Use:   "sarif [scan-id]",
 	Short: "Export scan results as SARIF",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return doExport(args[0], "sarif", "sarif.json")
-	},
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runExport("sarif", "sarif.json"),
 }
 
-// doExport fetches scan data in the specified format using the real API endpoint:
+// runExport returns a cobra.RunE function that exports the scan given as an
+// argument, or a batch of scans selected with --scans or --all.
+func runExport(format, ext string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		scans, _ := exportCmd.PersistentFlags().GetString("scans")
+		all, _ := exportCmd.PersistentFlags().GetBool("all")
+		batch := scans != "" || all
+
+		includeRaw, _ := exportCmd.PersistentFlags().GetBool("include-raw")
+		noRaw, _ := exportCmd.PersistentFlags().GetBool("no-raw")
+		if includeRaw && noRaw {
+			return fmt.Errorf("--include-raw and --no-raw are mutually exclusive")
+		}
+		if n, _ := exportCmd.PersistentFlags().GetInt("events-per-file"); n > 0 && format != "json" && format != "csv" {
+			return fmt.Errorf("--events-per-file is only supported for json and csv exports")
+		}
+		var red *redactor
+		redact, _ := exportCmd.PersistentFlags().GetBool("redact")
+		anonymize, _ := exportCmd.PersistentFlags().GetBool("anonymize")
+		if redact || anonymize {
+			if format == "sqlite" || format == "pdf" {
+				return fmt.Errorf("--redact and --anonymize are not supported for %s exports", format)
+			}
+			red = &redactor{fields: make(map[string]bool)}
+			if redact {
+				var err error
+				if red, err = newRedactor(); err != nil {
+					return err
+				}
+			}
+			if anonymize {
+				key := viper.GetString("anon_key")
+				if key == "" {
+					return fmt.Errorf("--anonymize needs --anon-key or SF_ANON_KEY; the same key always gives the same pseudonyms")
+				}
+				red.anon = newAnonymizer(key)
+			}
+		}
+
+		switch {
+		case len(args) == 1 && batch:
+			return fmt.Errorf("specify either a scan ID or --scans/--all, not both")
+		case len(args) == 1:
+			outFile, _ := exportCmd.PersistentFlags().GetString("file")
+			return doExport(args[0], format, ext, outFile, red)
+		case batch:
+			return doBatchExport(format, ext, red)
+		default:
+			return fmt.Errorf("a scan ID, --scans, or --all is required")
+		}
+	}
+}
+
+// exportFormats maps each export format to its file extension.
+var exportFormats = map[string]string{
+	"json": "json", "csv": "csv", "stix": "json", "sarif": "sarif.json", "pdf": "pdf", "sqlite": "db",
+}
+
+// doExport exports a single scan to outFile (auto-generated if empty),
+// redacting it if red is non-nil.
+func doExport(scanID, format, ext, outFile string, red *redactor) error {
+	if outFile == "" {
+		dir := exportDir()
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("creating directory: %w", err)
+		}
+		outFile = filepath.Join(dir, exportFilename(scanID, ext))
+	}
+	stop := output.StartSpinner(fmt.Sprintf("Exporting scan %s as %s...", scanID, format))
+	outFile, n, err := exportScan(client.New(), scanID, format, ext, outFile, red)
+	stop()
+	if err != nil {
+		return err
+	}
+	output.Success("Exported to %s (%d bytes)", outFile, n)
+	if red != nil {
+		for _, line := range red.summary() {
+			output.Success("%s", line)
+		}
+	}
+	return nil
+}
+
+// exportScan fetches scan data in the specified format using the real API endpoint:
 // GET /api/scans/{scan_id}/export?format=json|csv|stix|sarif
-func doExport(scanID, format, ext string) error {
+// It returns the name of the file written and its size in bytes. If red is
+// non-nil, the data is redacted before it is written.
+func exportScan(c *client.Client, scanID, format, ext, outFile string, red *redactor) (string, int, error) {
 	if err := validateSafeID(scanID, "scan ID"); err != nil {
-		return err
+		return "", 0, err
 	}
-	c := client.New()
 	includeRaw, _ := exportCmd.PersistentFlags().GetBool("include-raw")
+	noRaw, _ := exportCmd.PersistentFlags().GetBool("no-raw")
 	maxEvents, _ := exportCmd.PersistentFlags().GetInt("max-events")
 
 	path := fmt.Sprintf("/api/scans/%s/export?format=%s", scanID, format)
-	if includeRaw {
+	switch {
+	case includeRaw:
 		path += "&include_raw=true"
+	case noRaw:
+		path += "&include_raw=false"
 	}
 	if maxEvents > 0 {
 		path += fmt.Sprintf("&max_events=%d", maxEvents)
 	}
 
-	data, _, err := c.GetRaw(path)
-	if err != nil {
+	if outFile == "" {
+		outFile = exportFilename(scanID, ext)
+	}
+	if format == "sqlite" {
+		n, err := writeSQLiteExport(c, scanID, outFile)
+		return outFile, n, err
+	}
+	if format == "pdf" {
+		n, err := writePDFExport(c, scanID, outFile)
+		return outFile, n, err
+	}
+
+	eventsPerFile, _ := exportCmd.PersistentFlags().GetInt("events-per-file")
+	if eventsPerFile > 0 {
+		return exportChunked(c, scanID, format, outFile, eventsPerFile, maxEvents, red)
+	}
+
+	ctx, cancel := exportContext()
+	defer cancel()
+
+	var n int64
+	err := writeFileAtomic(outFile, func(f *os.File) error {
+		if red == nil {
+			var err error
+			n, err = c.Download(ctx, path, f)
+			return err
+		}
+		// Redaction needs the whole document, so download it into memory.
+		var buf bytes.Buffer
+		if _, err := c.Download(ctx, path, &buf); err != nil {
+			return err
+		}
+		data, err := red.redact(format, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		written, err := f.Write(data)
+		n = int64(written)
 		return err
+	})
+	if err != nil {
+		return "", 0, exportFailure(ctx, err, scanID)
 	}
+	return outFile, int(n), nil
+}
 
-	outFile, _ := exportCmd.PersistentFlags().GetString("file")
-	if outFile == "" {
-		outFile = fmt.Sprintf("spiderfoot_%s.%s", scanID[:min(12, len(scanID))], ext)
+// exportContext returns the context bounding one export download: it is
+// cancelled on Ctrl-C or SIGTERM and, unless --timeout is 0, when the timeout
+// elapses.
+func exportContext() (context.Context, context.CancelFunc) {
+	timeout := viper.GetDuration("timeout")
+	if timeout <= 0 {
+		return context.WithCancel(commandContext())
+	}
+	return context.WithTimeout(commandContext(), timeout)
+}
+
+// exportFailure turns a failed download into a user-facing error, explaining
+// timeouts and cancellations rather than reporting the raw network error.
+func exportFailure(ctx context.Context, err error, scanID string) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || isTimeout(err):
+		return fmt.Errorf("export timed out after %s; try a larger --timeout", viper.GetDuration("timeout"))
+	case ctx.Err() != nil:
+		return fmt.Errorf("export cancelled")
+	}
+	return notFound(err, "scan", scanID)
+}
+
+// isTimeout reports whether err is a network timeout, such as the HTTP
+// client's own deadline firing.
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// exportDir returns the directory for auto-named exports: --dir if given,
+// else the export.dir config key, else the current directory.
+func exportDir() string {
+	if f := exportCmd.PersistentFlags().Lookup("dir"); f.Changed {
+		return f.Value.String()
+	}
+	dir := viper.GetString("export.dir")
+	if dir == "" {
+		return "."
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, rest)
+		}
+	}
+	return dir
+}
+
+// exportFilename returns the auto-generated export filename for a scan. The
+// whole ID is used, so scans whose IDs share a prefix do not overwrite each
+// other's files in a batch export.
+func exportFilename(scanID, ext string) string {
+	return fmt.Sprintf("spiderfoot_%s.%s", scanID, ext)
+}
+
+type exportResult struct {
+	ScanID string `json:"scan_id"`
+	File   string `json:"file,omitempty"`
+	Bytes  int    `json:"bytes"`
+	Error  string `json:"error,omitempty"`
+}
+
+// doBatchExport exports every selected scan into --dir, running at most
+// --concurrency exports at a time.
+func doBatchExport(format, ext string, red *redactor) error {
+	scans, _ := exportCmd.PersistentFlags().GetString("scans")
+	all, _ := exportCmd.PersistentFlags().GetBool("all")
+	status, _ := exportCmd.PersistentFlags().GetString("status")
+	dir := exportDir()
+	concurrency, _ := exportCmd.PersistentFlags().GetInt("concurrency")
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	c := client.New()
+	var ids []string
+	if all {
+		scans, err := fetchScanList(c, time.Time{}, time.Time{})
+		if err != nil {
+			return err
+		}
+		for _, s := range scans {
+			if status == "" || strings.EqualFold(s.Status, status) {
+				ids = append(ids, s.ScanID)
+			}
+		}
+	} else {
+		for _, id := range strings.Split(scans, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("no scans selected for export")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	stop := output.StartSpinner(fmt.Sprintf("Exporting %d scans as %s...", len(ids), format))
+	results := make([]exportResult, len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res := exportResult{ScanID: id}
+			file, n, err := exportScan(c, id, format, ext, filepath.Join(dir, exportFilename(id, ext)), red)
+			if err != nil {
+				res.Error = err.Error()
+			} else {
+				res.File, res.Bytes = file, n
+			}
+			results[i] = res
+		}(i, id)
+	}
+	wg.Wait()
+	stop()
+
+	failed, total := 0, 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+		total += r.Bytes
+	}
+
+	switch output.Current() {
+	case output.JSON, output.YAML:
+		output.PrintStructured(results)
+	default:
+		header := []string{"Scan", "File", "Bytes", "Result"}
+		rows := make([][]string, 0, len(results))
+		for _, r := range results {
+			result := color.GreenString("ok")
+			if r.Error != "" {
+				result = color.RedString(r.Error)
+			}
+			rows = append(rows, []string{truncID(r.ScanID), r.File, fmt.Sprintf("%d", r.Bytes), result})
+		}
+		output.PrintTable(header, rows)
+		fmt.Printf("\nExported %d/%d scans (%d bytes)\n", len(results)-failed, len(results), total)
+		if red != nil {
+			for _, line := range red.summary() {
+				fmt.Println(line)
+			}
+		}
 	}
 
-	if err := os.WriteFile(outFile, data, 0600); err != nil {
-		return fmt.Errorf("writing file: %w", err)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d exports failed", failed, len(results))
 	}
-	output.Success("Exported to %s (%d bytes)", outFile, len(data))
 	return nil
 }
 
 func init() {
 	exportCmd.PersistentFlags().StringP("file", "f", "", "Output filename (auto-generated if omitted)")
 	exportCmd.PersistentFlags().Bool("include-raw", false, "Include raw event data")
+	exportCmd.PersistentFlags().Bool("no-raw", false, "Omit raw module payloads to shrink the export")
 	exportCmd.PersistentFlags().Int("max-events", 0, "Maximum events to export (0 = all)")
+	exportCmd.PersistentFlags().String("scans", "", "Comma-separated scan IDs to export as a batch")
+	exportCmd.PersistentFlags().Bool("all", false, "Export all scans (combine with --status)")
+	exportCmd.PersistentFlags().String("status", "", "With --all, only export scans with this status")
+	exportCmd.PersistentFlags().String("dir", "", "Output directory for auto-named exports (default: export.dir config key, else current directory)")
+	exportCmd.PersistentFlags().Int("concurrency", 4, "Maximum concurrent exports in batch mode")
+	exportCmd.PersistentFlags().Bool("redact", false, "Redact values matching the redact.patterns and redact.fields config rules")
+	exportCmd.PersistentFlags().Bool("anonymize", false, "Replace IPs, domains and emails with consistent HMAC-based pseudonyms (needs --anon-key)")
+	exportCmd.PersistentFlags().String("anon-key", "", "Secret key for --anonymize pseudonyms (or SF_ANON_KEY)")
+	viper.BindPFlag("anon_key", exportCmd.PersistentFlags().Lookup("anon-key"))
+	exportCmd.PersistentFlags().Int("events-per-file", 0, "Split JSON/CSV exports into numbered files of at most N events, plus a manifest")
 
 	exportCmd.AddCommand(exportJSONCmd)
 	exportCmd.AddCommand(exportCSVCmd)
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// anonymizeSkip lists the fields that describe an event rather than hold
+// collected data. They are never pseudonymized, so event types, modules and
+// the links between events stay intact.
+var anonymizeSkip = map[string]bool{
+	"type": true, "event_type": true, "module": true, "hash": true,
+	"source_event_hash": true, "scan_id": true, "generated": true,
+	"risk": true, "confidence": true, "visibility": true,
+}
+
+// anonRe finds the values --anonymize replaces, in order of precedence: an
+// email address (local part and domain), an IPv4 address, an IPv6 candidate
+// (checked with net.ParseIP) and a domain name (checked against knownTLDs).
+var anonRe = regexp.MustCompile(`(?i)([a-z0-9._%+-]+)@((?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63})\b` +
+	`|\b(\d{1,3}(?:\.\d{1,3}){3})\b` +
+	`|([0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7})` +
+	`|\b((?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63})\b`)
+
+// knownTLDs are the top-level domains a name must end in to be pseudonymized
+// as a domain, so file names such as report.pdf are left alone: every
+// country code, the common generic domains and names used on internal
+// networks.
+var knownTLDs = func() map[string]bool {
+	tlds := make(map[string]bool)
+	for _, tld := range strings.Fields(`
+		com net org edu gov mil int arpa info biz name pro aero asia cat coop
+		jobs mobi museum tel travel xxx app dev cloud online site tech store
+		xyz top club shop blog page email live news link website space digital
+		network services solutions agency company group systems security
+		center host today world media global zone life
+		local localdomain internal intranet lan corp home
+		ac ad ae af ag ai al am ao aq ar as at au aw ax az ba bb bd be bf bg
+		bh bi bj bm bn bo br bs bt bw by bz ca cc cd cf cg ch ci ck cl cm cn
+		co cr cu cv cw cx cy cz de dj dk dm do dz ec ee eg er es et eu fi fj
+		fk fm fo fr ga gd ge gf gg gh gi gl gm gn gp gq gr gs gt gu gw gy hk
+		hm hn hr ht hu id ie il im in io iq ir is it je jm jo jp ke kg kh ki
+		km kn kp kr kw ky kz la lb lc li lk lr ls lt lu lv ly ma mc md me mg
+		mh mk ml mm mn mo mp mq mr ms mt mu mv mw mx my mz na nc ne nf ng ni
+		nl no np nr nu nz om pa pe pf pg ph pk pl pm pn pr ps pt pw py qa re
+		ro rs ru rw sa sb sc sd se sg sh si sk sl sm sn so sr ss st su sv sx
+		sy sz tc td tf tg th tj tk tl tm tn to tr tt tv tw tz ua ug uk us uy
+		uz va vc ve vg vi vn vu wf ws ye yt za zm zw`) {
+		tlds[tld] = true
+	}
+	return tlds
+}()
+
+// anonymizer replaces IP addresses, domains and email addresses with
+// pseudonyms derived from an HMAC of the value, so the same key always maps a
+// value to the same pseudonym and relationships between events survive.
+// Domains are pseudonymized label by label with the top-level domain kept, so
+// hosts under the same parent domain still share a parent. count totals the
+// replacements.
+type anonymizer struct {
+	key   []byte
+	count atomic.Int64
+
+	// ips maps the addresses seen so far to their pseudonyms, and taken
+	// holds those pseudonyms, so that addresses whose hashes collide are
+	// still told apart.
+	mu    sync.Mutex
+	ips   map[string]string
+	taken map[string]bool
+}
+
+func newAnonymizer(key string) *anonymizer {
+	return &anonymizer{key: []byte(key), ips: make(map[string]string), taken: make(map[string]bool)}
+}
+
+// sum returns the HMAC of a value of the given kind.
+func (a *anonymizer) sum(kind, value string) []byte {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(kind + ":" + strings.ToLower(value)))
+	return mac.Sum(nil)
+}
+
+// domain pseudonymizes a domain name. Each label is derived from itself and
+// every label above it, so www.example.com and mail.example.com keep a common
+// parent while www under another domain does not match.
+func (a *anonymizer) domain(name string) string {
+	labels := strings.Split(name, ".")
+	out := make([]string, len(labels))
+	out[len(labels)-1] = strings.ToLower(labels[len(labels)-1])
+	for i := 0; i < len(labels)-1; i++ {
+		out[i] = "h" + hex.EncodeToString(a.sum("domain", strings.Join(labels[i:], ".")))[:8]
+	}
+	return strings.Join(out, ".")
+}
+
+// ip pseudonymizes an IP address: an IPv4 address into 10.0.0.0/8, an IPv6
+// address into fd00::/8. Only 24 bits of hash fit an IPv4 pseudonym, so an
+// address whose pseudonym is already taken by another is re-hashed with a
+// counter until it gets one of its own. Which of two colliding addresses
+// keeps the first pseudonym then depends on the order they are seen in.
+func (a *anonymizer) ip(ip net.IP) string {
+	value := ip.String()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if p, ok := a.ips[value]; ok {
+		return p
+	}
+	for n := 0; ; n++ {
+		input := value
+		if n > 0 {
+			input = fmt.Sprintf("%s#%d", value, n)
+		}
+		h := a.sum("ip", input)
+		p := fmt.Sprintf("fd00::%x:%x:%x:%x", binary.BigEndian.Uint16(h[0:]),
+			binary.BigEndian.Uint16(h[2:]), binary.BigEndian.Uint16(h[4:]), binary.BigEndian.Uint16(h[6:]))
+		if ip.To4() != nil {
+			p = fmt.Sprintf("10.%d.%d.%d", h[0], h[1], h[2])
+		}
+		if !a.taken[p] {
+			a.ips[value], a.taken[p] = p, true
+			return p
+		}
+	}
+}
+
+// text replaces every IP address, domain and email address in s.
+func (a *anonymizer) text(s string) string {
+	matches := anonRe.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return s[m[2*i]:m[2*i+1]]
+		}
+		var repl string
+		switch {
+		case group(1) != "":
+			repl = "u" + hex.EncodeToString(a.sum("email", group(0)))[:8] + "@" + a.domain(group(2))
+		case group(3) != "":
+			if ip := net.ParseIP(group(3)); ip != nil {
+				repl = a.ip(ip)
+			}
+		case group(4) != "":
+			if ip := net.ParseIP(group(4)); ip != nil && ip.To4() == nil {
+				repl = a.ip(ip)
+			}
+		case group(5) != "":
+			name := group(5)
+			if knownTLDs[strings.ToLower(name[strings.LastIndex(name, ".")+1:])] {
+				repl = a.domain(name)
+			}
+		}
+		if repl == "" {
+			continue
+		}
+		a.count.Add(1)
+		b.WriteString(s[last:m[0]])
+		b.WriteString(repl)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
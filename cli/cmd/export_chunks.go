@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+// chunkCSVColumns are the event fields written to chunked CSV exports.
+var chunkCSVColumns = []string{"generated", "type", "module", "data", "hash", "source_event_hash", "risk"}
+
+type exportChunk struct {
+	File   string `json:"file"`
+	Events int    `json:"events"`
+	Bytes  int64  `json:"bytes"`
+}
+
+type exportManifest struct {
+	ScanID        string        `json:"scan_id"`
+	Format        string        `json:"format"`
+	EventsPerFile int           `json:"events_per_file"`
+	TotalEvents   int           `json:"total_events"`
+	Chunks        []exportChunk `json:"chunks"`
+}
+
+// chunkWriter writes events into numbered files of at most perFile events
+// each, named <base>.partNNN.<ext>. Each chunk is written to a temporary file
+// and renamed into place once complete. Until unregister is called, an
+// interrupt removes the chunks, as abort does.
+type chunkWriter struct {
+	base, format string
+	perFile      int
+
+	f      *os.File
+	name   string
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	events int
+	chunks []exportChunk
+	files  []string
+	// dropTemp unregisters the interrupt cleanup of the open temporary
+	// file, and cleanups those of the finished chunks.
+	dropTemp func()
+	cleanups []func()
+}
+
+// add appends an event to the current chunk, starting a new one if needed.
+func (w *chunkWriter) add(event map[string]interface{}) error {
+	if w.f == nil || w.events == w.perFile {
+		if err := w.closeChunk(); err != nil {
+			return err
+		}
+		if err := w.openChunk(); err != nil {
+			return err
+		}
+	}
+
+	if w.format == "csv" {
+		row := make([]string, len(chunkCSVColumns))
+		for i, col := range chunkCSVColumns {
+			row[i] = eventCell(event[col])
+		}
+		if err := w.csv.Write(row); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+	} else {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if w.events == 0 {
+			sep = "  "
+		}
+		if _, err := w.buf.WriteString(sep); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		if _, err := w.buf.Write(line); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+	}
+	w.events++
+	return nil
+}
+
+func (w *chunkWriter) openChunk() error {
+	name := fmt.Sprintf("%s.part%03d.%s", w.base, len(w.chunks)+1, w.format)
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.part")
+	if err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	tmp := f.Name()
+	w.dropTemp = onInterrupt(func() { os.Remove(tmp) })
+	w.f, w.name, w.buf, w.events = f, name, bufio.NewWriter(f), 0
+	w.chunks = append(w.chunks, exportChunk{File: filepath.Base(name)})
+
+	if w.format == "csv" {
+		w.csv = csv.NewWriter(w.buf)
+		if err := w.csv.Write(chunkCSVColumns); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		return nil
+	}
+	if _, err := w.buf.WriteString("[\n"); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	return nil
+}
+
+// closeChunk finishes the current chunk file, if any, and records its size.
+func (w *chunkWriter) closeChunk() error {
+	if w.f == nil {
+		return nil
+	}
+	f := w.f
+	w.f = nil
+	// The temporary file is gone once renamed; this only matters on failure.
+	defer w.dropTemp()
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if w.format == "csv" {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+	} else if _, err := w.buf.WriteString("\n]\n"); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	last := &w.chunks[len(w.chunks)-1]
+	last.Events, last.Bytes = w.events, info.Size()
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	if err := os.Rename(f.Name(), w.name); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	name := w.name
+	w.files = append(w.files, name)
+	w.cleanups = append(w.cleanups, onInterrupt(func() { os.Remove(name) }))
+	return nil
+}
+
+// abort closes and removes every chunk written so far.
+func (w *chunkWriter) abort() {
+	if w.f != nil {
+		w.f.Close()
+		os.Remove(w.f.Name())
+		w.dropTemp()
+		w.f = nil
+	}
+	for _, name := range w.files {
+		os.Remove(name)
+	}
+	w.unregister()
+}
+
+// unregister keeps the finished chunks if the process is interrupted, once
+// the manifest listing them is written.
+func (w *chunkWriter) unregister() {
+	for _, remove := range w.cleanups {
+		remove()
+	}
+	w.cleanups = nil
+}
+
+// eventCell formats an event field for a CSV cell.
+func eventCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// errStopEvents is returned by a decodeEventStream callback to stop early.
+var errStopEvents = errors.New("stop")
+
+// decodeEventStream calls fn for each event in an events response, decoding
+// one event at a time so large scans are never held in memory. The response
+// is either a bare array or an object with an "events" array. Decoding stops
+// early, without error, if fn returns errStopEvents.
+func decodeEventStream(r io.Reader, fn func(map[string]interface{}) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == json.Delim('{') {
+		found := false
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if key == "events" {
+				if tok, err = dec.Token(); err != nil {
+					return err
+				}
+				found = true
+				break
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("unexpected events response: no events array")
+		}
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("unexpected events response")
+	}
+
+	for dec.More() {
+		var event map[string]interface{}
+		if err := dec.Decode(&event); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			if errors.Is(err, errStopEvents) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// exportChunked streams a scan's events into numbered chunk files of at most
+// perFile events next to outFile and writes a <base>.manifest.json listing
+// them. maxEvents > 0 caps the total exported; red, if non-nil, redacts each
+// event. It returns the manifest path and the total bytes written. On failure
+// every chunk is removed.
+func exportChunked(c *client.Client, scanID, format, outFile string, perFile, maxEvents int, red *redactor) (string, int, error) {
+	base := strings.TrimSuffix(outFile, filepath.Ext(outFile))
+	w := &chunkWriter{base: base, format: format, perFile: perFile}
+
+	ctx, cancel := exportContext()
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := c.Download(ctx, fmt.Sprintf("/api/scans/%s/events", scanID), pw)
+		pw.CloseWithError(err)
+	}()
+
+	total := 0
+	err := decodeEventStream(pr, func(event map[string]interface{}) error {
+		if maxEvents > 0 && total == maxEvents {
+			return errStopEvents
+		}
+		total++
+		if red != nil {
+			red.value(event)
+		}
+		return w.add(event)
+	})
+	pr.Close()
+	if err == nil {
+		err = w.closeChunk()
+	}
+	if err != nil {
+		w.abort()
+		return "", 0, exportFailure(ctx, err, scanID)
+	}
+
+	manifest := exportManifest{ScanID: scanID, Format: format, EventsPerFile: perFile, TotalEvents: total, Chunks: w.chunks}
+	if manifest.Chunks == nil {
+		manifest.Chunks = []exportChunk{}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		w.abort()
+		return "", 0, err
+	}
+	manifestFile := base + ".manifest.json"
+	if err := writeBytesAtomic(manifestFile, append(data, '\n')); err != nil {
+		w.abort()
+		return "", 0, err
+	}
+	w.unregister()
+
+	size := int64(len(data) + 1)
+	for _, ch := range w.chunks {
+		size += ch.Bytes
+	}
+	return manifestFile, int(size), nil
+}
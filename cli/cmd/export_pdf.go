@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+var exportPDFCmd = &cobra.Command{
+	Use:   "pdf [scan-id]",
+	Short: "Export a scan summary report as PDF",
+	Long: `Export a scan summary report as PDF.
+
+The report has a header with the scan's metadata, a summary of correlation
+findings by risk level, the findings themselves and the most common event
+types. The server has no synchronous PDF endpoint, so the report is rendered
+locally from the scan, event and correlation data.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport("pdf", "pdf"),
+}
+
+// pdfTopEventTypes is the number of event types listed in a PDF report.
+const pdfTopEventTypes = 15
+
+type pdfFinding struct {
+	Title      string `json:"title"`
+	RuleName   string `json:"rule_name"`
+	Risk       string `json:"rule_risk"`
+	EventCount int    `json:"event_count"`
+}
+
+type riskCount struct {
+	Risk     string
+	Findings int
+	Events   int
+}
+
+// findingsByRisk counts findings and their events per risk level, highest
+// risk first. Every known level is included, even with no findings; unknown
+// levels follow in name order.
+func findingsByRisk(findings []pdfFinding) []riskCount {
+	counts := make(map[string]*riskCount)
+	for i := len(riskLevels) - 1; i >= 0; i-- {
+		counts[riskLevels[i]] = &riskCount{Risk: riskLevels[i]}
+	}
+	for _, f := range findings {
+		risk := strings.ToUpper(f.Risk)
+		if risk == "" {
+			risk = "INFO"
+		}
+		if counts[risk] == nil {
+			counts[risk] = &riskCount{Risk: risk}
+		}
+		counts[risk].Findings++
+		counts[risk].Events += f.EventCount
+	}
+
+	out := make([]riskCount, 0, len(counts))
+	for _, rc := range counts {
+		out = append(out, *rc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ri, rj := riskRank(out[i].Risk), riskRank(out[j].Risk)
+		if ri != rj {
+			return ri > rj
+		}
+		return out[i].Risk < out[j].Risk
+	})
+	return out
+}
+
+// writePDFExport fetches a scan with its events and correlations and renders
+// a summary report to path, returning the resulting file size.
+func writePDFExport(c *client.Client, scanID, path string) (int, error) {
+	var scan scanDetail
+	if err := c.Get(fmt.Sprintf("/api/scans/%s", scanID), &scan); err != nil {
+		return 0, notFound(err, "scan", scanID)
+	}
+	var evResp interface{}
+	if err := c.Get(fmt.Sprintf("/api/scans/%s/events", scanID), &evResp); err != nil {
+		return 0, notFound(err, "scan", scanID)
+	}
+	events, ok := eventItems(evResp)
+	if !ok {
+		return 0, fmt.Errorf("unexpected events response for scan %s", scanID)
+	}
+	var corrResp struct {
+		Correlations []pdfFinding `json:"correlations"`
+	}
+	if err := c.Get(fmt.Sprintf("/api/scans/%s/correlations", scanID), &corrResp); err != nil {
+		return 0, notFound(err, "scan", scanID)
+	}
+	findings := corrResp.Correlations
+	sort.SliceStable(findings, func(i, j int) bool {
+		return riskRank(findings[i].Risk) > riskRank(findings[j].Risk)
+	})
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("SpiderFoot scan report: "+scan.Name, true)
+	pdf.SetCreator("sf", true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	heading := func(text string) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.CellFormat(0, 8, tr(text), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+	row := func(widths []float64, cells []string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		for i, cell := range cells {
+			align := "L"
+			if i > 0 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 6, tr(cell), "1", 0, align, bold, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr("SpiderFoot Scan Report"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, kv := range [][2]string{
+		{"Name", scan.Name},
+		{"Target", scan.Target},
+		{"Status", scan.Status},
+		{"Started", formatEpoch(scan.StartedAt)},
+		{"Ended", formatEpoch(scan.EndedAt)},
+		{"Scan ID", scan.ScanID},
+		{"Events", fmt.Sprintf("%d", len(events))},
+	} {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(30, 6, tr(kv[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, tr(kv[1]), "", 1, "L", false, 0, "")
+	}
+
+	heading("Findings by Risk")
+	pdf.SetFillColor(230, 230, 230)
+	widths := []float64{50, 40, 40}
+	row(widths, []string{"Risk", "Findings", "Events"}, true)
+	for _, rc := range findingsByRisk(findings) {
+		row(widths, []string{rc.Risk, fmt.Sprintf("%d", rc.Findings), fmt.Sprintf("%d", rc.Events)}, false)
+	}
+
+	heading("Findings")
+	if len(findings) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, 6, "No correlation findings.", "", 1, "L", false, 0, "")
+	}
+	for _, f := range findings {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.MultiCell(0, 5, tr(fmt.Sprintf("[%s] %s", strings.ToUpper(f.Risk), f.Title)), "", "L", false)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, tr(fmt.Sprintf("Rule: %s, %d events", f.RuleName, f.EventCount)), "", "L", false)
+		pdf.Ln(1)
+	}
+
+	counts := make(map[string]int)
+	for _, e := range events {
+		counts[fmt.Sprintf("%v", e["type"])]++
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	heading("Top Event Types")
+	widths = []float64{100, 30}
+	row(widths, []string{"Type", "Events"}, true)
+	for _, t := range types[:min(pdfTopEventTypes, len(types))] {
+		row(widths, []string{t, fmt.Sprintf("%d", counts[t])}, false)
+	}
+
+	err := writeFileAtomic(path, func(f *os.File) error {
+		if err := pdf.Output(f); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return int(fi.Size()), nil
+}
+
+func init() {
+	exportCmd.AddCommand(exportPDFCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// redactedText replaces every redacted value.
+const redactedText = "[REDACTED]"
+
+// redactor applies the redact.patterns and redact.fields config rules to
+// export data. Values of a listed field (a JSON key or CSV column, matched
+// case-insensitively) are replaced outright; elsewhere, text matching a
+// pattern is replaced. count totals the replacements across all exports.
+// With anon set, remaining values are also pseudonymized.
+type redactor struct {
+	patterns []*regexp.Regexp
+	fields   map[string]bool
+	count    atomic.Int64
+	anon     *anonymizer
+}
+
+// newRedactor builds a redactor from the config rules.
+func newRedactor() (*redactor, error) {
+	r := &redactor{fields: make(map[string]bool)}
+	for _, p := range viper.GetStringSlice("redact.patterns") {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact.patterns entry %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	for _, f := range viper.GetStringSlice("redact.fields") {
+		r.fields[strings.ToLower(f)] = true
+	}
+	if len(r.patterns) == 0 && len(r.fields) == 0 {
+		return nil, fmt.Errorf("--redact needs rules: set redact.patterns and/or redact.fields in the config file")
+	}
+	return r, nil
+}
+
+// text replaces every pattern match in s, the value of field key, then
+// pseudonymizes what is left unless key is a structural field.
+func (r *redactor) text(key, s string) string {
+	for _, re := range r.patterns {
+		n := len(re.FindAllStringIndex(s, -1))
+		if n == 0 {
+			continue
+		}
+		r.count.Add(int64(n))
+		s = re.ReplaceAllLiteralString(s, redactedText)
+	}
+	if r.anon != nil && !anonymizeSkip[strings.ToLower(key)] {
+		s = r.anon.text(s)
+	}
+	return s
+}
+
+// value redacts a decoded JSON value in place and returns it.
+func (r *redactor) value(v interface{}) interface{} {
+	return r.field("", v)
+}
+
+// field redacts v, the value of JSON key key, in place and returns it.
+func (r *redactor) field(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if r.fields[strings.ToLower(k)] && val != nil {
+				r.count.Add(1)
+				v[k] = redactedText
+				continue
+			}
+			v[k] = r.field(k, val)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = r.field(key, v[i])
+		}
+		return v
+	case string:
+		return r.text(key, v)
+	}
+	return v
+}
+
+// summary describes what was replaced, one line per kind of rule in use.
+func (r *redactor) summary() []string {
+	var lines []string
+	if len(r.patterns) > 0 || len(r.fields) > 0 {
+		lines = append(lines, fmt.Sprintf("Redacted %d values", r.count.Load()))
+	}
+	if r.anon != nil {
+		lines = append(lines, fmt.Sprintf("Pseudonymized %d values", r.anon.count.Load()))
+	}
+	return lines
+}
+
+// redact rewrites an export body, which is CSV for the csv format and JSON
+// otherwise.
+func (r *redactor) redact(format string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if format == "csv" {
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("redacting export: %w", err)
+		}
+		for i, rec := range records {
+			if i == 0 {
+				continue
+			}
+			for j, cell := range rec {
+				if j < len(records[0]) && r.fields[strings.ToLower(records[0][j])] && cell != "" {
+					r.count.Add(1)
+					rec[j] = redactedText
+				} else {
+					rec[j] = r.text(records[0][j], cell)
+				}
+			}
+		}
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(records); err != nil {
+			return nil, fmt.Errorf("redacting export: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("redacting export: %w", err)
+	}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.value(v)); err != nil {
+		return nil, fmt.Errorf("redacting export: %w", err)
+	}
+	return buf.Bytes(), nil
+}
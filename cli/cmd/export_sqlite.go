@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+
+	// Pure-Go SQLite driver, registered as "sqlite".
+	_ "modernc.org/sqlite"
+)
+
+var exportSQLiteCmd = &cobra.Command{
+	Use:   "sqlite [scan-id]",
+	Short: "Export scan events and correlations as a SQLite database",
+	Long: `Export scan events and correlations as a SQLite database.
+
+The database has an "events" table and a "correlations" table, so results can
+be queried offline, e.g.:
+
+  sqlite3 spiderfoot_<scan-id>.db "SELECT type, COUNT(*) FROM events GROUP BY type"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport("sqlite", "db"),
+}
+
+const sqliteSchema = `
+CREATE TABLE events (
+	scan_id           TEXT NOT NULL,
+	hash              TEXT,
+	type              TEXT,
+	data              TEXT,
+	module            TEXT,
+	source_event_hash TEXT,
+	generated         REAL,
+	confidence        INTEGER,
+	visibility        INTEGER,
+	risk              INTEGER
+);
+CREATE INDEX events_type ON events (type);
+CREATE INDEX events_hash ON events (hash);
+CREATE TABLE correlations (
+	scan_id     TEXT NOT NULL,
+	id          TEXT,
+	title       TEXT,
+	rule_id     TEXT,
+	rule_risk   TEXT,
+	rule_name   TEXT,
+	rule_descr  TEXT,
+	rule_logic  TEXT,
+	event_count INTEGER
+);`
+
+// sqliteValue converts a decoded JSON value into something database/sql can
+// store, encoding objects and arrays as JSON text.
+func sqliteValue(v interface{}) interface{} {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return v
+}
+
+// writeSQLiteExport fetches a scan's events and correlations and writes them
+// to a fresh SQLite database at path, returning the resulting file size.
+func writeSQLiteExport(c *client.Client, scanID, path string) (int, error) {
+	var evResp interface{}
+	if err := c.Get(fmt.Sprintf("/api/scans/%s/events", scanID), &evResp); err != nil {
+		return 0, notFound(err, "scan", scanID)
+	}
+	events, ok := eventItems(evResp)
+	if !ok {
+		return 0, fmt.Errorf("unexpected events response for scan %s", scanID)
+	}
+	var corrResp struct {
+		Correlations []map[string]interface{} `json:"correlations"`
+	}
+	if err := c.Get(fmt.Sprintf("/api/scans/%s/correlations", scanID), &corrResp); err != nil {
+		return 0, notFound(err, "scan", scanID)
+	}
+
+	// Build the database in an empty temporary file, so re-exports don't
+	// append to stale data and an interrupted export leaves no partial file.
+	err := writeFileAtomic(path, func(f *os.File) error {
+		return writeSQLiteDB(f.Name(), scanID, events, corrResp.Correlations)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return int(fi.Size()), nil
+}
+
+// writeSQLiteDB writes a scan's events and correlations into the SQLite
+// database at path.
+func writeSQLiteDB(path, scanID string, events, correlations []map[string]interface{}) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("writing database: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("creating tables: %w", err)
+	}
+	for _, e := range events {
+		_, err := tx.Exec(`INSERT INTO events VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			scanID, sqliteValue(e["hash"]), sqliteValue(e["type"]), sqliteValue(e["data"]),
+			sqliteValue(e["module"]), sqliteValue(e["source_event_hash"]), sqliteValue(e["generated"]),
+			sqliteValue(e["confidence"]), sqliteValue(e["visibility"]), sqliteValue(e["risk"]))
+		if err != nil {
+			return fmt.Errorf("writing events: %w", err)
+		}
+	}
+	for _, r := range correlations {
+		_, err := tx.Exec(`INSERT INTO correlations VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			scanID, sqliteValue(r["id"]), sqliteValue(r["title"]), sqliteValue(r["rule_id"]),
+			sqliteValue(r["rule_risk"]), sqliteValue(r["rule_name"]), sqliteValue(r["rule_descr"]),
+			sqliteValue(r["rule_logic"]), sqliteValue(r["event_count"]))
+		if err != nil {
+			return fmt.Errorf("writing correlations: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("writing database: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("closing database: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	exportCmd.AddCommand(exportSQLiteCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// fieldColumns resolves --fields against a command's columns. header holds the
+// display labels and keys the matching JSON field names; each requested field
+// may name either, case-insensitively. Without --fields, the command's
+// columns.<name> config list is used instead, so a shared config can fix the
+// columns a team sees. It returns the selected column indexes in the requested
+// order, or nil when neither is set.
+func fieldColumns(name string, header, keys []string) ([]int, error) {
+	raw, source := viper.GetString("fields"), "field"
+	if raw == "" {
+		raw, source = strings.Join(viper.GetStringSlice("columns."+name), ","), "columns."+name+" entry"
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	var cols []int
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		found := -1
+		for i := range keys {
+			if strings.EqualFold(f, keys[i]) || strings.EqualFold(f, header[i]) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return nil, fmt.Errorf("unknown %s %q (available: %s)", source, f, strings.Join(keys, ", "))
+		}
+		cols = append(cols, found)
+	}
+	return cols, nil
+}
+
+// selectColumns narrows header and rows to cols. A nil cols keeps everything.
+func selectColumns(header []string, rows [][]string, cols []int) ([]string, [][]string) {
+	if cols == nil {
+		return header, rows
+	}
+	pick := func(row []string) []string {
+		out := make([]string, len(cols))
+		for i, c := range cols {
+			if c < len(row) {
+				out[i] = row[c]
+			}
+		}
+		return out
+	}
+	selected := make([][]string, len(rows))
+	for i, row := range rows {
+		selected[i] = pick(row)
+	}
+	return pick(header), selected
+}
+
+// totalsLabel puts label in the first empty cell of a totals row, so the row
+// is labelled whichever columns --fields selects. It returns row.
+func totalsLabel(row []string, label string) []string {
+	for i, cell := range row {
+		if cell == "" {
+			row[i] = label
+			break
+		}
+	}
+	return row
+}
+
+// countLabel labels a total with its noun, e.g. "1 module" or "3 modules".
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// selectJSONFields narrows v, an object or array of objects, to the JSON keys
+// of cols. A nil cols returns v unchanged.
+func selectJSONFields(v interface{}, keys []string, cols []int) interface{} {
+	if cols == nil {
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+
+	pick := func(item interface{}) interface{} {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return item
+		}
+		out := make(map[string]interface{}, len(cols))
+		for _, c := range cols {
+			out[keys[c]] = m[keys[c]]
+		}
+		return out
+	}
+	if items, ok := generic.([]interface{}); ok {
+		for i, item := range items {
+			items[i] = pick(item)
+		}
+		return items
+	}
+	return pick(generic)
+}
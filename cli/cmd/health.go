@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -10,40 +13,93 @@ import (
 )
 
 type healthResp struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
-	Uptime  int64  `json:"uptime_seconds"`
+	Status     string            `json:"status"`
+	Version    string            `json:"version"`
+	Uptime     int64             `json:"uptime_seconds"`
+	Components map[string]string `json:"components,omitempty"`
 }
 
 var healthCmd = &cobra.Command{
 	Use:   "health",
 	Short: "Check the SpiderFoot API server health",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if showSchema, _ := cmd.Flags().GetBool("schema"); showSchema {
+			s, err := schemaFor("health")
+			if err != nil {
+				return err
+			}
+			output.PrintJSON(s)
+			return nil
+		}
+
+		showComponents, _ := cmd.Flags().GetBool("components")
 		c := client.New()
 		var resp healthResp
-		if err := c.Get("/health", &resp); err != nil {
+		start := time.Now()
+		err := c.Get("/health", &resp)
+		if history, _ := cmd.Flags().GetBool("history"); history {
+			check := healthCheck{
+				Timestamp: start.UTC().Format(time.RFC3339),
+				Server:    c.BaseURL,
+				Status:    resp.Status,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				check.Status, check.Error = healthUnreachable, err.Error()
+			}
+			recordHealth(check)
+		}
+		if err != nil {
 			output.Error("Server unreachable: %v", err)
 			return err
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
-			statusColor := color.GreenString(resp.Status)
-			if resp.Status != "ok" && resp.Status != "healthy" {
-				statusColor = color.RedString(resp.Status)
-			}
-			fmt.Printf("Status:   %s\n", statusColor)
+			fmt.Printf("Status:   %s\n", colorHealth(resp.Status))
 			fmt.Printf("Version:  %s\n", resp.Version)
 			if resp.Uptime > 0 {
 				fmt.Printf("Uptime:   %ds\n", resp.Uptime)
 			}
+			if showComponents {
+				if len(resp.Components) == 0 {
+					fmt.Println("Components: not reported by server")
+					break
+				}
+				names := make([]string, 0, len(resp.Components))
+				for name := range resp.Components {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				fmt.Println("Components:")
+				for _, name := range names {
+					fmt.Printf("  %-16s %s\n", name+":", colorHealth(resp.Components[name]))
+				}
+			}
 		}
 		return nil
 	},
 }
 
+// colorHealth colors a health status green when healthy, yellow when degraded
+// and red otherwise.
+func colorHealth(status string) string {
+	switch strings.ToLower(status) {
+	case "ok", "healthy", "up":
+		return color.GreenString(status)
+	case "degraded", "warning":
+		return color.YellowString(status)
+	default:
+		return color.RedString(status)
+	}
+}
+
 func init() {
+	healthCmd.Flags().Bool("components", false, "Show per-component health (database, queue, workers)")
+	healthCmd.Flags().Bool("history", false, "Append the result and latency to the local health history (see 'sf health report')")
+	healthCmd.Flags().Bool("schema", false, "Print the JSON schema of the health response instead of querying the server")
+
 	rootCmd.AddCommand(healthCmd)
 }
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// healthCheck is one line of the local health history.
+type healthCheck struct {
+	Timestamp string `json:"timestamp"`
+	Server    string `json:"server"`
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthReport summarizes the health history of one server over a window.
+type healthReport struct {
+	Server       string  `json:"server"`
+	Since        string  `json:"since"`
+	Checks       int     `json:"checks"`
+	Up           int     `json:"up"`
+	Degraded     int     `json:"degraded"`
+	Down         int     `json:"down"`
+	Availability float64 `json:"availability_percent"`
+	AvgLatencyMS int64   `json:"avg_latency_ms"`
+	MaxLatencyMS int64   `json:"max_latency_ms"`
+	LastDown     string  `json:"last_down,omitempty"`
+}
+
+// healthUnreachable is the status recorded for a server that could not be reached.
+const healthUnreachable = "unreachable"
+
+// healthHistoryFile returns the path of the local health history.
+func healthHistoryFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "health.jsonl"), nil
+}
+
+// recordHealth appends a health check result to the history. Failures are
+// reported as warnings and never fail the command.
+func recordHealth(check healthCheck) {
+	path, err := healthHistoryFile()
+	if err == nil {
+		var line []byte
+		if line, err = json.Marshal(check); err == nil {
+			var f *os.File
+			if f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+				_, err = f.Write(append(line, '\n'))
+				f.Close()
+			}
+		}
+	}
+	if err != nil {
+		output.Warn("Could not write health history: %v", err)
+	}
+}
+
+// summarizeHealth reports on the checks of server made at or after since.
+// A check counts as available when the server answered with a healthy or
+// degraded status.
+func summarizeHealth(checks []healthCheck, server string, since time.Time) healthReport {
+	r := healthReport{Server: server, Since: since.UTC().Format(time.RFC3339)}
+	var total int64
+	for _, c := range checks {
+		if c.Server != server {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, c.Timestamp)
+		if err != nil || t.Before(since) {
+			continue
+		}
+		r.Checks++
+		total += c.LatencyMS
+		r.MaxLatencyMS = max(r.MaxLatencyMS, c.LatencyMS)
+		switch strings.ToLower(c.Status) {
+		case "ok", "healthy", "up":
+			r.Up++
+		case "degraded", "warning":
+			r.Degraded++
+		default:
+			r.Down++
+			r.LastDown = c.Timestamp
+		}
+	}
+	if r.Checks > 0 {
+		r.Availability = float64(r.Up+r.Degraded) * 100 / float64(r.Checks)
+		r.AvgLatencyMS = total / int64(r.Checks)
+	}
+	return r
+}
+
+var healthReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize availability from the health history",
+	Long: `Summarize availability from the health history.
+
+Checks are recorded by 'sf health --history', e.g. from cron. The report covers
+the current server over the --since window.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceStr, _ := cmd.Flags().GetString("since")
+		since, err := parseTimeBound(sinceStr, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+
+		path, err := healthHistoryFile()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			fmt.Println("No health history recorded. Record checks with 'sf health --history'.")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading health history: %w", err)
+		}
+		defer f.Close()
+
+		var checks []healthCheck
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var c healthCheck
+			if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+				continue
+			}
+			checks = append(checks, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("reading health history: %w", err)
+		}
+
+		r := summarizeHealth(checks, strings.TrimRight(viper.GetString("server"), "/"), since)
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(r)
+		case output.CSV:
+			output.PrintCSV(
+				[]string{"server", "since", "checks", "up", "degraded", "down", "availability_percent", "avg_latency_ms", "max_latency_ms", "last_down"},
+				[][]string{{r.Server, r.Since, strconv.Itoa(r.Checks), strconv.Itoa(r.Up), strconv.Itoa(r.Degraded), strconv.Itoa(r.Down),
+					strconv.FormatFloat(r.Availability, 'f', 2, 64), strconv.FormatInt(r.AvgLatencyMS, 10), strconv.FormatInt(r.MaxLatencyMS, 10), r.LastDown}})
+		default:
+			if r.Checks == 0 {
+				fmt.Printf("No checks of %s recorded since %s.\n", r.Server, since.Local().Format("2006-01-02 15:04"))
+				return nil
+			}
+			fmt.Printf("Server:        %s\n", r.Server)
+			fmt.Printf("Since:         %s\n", since.Local().Format("2006-01-02 15:04"))
+			fmt.Printf("Checks:        %d (%d up, %d degraded, %d down)\n", r.Checks, r.Up, r.Degraded, r.Down)
+			fmt.Printf("Availability:  %.2f%%\n", r.Availability)
+			fmt.Printf("Latency:       %dms avg, %dms max\n", r.AvgLatencyMS, r.MaxLatencyMS)
+			if r.LastDown != "" {
+				fmt.Printf("Last down:     %s\n", r.LastDown)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	healthReportCmd.Flags().String("since", "7d", "Window to report on: a duration (24h, 7d, 4w) or a date")
+
+	healthCmd.AddCommand(healthReportCmd)
+}
@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/spiderfoot/spiderfoot-cli/internal/client"
 	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
@@ -17,8 +26,8 @@ func simpleGet(path string) func(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -28,6 +37,10 @@ func simpleGet(path string) func(cmd *cobra.Command, args []string) error {
 
 // printGenericResponse prints an arbitrary response value in a human-readable form.
 func printGenericResponse(resp interface{}) {
+	if viper.GetBool("flatten") {
+		output.PrintFlattened(resp)
+		return
+	}
 	switch v := resp.(type) {
 	case map[string]interface{}:
 		for key, val := range v {
@@ -41,3 +54,91 @@ func printGenericResponse(resp interface{}) {
 		fmt.Println(resp)
 	}
 }
+
+// confirm asks a yes/no question on stderr and reports whether the user
+// answered yes. When stdin is not a terminal there is nobody to ask, so it
+// returns true.
+func confirm(prompt string) bool {
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// addJSONPathFlag registers the --json-path flag on a detail command.
+func addJSONPathFlag(cmd *cobra.Command) {
+	cmd.Flags().String("json-path", "", "Print only the value at this dotted path (e.g. status, modules.0)")
+}
+
+// lookupJSONPath returns the value at a dotted path within v, as it would be
+// encoded to JSON. Numeric segments index into arrays.
+func lookupJSONPath(v interface{}, path string) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var cur interface{}
+	if err := dec.Decode(&cur); err != nil {
+		return nil, err
+	}
+
+	for _, seg := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			val, ok := node[seg]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", path)
+			}
+			cur = val
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("path %q not found", path)
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("path %q not found", path)
+		}
+	}
+	return cur, nil
+}
+
+// printJSONPath prints the value at path within v. Strings and numbers are
+// printed bare; objects and arrays as compact JSON.
+func printJSONPath(v interface{}, path string) error {
+	val, err := lookupJSONPath(v, path)
+	if err != nil {
+		return err
+	}
+	switch val := val.(type) {
+	case string:
+		fmt.Println(val)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(val)
+		fmt.Println(string(data))
+	case nil:
+		fmt.Println("null")
+	default:
+		fmt.Println(val)
+	}
+	return nil
+}
+
+// configDir returns the directory holding CLI state files (audit log, caches),
+// creating it if it does not exist.
+func configDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	dir := filepath.Join(base, "spiderfoot")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating config directory: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// historyEntry is one line of the local audit log.
+type historyEntry struct {
+	Timestamp string   `json:"timestamp"`
+	User      string   `json:"user,omitempty"`
+	Command   string   `json:"command"`
+	Args      []string `json:"args,omitempty"`
+	ResultID  string   `json:"result_id,omitempty"`
+	Server    string   `json:"server"`
+}
+
+// historyFile returns the path of the local audit log.
+func historyFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// isSecretFlag reports whether a flag's value must be redacted from the audit
+// log. Request bodies (sf api --data) may carry credentials too.
+func isSecretFlag(name string) bool {
+	name = strings.ToLower(name)
+	if name == "data" {
+		return true
+	}
+	for _, s := range []string{"key", "token", "password", "secret"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordHistory appends a mutating command to the audit log when --audit-log
+// is enabled. Failures are reported as warnings and never fail the command.
+func recordHistory(cmd *cobra.Command, args []string, resultID string) {
+	if !viper.GetBool("audit_log") {
+		return
+	}
+
+	entry := historyEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Command:   cmd.CommandPath(),
+		Args:      append([]string{}, args...),
+		ResultID:  resultID,
+		Server:    viper.GetString("server"),
+	}
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		val := f.Value.String()
+		if isSecretFlag(f.Name) {
+			val = "[REDACTED]"
+		}
+		entry.Args = append(entry.Args, fmt.Sprintf("--%s=%s", f.Name, val))
+	})
+
+	if err := appendHistory(entry); err != nil {
+		output.Warn("Could not write audit log: %v", err)
+	}
+}
+
+func appendHistory(entry historyEntry) error {
+	path, err := historyFile()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the local audit log of mutating commands",
+	Long: `Show the local audit log of mutating commands.
+
+Recording is opt-in: pass --audit-log or set "audit_log: true" in the config
+file. Entries are appended as JSON lines to history.jsonl in the CLI config
+directory, with secret flag values redacted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		path, err := historyFile()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			fmt.Println("No history recorded. Enable with --audit-log or 'sf config set audit_log true'.")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading history: %w", err)
+		}
+		defer f.Close()
+
+		var entries []historyEntry
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e historyEntry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				continue
+			}
+			entries = append(entries, e)
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("reading history: %w", err)
+		}
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(entries)
+		case output.CSV:
+			header := []string{"Time", "User", "Command", "Args", "Result ID", "Server"}
+			rows := make([][]string, 0, len(entries))
+			for _, e := range entries {
+				rows = append(rows, []string{e.Timestamp, e.User, e.Command, strings.Join(e.Args, " "), e.ResultID, e.Server})
+			}
+			output.PrintCSV(header, rows)
+		default:
+			header := []string{"Time", "User", "Command", "Args", "Result ID", "Server"}
+			rows := make([][]string, 0, len(entries))
+			for _, e := range entries {
+				rows = append(rows, []string{e.Timestamp, e.User, e.Command, strings.Join(e.Args, " "), truncID(e.ResultID), e.Server})
+			}
+			output.PrintTable(header, rows)
+		}
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().Int("limit", 50, "Show only the most recent N entries (0 = all)")
+
+	rootCmd.AddCommand(historyCmd)
+}
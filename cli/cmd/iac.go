@@ -47,10 +47,11 @@ var iacGenerateCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/iac", args[0]), bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			fmt.Printf("Provider:  %s\n", provider)
 			if summary, ok := resp["profile_summary"].(map[string]interface{}); ok {
@@ -97,10 +98,11 @@ var iacValidateCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/iac", args[0]), bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp["validation"])
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp["validation"])
 		default:
 			if results, ok := resp["validation"].([]interface{}); ok {
 				passed, failed := 0, 0
@@ -140,8 +142,8 @@ var iacProvidersCmd = &cobra.Command{
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(providers)
+		case output.JSON, output.YAML:
+			output.PrintStructured(providers)
 		default:
 			fmt.Println("Supported cloud providers:")
 			for _, p := range providers {
@@ -33,8 +33,8 @@ var keysListCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Name", "Created", "Status"}
@@ -73,9 +73,14 @@ var keysCreateCmd = &cobra.Command{
 		if err := c.Post("/api/keys", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		var id string
+		if m, ok := resp.(map[string]interface{}); ok {
+			id = fmt.Sprintf("%v", m["id"])
+		}
+		recordHistory(cmd, args, id)
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if m, ok := resp.(map[string]interface{}); ok {
 				output.Success("API key created: %v", m["id"])
@@ -104,6 +109,9 @@ var keysGetCmd = &cobra.Command{
 		if err := c.Get(fmt.Sprintf("/api/keys/%s", args[0]), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -121,6 +129,7 @@ var keysDeleteCmd = &cobra.Command{
 		if err := c.Delete(fmt.Sprintf("/api/keys/%s", args[0]), nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("API key %s deleted", args[0])
 		return nil
 	},
@@ -138,12 +147,14 @@ var keysRevokeCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/keys/%s/revoke", args[0]), nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("API key %s revoked", args[0])
 		return nil
 	},
 }
 
 func init() {
+	addJSONPathFlag(keysGetCmd)
 	keysCreateCmd.Flags().StringP("name", "n", "", "Key name (required)")
 
 	keysCmd.AddCommand(keysListCmd)
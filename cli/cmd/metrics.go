@@ -39,8 +39,8 @@ var metricsDashboardCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -56,6 +56,7 @@ var metricsResetCmd = &cobra.Command{
 		if err := c.Post("/api/scan-metrics/reset", nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, "")
 		output.Success("Scan metrics counters reset")
 		return nil
 	},
@@ -3,8 +3,12 @@ package cmd
 import (
 	"fmt"
 	"net/url"
+	"sort"
+	"strings"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/spiderfoot/spiderfoot-cli/internal/client"
 	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
@@ -17,6 +21,14 @@ type moduleInfo struct {
 	Consumes    []string `json:"consumes"`
 	Categories  []string `json:"categories"`
 	APIKeyReq   bool     `json:"apiKeyRequired"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+}
+
+type moduleStatusResp struct {
+	Modules []struct {
+		Module  string `json:"module"`
+		Enabled bool   `json:"enabled"`
+	} `json:"modules"`
 }
 
 var modulesCmd = &cobra.Command{
@@ -28,64 +40,248 @@ var modulesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available modules",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cols, err := fieldColumns("modules_list", modulesListHeader, modulesListKeys)
+		if err != nil {
+			return err
+		}
+
 		c := client.New()
+		if c.Cache, err = responseCache(cmd); err != nil {
+			return err
+		}
 		filter, _ := cmd.Flags().GetString("filter")
 
-		path := "/api/data/modules"
-		params := url.Values{}
-		if filter != "" {
-			params.Set("type", filter)
+		sortBy, _ := cmd.Flags().GetString("sort")
+		reverse, _ := cmd.Flags().GetBool("reverse")
+		disabledOnly, _ := cmd.Flags().GetBool("disabled")
+
+		modules, err := fetchModules(c, filter)
+		if err != nil {
+			return err
 		}
-		if q := params.Encode(); q != "" {
-			path += "?" + q
+		// Older servers have no status endpoint; the column then shows "-".
+		if err := applyModuleStatus(c, modules); err != nil && disabledOnly {
+			return fmt.Errorf("fetching module status: %w", err)
 		}
-
-		var modules []moduleInfo
-		if err := c.Get(path, &modules); err != nil {
+		if disabledOnly {
+			modules = disabledModules(modules)
+		}
+		if err := sortModules(modules, sortBy, reverse); err != nil {
 			return err
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(modules)
+		case output.JSON, output.YAML:
+			output.PrintStructured(selectJSONFields(modules, modulesListKeys, cols))
 		case output.CSV:
-			header := []string{"Name", "Type", "Description", "API Key"}
 			rows := make([][]string, 0, len(modules))
 			for _, m := range modules {
-				rows = append(rows, []string{m.Name, m.Type, m.Description, fmt.Sprintf("%v", m.APIKeyReq)})
+				rows = append(rows, []string{m.Name, m.Type, m.Description, fmt.Sprintf("%v", m.APIKeyReq), moduleStatus(m)})
 			}
-			output.PrintCSV(header, rows)
+			output.PrintCSV(selectColumns(modulesListHeader, rows, cols))
 		default:
-			header := []string{"Name", "Type", "Description", "API Key"}
 			rows := make([][]string, 0, len(modules))
 			for _, m := range modules {
-				desc := m.Description
-				if len(desc) > 60 {
-					desc = desc[:57] + "..."
-				}
+				desc := truncateCell(m.Description, 60)
 				apiKey := "no"
 				if m.APIKeyReq {
 					apiKey = "yes"
 				}
-				rows = append(rows, []string{m.Name, m.Type, desc, apiKey})
+				rows = append(rows, []string{m.Name, m.Type, desc, apiKey, colorModuleStatus(moduleStatus(m))})
+			}
+			if totals, _ := cmd.Flags().GetBool("totals"); totals {
+				footer := moduleTypeTotals(modules)
+				header, all := selectColumns(modulesListHeader, append(rows, footer...), cols)
+				output.PrintTableFooter(header, all[:len(rows)], all[len(rows):])
+				break
 			}
-			output.PrintTable(header, rows)
+			output.PrintTable(selectColumns(modulesListHeader, rows, cols))
 			fmt.Printf("\nTotal: %d modules\n", len(modules))
 		}
 		return nil
 	},
 }
 
+// modulesListHeader and modulesListKeys are the modules list columns and
+// their JSON field names, as accepted by --fields.
+var (
+	modulesListHeader = []string{"Name", "Type", "Description", "API Key", "Status"}
+	modulesListKeys   = []string{"name", "type", "descr", "apiKeyRequired", "enabled"}
+)
+
+// moduleTypeTotals returns modules list footer rows counting the modules of
+// each type, by type, then all of them.
+func moduleTypeTotals(modules []moduleInfo) [][]string {
+	byType := make(map[string]int)
+	for _, m := range modules {
+		byType[m.Type]++
+	}
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	var footer [][]string
+	if len(types) > 1 {
+		for _, t := range types {
+			footer = append(footer, []string{countLabel(byType[t], "module"), t, "", "", ""})
+		}
+	}
+	return append(footer, []string{countLabel(len(modules), "module"), "all", "", "", ""})
+}
+
+// applyModuleStatus fills in Enabled from /api/data/modules/status for
+// modules whose listing did not include it.
+func applyModuleStatus(c *client.Client, modules []moduleInfo) error {
+	missing := false
+	for _, m := range modules {
+		if m.Enabled == nil {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return nil
+	}
+
+	var resp moduleStatusResp
+	if err := c.Get("/api/data/modules/status", &resp); err != nil {
+		return err
+	}
+	enabled := make(map[string]bool, len(resp.Modules))
+	for _, s := range resp.Modules {
+		enabled[s.Module] = s.Enabled
+	}
+	for i := range modules {
+		if e, ok := enabled[modules[i].Name]; ok && modules[i].Enabled == nil {
+			modules[i].Enabled = &e
+		}
+	}
+	return nil
+}
+
+// disabledModules returns the modules known to be installed but turned off.
+func disabledModules(modules []moduleInfo) []moduleInfo {
+	var out []moduleInfo
+	for _, m := range modules {
+		if m.Enabled != nil && !*m.Enabled {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// moduleStatus returns "enabled", "disabled", or "-" if the server did not
+// report the module's status.
+func moduleStatus(m moduleInfo) string {
+	switch {
+	case m.Enabled == nil:
+		return "-"
+	case *m.Enabled:
+		return "enabled"
+	default:
+		return "disabled"
+	}
+}
+
+// colorModuleStatus colors a module status for table output.
+func colorModuleStatus(status string) string {
+	if status == "disabled" {
+		return color.YellowString(status)
+	}
+	return status
+}
+
+// sortModules orders modules by name, type, or first category, breaking ties
+// by name.
+func sortModules(modules []moduleInfo, by string, reverse bool) error {
+	var key func(m moduleInfo) string
+	switch strings.ToLower(by) {
+	case "name":
+		key = func(m moduleInfo) string { return m.Name }
+	case "type":
+		key = func(m moduleInfo) string { return m.Type }
+	case "category":
+		key = func(m moduleInfo) string {
+			if len(m.Categories) == 0 {
+				return ""
+			}
+			return m.Categories[0]
+		}
+	default:
+		return fmt.Errorf("invalid --sort %q (expected name, type, or category)", by)
+	}
+
+	sort.SliceStable(modules, func(i, j int) bool {
+		a, b := modules[i], modules[j]
+		if reverse {
+			a, b = b, a
+		}
+		if ka, kb := strings.ToLower(key(a)), strings.ToLower(key(b)); ka != kb {
+			return ka < kb
+		}
+		return a.Name < b.Name
+	})
+	return nil
+}
+
+var modulesTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show how modules feed each other via event types",
+	Long: `Show how modules feed each other via event types.
+
+Starting from a seed event type (--root), the tree lists every module that
+consumes it, the event types those modules provide, the modules consuming
+those, and so on up to --depth module hops. Event types already expanded
+elsewhere in the tree are marked with "(see above)".
+
+Use -o dot to emit a Graphviz digraph instead, e.g.:
+  sf modules tree --root DOMAIN_NAME -o dot | dot -Tsvg > modules.svg`,
+	Annotations: map[string]string{formatsAnnotation: string(output.DOT)},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, _ := cmd.Flags().GetString("root")
+		depth, _ := cmd.Flags().GetInt("depth")
+		if root == "" {
+			return fmt.Errorf("--root is required")
+		}
+
+		modules, err := fetchModules(client.New(), "")
+		if err != nil {
+			return err
+		}
+		tree := buildModuleTree(modules, root, depth)
+
+		switch {
+		case strings.EqualFold(viper.GetString("output"), string(output.DOT)):
+			fmt.Print(moduleTreeDOT(tree))
+		case output.Structured():
+			output.PrintStructured(tree)
+		default:
+			fmt.Print(moduleTreeText(tree))
+		}
+		return nil
+	},
+}
+
 var modulesGetCmd = &cobra.Command{
 	Use:   "get [module-name]",
 	Short: "Get details for a specific module",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.New()
+		cache, err := responseCache(cmd)
+		if err != nil {
+			return err
+		}
+		c.Cache = cache
 		var resp interface{}
 		if err := c.Get(fmt.Sprintf("/api/data/modules/%s", url.PathEscape(args[0])), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -118,6 +314,7 @@ var modulesEnableCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/data/modules/%s/enable", url.PathEscape(args[0])), nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Module %s enabled", args[0])
 		return nil
 	},
@@ -132,16 +329,149 @@ var modulesDisableCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/data/modules/%s/disable", url.PathEscape(args[0])), nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Module %s disabled", args[0])
 		return nil
 	},
 }
 
+// fetchModules retrieves the module list, optionally filtered by module type.
+func fetchModules(c *client.Client, filter string) ([]moduleInfo, error) {
+	path := "/api/data/modules"
+	params := url.Values{}
+	if filter != "" {
+		params.Set("type", filter)
+	}
+	if q := params.Encode(); q != "" {
+		path += "?" + q
+	}
+
+	var modules []moduleInfo
+	if err := c.Get(path, &modules); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
+
+// moduleTreeNode is an event type or module in the module data-flow tree.
+type moduleTreeNode struct {
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"`
+	Repeated bool              `json:"repeated,omitempty"`
+	Children []*moduleTreeNode `json:"children,omitempty"`
+}
+
+// buildModuleTree expands the data-flow tree breadth-first from the root event
+// type, following up to depth module hops. Each event type is expanded only at
+// its shallowest occurrence; later occurrences are marked as repeated.
+func buildModuleTree(modules []moduleInfo, root string, depth int) *moduleTreeNode {
+	consumers := make(map[string][]moduleInfo)
+	for _, m := range modules {
+		for _, t := range m.Consumes {
+			if t != "*" {
+				consumers[t] = append(consumers[t], m)
+			}
+		}
+	}
+	for t := range consumers {
+		sort.Slice(consumers[t], func(i, j int) bool { return consumers[t][i].Name < consumers[t][j].Name })
+	}
+
+	rootNode := &moduleTreeNode{Name: root, Kind: "event"}
+	expanded := map[string]bool{root: true}
+	queue := []*moduleTreeNode{rootNode}
+	for level := 0; level < depth && len(queue) > 0; level++ {
+		var next []*moduleTreeNode
+		for _, n := range queue {
+			for _, m := range consumers[n.Name] {
+				mn := &moduleTreeNode{Name: m.Name, Kind: "module"}
+				for _, p := range m.Provides {
+					pn := &moduleTreeNode{Name: p, Kind: "event"}
+					if expanded[p] {
+						pn.Repeated = true
+					} else {
+						expanded[p] = true
+						next = append(next, pn)
+					}
+					mn.Children = append(mn.Children, pn)
+				}
+				n.Children = append(n.Children, mn)
+			}
+		}
+		queue = next
+	}
+	return rootNode
+}
+
+// moduleTreeText renders the tree with box-drawing connectors.
+func moduleTreeText(root *moduleTreeNode) string {
+	var b strings.Builder
+	b.WriteString(root.Name + "\n")
+	var walk func(n *moduleTreeNode, prefix string)
+	walk = func(n *moduleTreeNode, prefix string) {
+		for i, child := range n.Children {
+			connector, indent := "├── ", "│   "
+			if i == len(n.Children)-1 {
+				connector, indent = "└── ", "    "
+			}
+			label := child.Name
+			if child.Repeated {
+				label += " (see above)"
+			}
+			b.WriteString(prefix + connector + label + "\n")
+			walk(child, prefix+indent)
+		}
+	}
+	walk(root, "")
+	return b.String()
+}
+
+// moduleTreeDOT renders the tree as a Graphviz digraph, with event types as
+// ellipses and modules as boxes.
+func moduleTreeDOT(root *moduleTreeNode) string {
+	var b strings.Builder
+	b.WriteString("digraph modules {\n  rankdir=LR;\n")
+	nodes := make(map[string]bool)
+	edges := make(map[string]bool)
+	var walk func(n *moduleTreeNode)
+	walk = func(n *moduleTreeNode) {
+		if !nodes[n.Name] {
+			nodes[n.Name] = true
+			shape := "ellipse"
+			if n.Kind == "module" {
+				shape = "box"
+			}
+			fmt.Fprintf(&b, "  %q [shape=%s];\n", n.Name, shape)
+		}
+		for _, child := range n.Children {
+			edge := fmt.Sprintf("  %q -> %q;\n", n.Name, child.Name)
+			if !edges[edge] {
+				edges[edge] = true
+				b.WriteString(edge)
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+	b.WriteString("}\n")
+	return b.String()
+}
+
 func init() {
+	addJSONPathFlag(modulesGetCmd)
+	addCacheFlags(modulesGetCmd)
+	addCacheFlags(modulesListCmd)
 	modulesListCmd.Flags().StringP("filter", "f", "", "Filter by module type")
+	modulesListCmd.Flags().String("sort", "name", "Sort by name, type, or category")
+	modulesListCmd.Flags().Bool("reverse", false, "Reverse the sort order")
+	modulesListCmd.Flags().Bool("totals", false, "End the table with totals rows counting the modules of each type")
+	modulesListCmd.Flags().Bool("disabled", false, "Only show modules that are installed but disabled")
+	modulesTreeCmd.Flags().String("root", "", "Seed event type, e.g. DOMAIN_NAME (required)")
+	modulesTreeCmd.Flags().Int("depth", 3, "Maximum number of module hops to expand")
 
 	modulesCmd.AddCommand(modulesListCmd)
 	modulesCmd.AddCommand(modulesGetCmd)
+	modulesCmd.AddCommand(modulesTreeCmd)
 	modulesCmd.AddCommand(modulesStatsCmd)
 	modulesCmd.AddCommand(modulesCategoriesCmd)
 	modulesCmd.AddCommand(modulesTypesCmd)
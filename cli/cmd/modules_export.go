@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+// catalogFormats are the formats modules export can write.
+var catalogFormats = []string{"json", "csv", "yaml"}
+
+// catalogFormat picks the export format: format if set, else the extension
+// of file, else JSON.
+func catalogFormat(format, file string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".csv":
+			return "csv", nil
+		case ".yaml", ".yml":
+			return "yaml", nil
+		}
+		return "json", nil
+	}
+	format = strings.ToLower(format)
+	if format == "yml" {
+		format = "yaml"
+	}
+	if !slices.Contains(catalogFormats, format) {
+		return "", fmt.Errorf("invalid --format %q (expected %s)", format, strings.Join(catalogFormats, ", "))
+	}
+	return format, nil
+}
+
+// fetchModuleCatalog lists every module and fills in each one's full detail
+// and option descriptions, fetched concurrently. Fields are kept as the
+// server sends them; options go under "options". Modules whose detail could
+// not be fetched keep their summary and are returned in failed. The catalog
+// is sorted by module name.
+func fetchModuleCatalog(c *client.Client) (catalog []map[string]interface{}, failed []string, err error) {
+	if err := c.Get("/api/data/modules", &catalog); err != nil {
+		return nil, nil, err
+	}
+
+	sem := make(chan struct{}, resolveConcurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, m := range catalog {
+		name, _ := m["name"].(string)
+		if name == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(m map[string]interface{}, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var detail struct {
+				Module map[string]interface{} `json:"module"`
+			}
+			var opts struct {
+				Options map[string]interface{} `json:"options"`
+			}
+			base := "/api/data/modules/" + url.PathEscape(name)
+			err := c.Get(base, &detail)
+			if err == nil {
+				err = c.Get(base+"/options", &opts)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, name)
+				return
+			}
+			for k, v := range detail.Module {
+				m[k] = v
+			}
+			if opts.Options != nil {
+				m["options"] = opts.Options
+			}
+		}(m, name)
+	}
+	wg.Wait()
+
+	sort.Slice(catalog, func(i, j int) bool {
+		return fmt.Sprint(catalog[i]["name"]) < fmt.Sprint(catalog[j]["name"])
+	})
+	sort.Strings(failed)
+	return catalog, failed, nil
+}
+
+// encodeModuleCatalog renders the catalog in format. CSV has one row per
+// module, with lists joined by ";" and options as a JSON object.
+func encodeModuleCatalog(catalog []map[string]interface{}, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(catalog)
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"name", "type", "descr", "categories", "provides", "consumes", "apiKeyRequired", "options"})
+		for _, m := range catalog {
+			options := ""
+			if o, ok := m["options"]; ok {
+				data, err := json.Marshal(o)
+				if err != nil {
+					return nil, err
+				}
+				options = string(data)
+			}
+			_ = w.Write([]string{eventCell(m["name"]), eventCell(m["type"]), eventCell(m["descr"]),
+				catalogList(m["categories"]), catalogList(m["provides"]), catalogList(m["consumes"]),
+				eventCell(m["apiKeyRequired"]), options})
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	return append(data, '\n'), err
+}
+
+// catalogList joins a JSON list into one CSV cell.
+func catalogList(v interface{}) string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return eventCell(v)
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = eventCell(item)
+	}
+	return strings.Join(parts, ";")
+}
+
+var modulesExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the full module catalog with options, provides and consumes",
+	Long: `Export the full module catalog with options, provides and consumes.
+
+Unlike 'sf modules list', every field the server reports for each module is
+kept, plus its option descriptions, which takes a request or two per module.
+The catalog goes to stdout, or to --file in the format of its extension
+(.json, .csv, .yaml) unless --format is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		format, err := catalogFormat(formatFlag, file)
+		if err != nil {
+			return err
+		}
+
+		c := client.New()
+		stop := output.StartSpinner("Fetching module details...")
+		catalog, failed, err := fetchModuleCatalog(c)
+		stop()
+		if err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			output.Warn("Could not fetch details of %d module(s), exported with summary fields only: %s", len(failed), strings.Join(failed, ", "))
+		}
+		data, err := encodeModuleCatalog(catalog, format)
+		if err != nil {
+			return err
+		}
+
+		if file == "" {
+			os.Stdout.Write(data)
+			return nil
+		}
+		if err := writeBytesAtomic(file, data); err != nil {
+			return err
+		}
+		output.Success("Exported %d modules to %s", len(catalog), file)
+		return nil
+	},
+}
+
+func init() {
+	modulesExportCmd.Flags().StringP("file", "f", "", "Write the catalog to this file instead of stdout")
+	modulesExportCmd.Flags().String("format", "", "Catalog format: json, csv or yaml (default: from --file, else json)")
+
+	modulesCmd.AddCommand(modulesExportCmd)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// targetSeedTypes maps each --target-type to the event type a scan of that
+// kind of target starts from.
+var targetSeedTypes = map[string]string{
+	"domain":   "INTERNET_NAME",
+	"ip":       "IP_ADDRESS",
+	"netblock": "NETBLOCK_OWNER",
+	"email":    "EMAILADDR",
+	"url":      "INTERNET_NAME",
+	"phone":    "PHONE_NUMBER",
+	"name":     "HUMAN_NAME",
+	"asn":      "BGP_AS_OWNER",
+}
+
+type moduleRecommendation struct {
+	Module string `json:"module"`
+	Type   string `json:"type"`
+	Hop    int    `json:"hop"`
+	Via    string `json:"via"`
+}
+
+var modulesRecommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Suggest a module set for a target type",
+	Long: `Suggest a module set for a target type.
+
+Starting from the event type a scan of --target-type begins with, modules that
+consume it are recommended, then the modules consuming what those provide, and
+so on for --depth hops. The result ends with a --modules value ready to paste
+into 'sf scan start'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetType, _ := cmd.Flags().GetString("target-type")
+		depth, _ := cmd.Flags().GetInt("depth")
+		passiveOnly, _ := cmd.Flags().GetBool("passive-only")
+		seed, ok := targetSeedTypes[strings.ToLower(targetType)]
+		if !ok {
+			return fmt.Errorf("invalid --target-type %q (valid: %s)", targetType, strings.Join(targetTypes, ", "))
+		}
+
+		modules, err := fetchModules(client.New(), "")
+		if err != nil {
+			return err
+		}
+		recs := recommendModules(modules, seed, depth, passiveOnly)
+		names := make([]string, len(recs))
+		for i, r := range recs {
+			names[i] = r.Module
+		}
+		flag := strings.Join(names, ",")
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(map[string]interface{}{
+				"target_type": strings.ToLower(targetType),
+				"seed":        seed,
+				"modules":     recs,
+				"flag":        flag,
+			})
+		case output.CSV:
+			rows := make([][]string, 0, len(recs))
+			for _, r := range recs {
+				rows = append(rows, []string{r.Module, r.Type, strconv.Itoa(r.Hop), r.Via})
+			}
+			output.PrintCSV([]string{"module", "type", "hop", "via"}, rows)
+		default:
+			if len(recs) == 0 {
+				fmt.Printf("No modules consume %s.\n", seed)
+				return nil
+			}
+			rows := make([][]string, 0, len(recs))
+			for _, r := range recs {
+				rows = append(rows, []string{r.Module, r.Type, strconv.Itoa(r.Hop), r.Via})
+			}
+			output.PrintTable([]string{"Module", "Type", "Hop", "Via"}, rows)
+			fmt.Printf("\n--modules %s\n", flag)
+		}
+		return nil
+	},
+}
+
+// recommendModules walks the module data flow breadth-first from the seed
+// event type for up to depth hops, returning each module the first time it
+// can be reached, ordered by hop and then name. Via is the event type that
+// triggers the module. With passiveOnly, active modules are skipped and their
+// outputs are not followed.
+func recommendModules(modules []moduleInfo, seed string, depth int, passiveOnly bool) []moduleRecommendation {
+	consumers := make(map[string][]moduleInfo)
+	for _, m := range modules {
+		if passiveOnly && strings.EqualFold(m.Type, "active") {
+			continue
+		}
+		for _, t := range m.Consumes {
+			if t != "*" {
+				consumers[t] = append(consumers[t], m)
+			}
+		}
+	}
+
+	recs := []moduleRecommendation{}
+	picked := make(map[string]bool)
+	seen := map[string]bool{seed: true}
+	frontier := []string{seed}
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		var level []moduleRecommendation
+		var next []string
+		for _, t := range frontier {
+			for _, m := range consumers[t] {
+				if picked[m.Name] {
+					continue
+				}
+				picked[m.Name] = true
+				level = append(level, moduleRecommendation{Module: m.Name, Type: m.Type, Hop: hop, Via: t})
+				for _, p := range m.Provides {
+					if !seen[p] {
+						seen[p] = true
+						next = append(next, p)
+					}
+				}
+			}
+		}
+		sort.Slice(level, func(i, j int) bool { return level[i].Module < level[j].Module })
+		recs = append(recs, level...)
+		frontier = next
+	}
+	return recs
+}
+
+func init() {
+	modulesRecommendCmd.Flags().String("target-type", "", "Target type: "+strings.Join(targetTypes, ", ")+" (required)")
+	modulesRecommendCmd.Flags().Int("depth", 2, "Number of module hops to follow from the seed event type")
+	modulesRecommendCmd.Flags().Bool("passive-only", false, "Exclude active modules")
+	modulesCmd.AddCommand(modulesRecommendCmd)
+}
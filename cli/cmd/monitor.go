@@ -30,6 +30,9 @@ var monitorGetCmd = &cobra.Command{
 		if err := c.Get(fmt.Sprintf("/api/monitor/domains/%s", args[0]), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -49,8 +52,8 @@ var monitorChangesCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"Time", "Type", "Details"}
@@ -82,12 +85,14 @@ var monitorCheckCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/monitor/domains/%s/check", args[0]), nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Check triggered for %s", args[0])
 		return nil
 	},
 }
 
 func init() {
+	addJSONPathFlag(monitorGetCmd)
 	monitorChangesCmd.Flags().Int("limit", 20, "Maximum changes to return")
 
 	monitorCmd.AddCommand(monitorListCmd)
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spiderfoot/spiderfoot-cli/internal/client"
@@ -42,8 +41,8 @@ var reportListCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Scan", "Status", "Format", "Created"}
@@ -90,9 +89,10 @@ var reportGenerateCmd = &cobra.Command{
 		if err := c.Post("/api/reports/generate", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["report_id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Report generation started: %v", resp["report_id"])
 			fmt.Println("Use 'sf report status <id>' to check progress")
@@ -132,7 +132,9 @@ var reportDownloadCmd = &cobra.Command{
 
 		c := client.New()
 		path := fmt.Sprintf("/api/reports/%s/export?format=%s", args[0], format)
+		stop := output.StartSpinner(fmt.Sprintf("Downloading report %s...", args[0]))
 		data, _, err := c.GetRaw(path)
+		stop()
 		if err != nil {
 			return err
 		}
@@ -140,8 +142,8 @@ var reportDownloadCmd = &cobra.Command{
 		if outFile == "" {
 			outFile = fmt.Sprintf("report_%s.%s", args[0][:min(12, len(args[0]))], format)
 		}
-		if err := os.WriteFile(outFile, data, 0600); err != nil {
-			return fmt.Errorf("writing file: %w", err)
+		if err := writeBytesAtomic(outFile, data); err != nil {
+			return err
 		}
 		output.Success("Report saved to %s (%d bytes)", outFile, len(data))
 		return nil
@@ -160,6 +162,7 @@ var reportDeleteCmd = &cobra.Command{
 		if err := c.Delete(fmt.Sprintf("/api/reports/%s", args[0]), nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Report %s deleted", args[0])
 		return nil
 	},
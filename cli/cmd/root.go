@@ -2,16 +2,28 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
 
-var version = "6.0.0"
+// version, commit and date are set at build time with -ldflags -X.
+var (
+	version = "6.0.0"
+	commit  = "unknown"
+	date    = "unknown"
+)
 
 const defaultAddr = "http://127.0.0.1:8001"
 
@@ -26,14 +38,123 @@ REST API. It provides scan management, module listing, data export, schedule
 management, and health checks.
 
 Configure connection parameters via flags, environment variables, or a
-~/.spiderfoot.yaml config file.`,
+~/.spiderfoot.yaml config file (JSON and TOML are also accepted).`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyProfile(); err != nil {
+			return err
+		}
+		if viper.GetBool("no_color") {
+			color.NoColor = true
+		}
+		if err := output.CheckFormat(commandFormats(cmd)...); err != nil {
+			return err
+		}
+		if _, err := client.ParseResolve(viper.GetStringSlice("resolve")); err != nil {
+			return err
+		}
+		if _, err := client.ParseSize(viper.GetString("max_response_size")); err != nil {
+			return fmt.Errorf("invalid --max-response-size: %w", err)
+		}
+		if viper.GetInt("retries") < 0 || viper.GetDuration("retry_wait") < 0 {
+			return fmt.Errorf("--retries and --retry-wait must not be negative")
+		}
+		if file := viper.GetString("ca_cert"); file != "" {
+			if _, err := client.LoadCACerts(file, viper.GetBool("ca_cert_only")); err != nil {
+				return fmt.Errorf("invalid --ca-cert: %w", err)
+			}
+		} else if viper.GetBool("ca_cert_only") {
+			return fmt.Errorf("--ca-cert-only requires --ca-cert")
+		}
+		return output.CheckTableSettings()
+	},
+}
+
+// formatsAnnotation is the command annotation listing, comma-separated, the
+// output formats a command supports beyond the common ones.
+const formatsAnnotation = "output-formats"
+
+// commandFormats returns the extra output formats cmd supports.
+func commandFormats(cmd *cobra.Command) []output.Format {
+	var formats []output.Format
+	for _, f := range strings.Split(cmd.Annotations[formatsAnnotation], ",") {
+		if f != "" {
+			formats = append(formats, output.Format(f))
+		}
+	}
+	return formats
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// Errors are reported by output.PrintError so JSON mode can emit them as JSON.
+	rootCmd.SilenceErrors = true
+	silenceUsageOnInterrupt(rootCmd)
+	ctx, stop := signalContext()
+	client.BaseContext = ctx
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+	if n := client.RetryCount(); n > 0 {
+		output.Note("%d request retries during this command", n)
+	}
+	if err != nil {
+		code := exitCode(err)
+		if interrupted {
+			code = exitInterrupted
+			if errors.Is(err, context.Canceled) {
+				err = errors.New("interrupted")
+			}
+		}
+		output.PrintError(explainError(err), code, client.RequestIDOf(err))
+		os.Exit(code)
+	}
+	if interrupted {
+		os.Exit(exitInterrupted)
+	}
+}
+
+// Exit codes for common failure classes, so scripts can branch on them.
+const (
+	exitError        = 1
+	exitUnauthorized = 3
+	exitNotFound     = 4
+	exitRateLimited  = 5
+	exitServerError  = 6
+)
+
+// exitCode maps an error to the process exit code.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, client.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, client.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, client.ErrServer):
+		return exitServerError
+	}
+	return exitError
+}
+
+// explainError adds a remediation hint to common HTTP failures.
+func explainError(err error) error {
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		return fmt.Errorf("%w — check your API key or token", err)
+	case errors.Is(err, client.ErrRateLimited):
+		return fmt.Errorf("%w — the server is rate limiting requests, try again later", err)
+	case errors.Is(err, client.ErrServer):
+		return fmt.Errorf("%w — the server failed to handle the request", err)
 	}
+	return err
+}
+
+// notFound turns a 404 from the client into a "<what> <id> not found" error.
+func notFound(err error, what, id string) error {
+	if errors.Is(err, client.ErrNotFound) {
+		return fmt.Errorf("%s %s not found: %w", what, id, err)
+	}
+	return err
 }
 
 func init() {
@@ -41,41 +162,152 @@ func init() {
 
 	// Propagate build version to HTTP client User-Agent header.
 	client.Version = version
+	client.VersionMismatch = func(requested, served string) {
+		output.Warn("server is serving API version %s but this CLI requested %s; results may not match what it expects", served, requested)
+	}
+	client.RetryNotify = func(attempt, max, status int, wait time.Duration) {
+		cause := "a connection error"
+		if status != 0 {
+			cause = strconv.Itoa(status)
+		}
+		output.Note("retrying (%d/%d) after %s, waiting %s", attempt, max, cause, wait.Round(time.Millisecond))
+	}
+	client.ResumeNotify = func(attempt, max int, kept int64, ranged bool) {
+		if ranged {
+			output.Note("download interrupted; resuming from byte %d (%d/%d)", kept, attempt, max)
+		} else {
+			output.Note("download interrupted; starting over (%d/%d)", attempt, max)
+		}
+	}
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default $HOME/.spiderfoot.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file, YAML, JSON or TOML (default $HOME/.spiderfoot.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to use (from the profiles section of the config file)")
 	rootCmd.PersistentFlags().String("server", defaultAddr, "SpiderFoot API server URL")
 	rootCmd.PersistentFlags().String("api-key", "", "API key for authentication")
 	rootCmd.PersistentFlags().String("token", "", "JWT bearer token")
-	rootCmd.PersistentFlags().StringP("output", "o", "table", "Output format: table, json, csv")
+	rootCmd.PersistentFlags().StringP("output", "o", "auto", "Output format: auto, table, json, yaml, csv (auto = table on a terminal, JSON when piped)")
+	rootCmd.PersistentFlags().String("fields", "", "Comma-separated columns to show on list commands (JSON field names or table headers)")
+	rootCmd.PersistentFlags().Bool("flatten", false, "Flatten nested JSON into dotted columns for table/CSV output of generic responses")
+	rootCmd.PersistentFlags().Bool("wrap", false, "Wrap long table cells to the terminal width instead of truncating them")
+	rootCmd.PersistentFlags().Bool("full-width", false, "Don't narrow tables to fit the terminal; long cells run past its edge")
+	rootCmd.PersistentFlags().Int("max-col-width", 0, "Cap table columns at N characters, shortening or (with --wrap) wrapping longer cells (0 = no cap)")
+	rootCmd.PersistentFlags().Bool("no-headers", false, "Leave the header row out of tables")
+	rootCmd.PersistentFlags().String("align", output.AlignAuto, "Table column alignment: auto (numbers right), left or right")
+	rootCmd.PersistentFlags().Bool("compact", false, "Emit single-line JSON instead of indented JSON")
 	rootCmd.PersistentFlags().Bool("no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress spinners and progress indicators")
+	rootCmd.PersistentFlags().Bool("no-progress", false, "Disable spinners, progress bars and live redrawing (also off when not a terminal or CI is set)")
 	rootCmd.PersistentFlags().Bool("insecure", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().Bool("allow-insecure-auth", false, "Send the API key or token even over plain HTTP to a host other than localhost")
+	rootCmd.PersistentFlags().Bool("skip-hostname-verification", false, "Verify the TLS certificate chain but not that it matches the server hostname")
+	rootCmd.PersistentFlags().String("ca-cert", "", "PEM file of CA certificates to trust in addition to the system roots")
+	rootCmd.PersistentFlags().Bool("ca-cert-only", false, "Trust only the --ca-cert certificates, not the system roots")
+	rootCmd.PersistentFlags().StringArray("resolve", nil, "Connect to IP for host instead of using DNS (host:ip or host:port:ip, repeatable)")
+	rootCmd.PersistentFlags().Duration("timeout", 30*time.Second, "Timeout for each API request, including export downloads (0 = no timeout)")
+	rootCmd.PersistentFlags().String("max-response-size", client.DefaultMaxResponseSize, "Fail API responses larger than this (e.g. 512KB, 100MB; 0 = no limit); exports and streamed events are not limited")
+	rootCmd.PersistentFlags().Int("retries", 0, "Retry GET and DELETE requests that fail to connect or are answered with a 5xx up to N times")
+	rootCmd.PersistentFlags().Duration("retry-wait", client.DefaultRetryWait, "Wait before the first retry, doubled for each further one (with jitter, at most 1m); a Retry-After header takes precedence")
+	rootCmd.PersistentFlags().String("api-version", client.APIVersion, "API version to request from the server (empty to send none)")
+	rootCmd.PersistentFlags().Bool("audit-log", false, "Record mutating commands in the local audit log (see 'sf history')")
 
 	// Bind flags to viper keys
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	viper.BindPFlag("server", rootCmd.PersistentFlags().Lookup("server"))
 	viper.BindPFlag("api_key", rootCmd.PersistentFlags().Lookup("api-key"))
 	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
 	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	viper.BindPFlag("fields", rootCmd.PersistentFlags().Lookup("fields"))
+	viper.BindPFlag("flatten", rootCmd.PersistentFlags().Lookup("flatten"))
+	viper.BindPFlag("wrap", rootCmd.PersistentFlags().Lookup("wrap"))
+	viper.BindPFlag("full_width", rootCmd.PersistentFlags().Lookup("full-width"))
+	viper.BindPFlag("max_col_width", rootCmd.PersistentFlags().Lookup("max-col-width"))
+	viper.BindPFlag("no_headers", rootCmd.PersistentFlags().Lookup("no-headers"))
+	viper.BindPFlag("align", rootCmd.PersistentFlags().Lookup("align"))
+	viper.BindPFlag("compact", rootCmd.PersistentFlags().Lookup("compact"))
 	viper.BindPFlag("no_color", rootCmd.PersistentFlags().Lookup("no-color"))
+	viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+	viper.BindPFlag("no_progress", rootCmd.PersistentFlags().Lookup("no-progress"))
 	viper.BindPFlag("insecure", rootCmd.PersistentFlags().Lookup("insecure"))
+	viper.BindPFlag("allow_insecure_auth", rootCmd.PersistentFlags().Lookup("allow-insecure-auth"))
+	viper.BindPFlag("skip_hostname_verification", rootCmd.PersistentFlags().Lookup("skip-hostname-verification"))
+	viper.BindPFlag("ca_cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	viper.BindPFlag("ca_cert_only", rootCmd.PersistentFlags().Lookup("ca-cert-only"))
+	viper.BindPFlag("resolve", rootCmd.PersistentFlags().Lookup("resolve"))
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	viper.BindPFlag("max_response_size", rootCmd.PersistentFlags().Lookup("max-response-size"))
+	viper.BindPFlag("retries", rootCmd.PersistentFlags().Lookup("retries"))
+	viper.BindPFlag("retry_wait", rootCmd.PersistentFlags().Lookup("retry-wait"))
+	viper.BindPFlag("api_version", rootCmd.PersistentFlags().Lookup("api-version"))
+	viper.BindPFlag("audit_log", rootCmd.PersistentFlags().Lookup("audit-log"))
+
+	// Format used by "-o auto" when stdout is not a terminal.
+	viper.SetDefault("auto_output", "json")
 
 	// Environment variable bindings
 	viper.SetEnvPrefix("SF")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 }
 
 func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
+		viper.SetConfigType(detectConfigType(cfgFile))
 	} else {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return
 		}
+		// With no config type set, viper finds .spiderfoot.yaml, .json or
+		// .toml and parses it according to its extension.
 		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
 		viper.SetConfigName(".spiderfoot")
 	}
 
 	// Silently read config if it exists
 	_ = viper.ReadInConfig()
 }
+
+// applyProfile overlays the active profile's settings (profiles.<name>.*) on
+// the top-level config file values. Flags and SF_* environment variables still
+// take precedence over profile settings.
+func applyProfile() error {
+	name := viper.GetString("profile")
+	if name == "" {
+		return nil
+	}
+	settings := viper.GetStringMap("profiles." + name)
+	if len(settings) == 0 {
+		return fmt.Errorf("profile %q not found in config file", name)
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// configFilePath returns the config file that edits should be written to.
+func configFilePath() (string, error) {
+	if f := viper.ConfigFileUsed(); f != "" {
+		return f, nil
+	}
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return "", fmt.Errorf("no config file found — use --config flag or run 'sf config init'")
+}
+
+// loadConfigFile reads the config file into a fresh viper instance, so edits
+// can be written back without baking in flag, environment or profile values.
+func loadConfigFile() (*viper.Viper, string, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, "", err
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(detectConfigType(path))
+	if _, err := os.Stat(path); err == nil {
+		if err := v.ReadInConfig(); err != nil {
+			return nil, "", fmt.Errorf("reading config: %w", err)
+		}
+	}
+	return v, path, nil
+}
@@ -1,7 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
 
 // TestRootCommandHasSubcommands verifies the command tree includes all expected subcommands.
@@ -28,6 +49,11 @@ func TestRootCommandHasSubcommands(t *testing.T) {
 		"webhooks",
 		"monitor",
 		"tags",
+		"history",
+		"api",
+		"correlations",
+		"serve",
+		"schema",
 	}
 
 	cmds := rootCmd.Commands()
@@ -48,7 +74,8 @@ func TestScanSubcommands(t *testing.T) {
 	expected := []string{
 		"list", "get", "start", "stop", "delete", "events", "correlations",
 		"search", "summary", "logs", "profiles", "rerun", "clone",
-		"retry", "archive", "unarchive", "compare", "history",
+		"retry", "archive", "unarchive", "compare", "history", "merge",
+		"watch",
 	}
 
 	cmds := scanCmd.Commands()
@@ -66,7 +93,7 @@ func TestScanSubcommands(t *testing.T) {
 
 // TestScheduleSubcommands verifies schedule has the expected subcommands.
 func TestScheduleSubcommands(t *testing.T) {
-	expected := []string{"list", "create", "update", "delete", "trigger"}
+	expected := []string{"list", "create", "update", "delete", "trigger", "apply", "next"}
 
 	cmds := scheduleCmd.Commands()
 	cmdNames := make(map[string]bool, len(cmds))
@@ -117,7 +144,7 @@ func TestStealthSubcommands(t *testing.T) {
 
 // TestModulesSubcommands verifies modules command tree.
 func TestModulesSubcommands(t *testing.T) {
-	expected := []string{"list", "get", "stats", "categories", "types", "enable", "disable"}
+	expected := []string{"list", "get", "tree", "stats", "categories", "types", "enable", "disable"}
 
 	cmds := modulesCmd.Commands()
 	cmdNames := make(map[string]bool, len(cmds))
@@ -222,6 +249,94 @@ func TestFormatEpoch(t *testing.T) {
 	}
 }
 
+// newTestServer starts a server that answers with h and is closed when the
+// test ends, and returns it with a client for it.
+func newTestServer(t *testing.T, h http.HandlerFunc) (*httptest.Server, *client.Client) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv, &client.Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+}
+
+// TestExportFilename verifies scans whose IDs share a long prefix are
+// exported to separate files.
+func TestExportFilename(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+
+	dir := t.TempDir()
+	for _, id := range []string{"abcdef123456-first", "abcdef123456-second"} {
+		if _, _, err := exportScan(c, id, "json", "json", filepath.Join(dir, exportFilename(id, "json")), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"abcdef123456-first", "abcdef123456-second"} {
+		data, err := os.ReadFile(filepath.Join(dir, "spiderfoot_"+id+".json"))
+		if err != nil || !strings.HasPrefix(string(data), "/api/scans/"+id+"/export") {
+			t.Errorf("export of %s = %q, %v", id, data, err)
+		}
+	}
+}
+
+// TestResolveEventSources verifies parents missing from a filtered event list
+// are found in the scan's full list, the only events route the API has.
+func TestResolveEventSources(t *testing.T) {
+	var paths []string
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path != "/api/scans/s1/events" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"total": 2, "events": [
+			{"hash": "h1", "type": "INTERNET_NAME", "module": "sfp_dns", "data": "a.example.com", "source_event_hash": "ROOT"},
+			{"hash": "h2", "type": "IP_ADDRESS", "module": "sfp_dnsresolve", "data": "1.2.3.4", "source_event_hash": "h1"}]}`))
+	})
+
+	events := []map[string]interface{}{
+		{"hash": "h2", "type": "IP_ADDRESS", "module": "sfp_dnsresolve", "data": "1.2.3.4", "source_event_hash": "h1"},
+		{"hash": "h3", "type": "IP_ADDRESS", "data": "5.6.7.8", "source_event_hash": "gone"},
+	}
+	resolveEventSources(c, "s1", events)
+	if events[0]["source_module"] != "sfp_dns" || events[0]["source_data"] != "a.example.com" {
+		t.Errorf("resolved source = %v, %v", events[0]["source_module"], events[0]["source_data"])
+	}
+	if events[1]["source_module"] != "?" {
+		t.Errorf("unknown source = %v, want ?", events[1]["source_module"])
+	}
+	if fmt.Sprint(paths) != "[/api/scans/s1/events]" {
+		t.Errorf("requests = %v, want one full event list", paths)
+	}
+}
+
+// TestSilenceUsageOnInterrupt verifies an error returned by an interrupted
+// command is reported without the usage text, and other errors keep it.
+func TestSilenceUsageOnInterrupt(t *testing.T) {
+	for _, interrupt := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		sub := &cobra.Command{Use: "sub", RunE: func(cmd *cobra.Command, args []string) error {
+			if interrupt {
+				cancel()
+			}
+			return errors.New("boom")
+		}}
+		root := &cobra.Command{Use: "root", SilenceErrors: true}
+		root.AddCommand(sub)
+		root.SetArgs([]string{"sub"})
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		silenceUsageOnInterrupt(root)
+		if err := root.ExecuteContext(ctx); err == nil {
+			t.Fatal("ExecuteContext() = nil, want the command's error")
+		}
+		if sub.SilenceUsage != interrupt {
+			t.Errorf("interrupted=%t: SilenceUsage = %t", interrupt, sub.SilenceUsage)
+		}
+		cancel()
+	}
+}
+
 // TestTruncID verifies ID truncation.
 func TestTruncID(t *testing.T) {
 	if result := truncID("short"); result != "short" {
@@ -232,3 +347,2304 @@ func TestTruncID(t *testing.T) {
 		t.Errorf("truncID(%q) length = %d, want 12", long, len(result))
 	}
 }
+
+// TestDetectTargetType verifies target type detection.
+func TestDetectTargetType(t *testing.T) {
+	tests := map[string]string{
+		"example.com":         "domain",
+		"sub.example.co.uk":   "domain",
+		"192.168.1.1":         "ip",
+		"2001:db8::1":         "ip",
+		"10.0.0.0/8":          "netblock",
+		"user@example.com":    "email",
+		"https://example.com": "url",
+		"+1 555 0100":         "phone",
+		"\"John Smith\"":      "name",
+		"AS15169":             "asn",
+		"not a target":        "",
+	}
+	for target, want := range tests {
+		if got := detectTargetType(target); got != want {
+			t.Errorf("detectTargetType(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+// TestValidateTarget verifies the warnings raised for suspicious targets.
+func TestValidateTarget(t *testing.T) {
+	tests := []struct {
+		target   string
+		hint     string
+		warnings int
+	}{
+		{"example.com", "", 0},
+		{"example.com ", "", 1},
+		{"htttp://example.com", "", 1},
+		{"https://example.com", "url", 0},
+		{"example.com", "ip", 1},
+		{"exa mple", "", 1},
+	}
+	for _, tt := range tests {
+		if got := validateTarget(tt.target, tt.hint); len(got) != tt.warnings {
+			t.Errorf("validateTarget(%q, %q) = %v, want %d warnings", tt.target, tt.hint, got, tt.warnings)
+		}
+	}
+}
+
+// TestBuildModuleTree verifies data-flow expansion, depth limiting and repeats.
+func TestBuildModuleTree(t *testing.T) {
+	modules := []moduleInfo{
+		{Name: "sfp_dns", Consumes: []string{"DOMAIN_NAME"}, Provides: []string{"IP_ADDRESS", "DOMAIN_NAME"}},
+		{Name: "sfp_geo", Consumes: []string{"IP_ADDRESS"}, Provides: []string{"GEOINFO"}},
+		{Name: "sfp_store", Consumes: []string{"*"}},
+	}
+
+	tree := buildModuleTree(modules, "DOMAIN_NAME", 1)
+	if len(tree.Children) != 1 || tree.Children[0].Name != "sfp_dns" {
+		t.Fatalf("root children = %+v, want only sfp_dns", tree.Children)
+	}
+	provides := tree.Children[0].Children
+	if len(provides) != 2 || !provides[1].Repeated {
+		t.Errorf("DOMAIN_NAME should be marked repeated under sfp_dns: %+v", provides)
+	}
+	if len(provides[0].Children) != 0 {
+		t.Errorf("depth 1 should not expand IP_ADDRESS, got %+v", provides[0].Children)
+	}
+
+	tree = buildModuleTree(modules, "DOMAIN_NAME", 2)
+	if ip := tree.Children[0].Children[0]; len(ip.Children) != 1 || ip.Children[0].Name != "sfp_geo" {
+		t.Errorf("depth 2 should expand IP_ADDRESS to sfp_geo, got %+v", ip.Children)
+	}
+}
+
+// TestParseTimeBound verifies absolute and relative time parsing.
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-06-01T00:00:00Z", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeBound(tt.in, now)
+		if err != nil {
+			t.Errorf("parseTimeBound(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimeBound(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "yesterday", "xd", "-5h"} {
+		if _, err := parseTimeBound(bad, now); err == nil {
+			t.Errorf("parseTimeBound(%q) should fail", bad)
+		}
+	}
+}
+
+// TestExitCode verifies HTTP failures map to distinct exit codes.
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("plain failure"), exitError},
+		{&client.HTTPError{StatusCode: 401}, exitUnauthorized},
+		{&client.HTTPError{StatusCode: 403}, exitUnauthorized},
+		{notFound(&client.HTTPError{StatusCode: 404}, "scan", "abc"), exitNotFound},
+		{&client.HTTPError{StatusCode: 429}, exitRateLimited},
+		{&client.HTTPError{StatusCode: 503}, exitServerError},
+		{&client.HTTPError{StatusCode: 400}, exitError},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestParseScheduleFile verifies schedule definitions load from YAML and JSON.
+func TestParseScheduleFile(t *testing.T) {
+	yamlDoc := []byte(`
+schedules:
+  - name: daily
+    target: example.com
+    interval_hours: 24
+  - name: weekly
+    target: example.org
+    interval_hours: 168
+    enabled: false
+`)
+	defs, err := parseScheduleFile(yamlDoc)
+	if err != nil {
+		t.Fatalf("parseScheduleFile(yaml) error: %v", err)
+	}
+	if len(defs) != 2 || !defs[0].Enabled || defs[1].Enabled || defs[1].IntervalHours != 168 {
+		t.Errorf("parseScheduleFile(yaml) = %+v", defs)
+	}
+
+	jsonDoc := []byte(`[{"name": "daily", "target": "example.com", "interval_hours": 24}]`)
+	if defs, err := parseScheduleFile(jsonDoc); err != nil || len(defs) != 1 {
+		t.Errorf("parseScheduleFile(json) = %+v, %v", defs, err)
+	}
+
+	for _, bad := range []string{
+		"",
+		"name: daily",
+		"- name: daily\n  target: example.com",
+		"- {name: a, target: x.com, interval_hours: 1}\n- {name: a, target: y.com, interval_hours: 1}",
+	} {
+		if _, err := parseScheduleFile([]byte(bad)); err == nil {
+			t.Errorf("parseScheduleFile(%q) should fail", bad)
+		}
+	}
+
+	existing := schedule{Name: "daily", Target: "example.com", IntervalHours: 24, Enabled: true}
+	if changes := scheduleChanges(existing, defs[0]); len(changes) != 0 {
+		t.Errorf("scheduleChanges() for identical schedule = %v", changes)
+	}
+	changed := defs[0]
+	changed.IntervalHours = 12
+	if changes := scheduleChanges(existing, changed); len(changes) != 1 || changes["interval_hours"] != 12.0 {
+		t.Errorf("scheduleChanges() = %v, want interval_hours only", changes)
+	}
+}
+
+// TestSortModules verifies client-side module ordering.
+func TestSortModules(t *testing.T) {
+	modules := []moduleInfo{
+		{Name: "sfp_whois", Type: "passive", Categories: []string{"Search Engines"}},
+		{Name: "sfp_dns", Type: "passive", Categories: []string{"DNS"}},
+		{Name: "sfp_portscan", Type: "active"},
+	}
+	names := func() string {
+		var out []string
+		for _, m := range modules {
+			out = append(out, m.Name)
+		}
+		return fmt.Sprint(out)
+	}
+
+	tests := []struct {
+		by      string
+		reverse bool
+		want    string
+	}{
+		{"name", false, "[sfp_dns sfp_portscan sfp_whois]"},
+		{"name", true, "[sfp_whois sfp_portscan sfp_dns]"},
+		{"type", false, "[sfp_portscan sfp_dns sfp_whois]"},
+		{"category", false, "[sfp_portscan sfp_dns sfp_whois]"},
+	}
+	for _, tt := range tests {
+		if err := sortModules(modules, tt.by, tt.reverse); err != nil {
+			t.Fatalf("sortModules(%q) error: %v", tt.by, err)
+		}
+		if got := names(); got != tt.want {
+			t.Errorf("sortModules(%q, %v) = %s, want %s", tt.by, tt.reverse, got, tt.want)
+		}
+	}
+
+	if err := sortModules(modules, "risk", false); err == nil {
+		t.Error("sortModules(\"risk\") should fail")
+	}
+}
+
+// TestParseResolve verifies --resolve entries in host:ip and host:port:ip form.
+func TestParseResolve(t *testing.T) {
+	got, err := client.ParseResolve([]string{"sf.internal:10.0.0.5", "API.example.com:443:10.0.0.6", "v6.local:[::1]"})
+	if err != nil {
+		t.Fatalf("ParseResolve() error: %v", err)
+	}
+	want := map[string]string{
+		"sf.internal":         "10.0.0.5",
+		"api.example.com:443": "10.0.0.6",
+		"v6.local":            "::1",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("ParseResolve()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+
+	for _, bad := range []string{"sf.internal", ":10.0.0.5", "sf.internal:443:", "sf.internal:443:not-an-ip"} {
+		if _, err := client.ParseResolve([]string{bad}); err == nil {
+			t.Errorf("ParseResolve(%q) should fail", bad)
+		}
+	}
+}
+
+// TestLookupJSONPath verifies dotted-path extraction from decoded responses.
+func TestLookupJSONPath(t *testing.T) {
+	s := scanDetail{ScanID: "abc123", Status: "RUNNING", Progress: 42}
+	if v, err := lookupJSONPath(s, "status"); err != nil || v != "RUNNING" {
+		t.Errorf("lookupJSONPath(status) = %v, %v", v, err)
+	}
+	if v, err := lookupJSONPath(s, "progress"); err != nil || fmt.Sprint(v) != "42" {
+		t.Errorf("lookupJSONPath(progress) = %v, %v", v, err)
+	}
+
+	generic := map[string]interface{}{
+		"owner":   map[string]interface{}{"name": "alice"},
+		"modules": []interface{}{"sfp_dns", "sfp_whois"},
+	}
+	if v, err := lookupJSONPath(generic, "owner.name"); err != nil || v != "alice" {
+		t.Errorf("lookupJSONPath(owner.name) = %v, %v", v, err)
+	}
+	if v, err := lookupJSONPath(generic, "modules.1"); err != nil || v != "sfp_whois" {
+		t.Errorf("lookupJSONPath(modules.1) = %v, %v", v, err)
+	}
+
+	for _, bad := range []string{"missing", "owner.email", "modules.2", "modules.x", "owner.name.first"} {
+		if _, err := lookupJSONPath(generic, bad); err == nil {
+			t.Errorf("lookupJSONPath(%q) should fail", bad)
+		}
+	}
+}
+
+// TestEstimateScan verifies module selection and duration scaling.
+func TestEstimateScan(t *testing.T) {
+	available := []moduleInfo{
+		{Name: "sfp_dns", Type: "passive"},
+		{Name: "sfp_whois", Type: "passive"},
+		{Name: "sfp_portscan", Type: "active"},
+	}
+
+	all := estimateScan(available, "all", nil, 1)
+	if all.Modules != 3 || all.Active != 1 {
+		t.Errorf("estimateScan(all) = %+v, want 3 modules, 1 active", all)
+	}
+	if want := passiveModuleMinutes*2 + activeModuleMinutes; all.MinMinutes != want/2 || all.MaxMinutes != want*2 {
+		t.Errorf("estimateScan(all) range = %v-%v, want %v-%v", all.MinMinutes, all.MaxMinutes, want/2, want*2)
+	}
+
+	if passive := estimateScan(available, "passive", nil, 1); passive.Modules != 2 || passive.Active != 0 {
+		t.Errorf("estimateScan(passive) = %+v, want 2 passive modules", passive)
+	}
+
+	selected := estimateScan(available, "all", []string{"sfp_portscan", "sfp_unknown"}, 1)
+	if selected.Modules != 2 || selected.Active != 1 {
+		t.Errorf("estimateScan(selected) = %+v, want 2 modules, 1 active", selected)
+	}
+
+	if threaded := estimateScan(available, "all", nil, 2); threaded.MaxMinutes != all.MaxMinutes/2 {
+		t.Errorf("estimateScan(threads=2) max = %v, want %v", threaded.MaxMinutes, all.MaxMinutes/2)
+	}
+}
+
+// TestGuardProxy verifies the serve proxy refuses foreign Host headers and
+// cross-origin requests before they reach the upstream server.
+func TestGuardProxy(t *testing.T) {
+	var hits int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ })
+	h := guardProxy(next, allowedHosts("127.0.0.1:9000", nil), true)
+	tests := []struct {
+		host, origin string
+		want         int
+	}{
+		{"127.0.0.1:9000", "", http.StatusOK},
+		{"localhost:9000", "http://localhost:9000", http.StatusOK},
+		{"attacker.example:9000", "", http.StatusForbidden},
+		{"127.0.0.1:9001", "", http.StatusForbidden},
+		{"127.0.0.1:9000", "http://attacker.example", http.StatusForbidden},
+		{"127.0.0.1:9000", "null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/scans", nil)
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Host %q Origin %q: status %d, want %d", tt.host, tt.origin, rec.Code, tt.want)
+		}
+	}
+	if hits != 2 {
+		t.Errorf("upstream hits = %d, want 2", hits)
+	}
+
+	hosts := allowedHosts("0.0.0.0:9000", []string{"sf-proxy.lan", "192.168.1.20:9000"})
+	for host, want := range map[string]bool{
+		"sf-proxy.lan:9000": true,
+		"192.168.1.20:9000": true,
+		"localhost:9000":    true,
+		"sf-proxy.lan:9001": false,
+		"192.168.1.21:9000": false,
+	} {
+		if hosts[host] != want {
+			t.Errorf("allowedHosts() has %q = %t, want %t", host, hosts[host], want)
+		}
+	}
+}
+
+// TestIsLoopback verifies which serve listen addresses are considered local.
+func TestIsLoopback(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:9000": true,
+		"localhost:9000": true,
+		"[::1]:9000":     true,
+		"0.0.0.0:9000":   false,
+		":9000":          false,
+		"10.0.0.5:9000":  false,
+		"bogus":          false,
+	}
+	for addr, want := range tests {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+// TestScanDone verifies which scan statuses end --follow.
+func TestScanDone(t *testing.T) {
+	for _, s := range []string{"FINISHED", "aborted", "ERROR-FAILED"} {
+		if !scanDone(s) {
+			t.Errorf("scanDone(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"RUNNING", "STARTING", "ABORT-REQUESTED", ""} {
+		if scanDone(s) {
+			t.Errorf("scanDone(%q) = true, want false", s)
+		}
+	}
+}
+
+// TestRecentScan verifies duplicate detection for --skip-if-recent.
+func TestRecentScan(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) float64 { return float64(now.Add(-d).Unix()) }
+	scans := []scanSummary{
+		{ScanID: "old", Target: "example.com", Status: "FINISHED", StartedAt: ago(50 * time.Hour), EndedAt: ago(49 * time.Hour)},
+		{ScanID: "new", Target: "example.com", Status: "FINISHED", StartedAt: ago(3 * time.Hour), EndedAt: ago(2 * time.Hour)},
+		{ScanID: "running", Target: "example.com", Status: "RUNNING", StartedAt: ago(time.Hour)},
+		{ScanID: "other", Target: "example.org", Status: "FINISHED", StartedAt: ago(time.Hour), EndedAt: ago(time.Minute)},
+	}
+
+	if got := recentScan(scans, "Example.com", now.Add(-24*time.Hour)); got == nil || got.ScanID != "new" {
+		t.Errorf("recentScan(24h) = %v, want new", got)
+	}
+	if got := recentScan(scans, "example.com", now.Add(-72*time.Hour)); got == nil || got.ScanID != "new" {
+		t.Errorf("recentScan(72h) = %v, want most recent (new)", got)
+	}
+	if got := recentScan(scans, "example.com", now.Add(-time.Hour)); got != nil {
+		t.Errorf("recentScan(1h) = %v, want nil", got)
+	}
+}
+
+// TestFlatten verifies nested JSON is flattened into dotted columns.
+func TestFlatten(t *testing.T) {
+	v := map[string]interface{}{
+		"id":     "abc",
+		"config": map[string]interface{}{"max_threads": 5.0},
+		"tags":   []interface{}{"a", "b"},
+		"hosts":  []interface{}{map[string]interface{}{"name": "x"}},
+	}
+	header, rows := output.Flatten(v)
+	if got := fmt.Sprint(header); got != "[config.max_threads hosts.0.name id tags]" {
+		t.Errorf("Flatten() header = %s", got)
+	}
+	if len(rows) != 1 || fmt.Sprint(rows[0]) != "[5 x abc a; b]" {
+		t.Errorf("Flatten() rows = %v", rows)
+	}
+
+	envelope := map[string]interface{}{
+		"total": 2.0,
+		"items": []interface{}{
+			map[string]interface{}{"name": "one"},
+			map[string]interface{}{"name": "two", "extra": true},
+		},
+	}
+	header, rows = output.Flatten(envelope)
+	if fmt.Sprint(header) != "[extra name]" || len(rows) != 2 || fmt.Sprint(rows[0]) != "[ one]" {
+		t.Errorf("Flatten(envelope) = %v, %v", header, rows)
+	}
+}
+
+// TestFieldColumns verifies --fields accepts JSON names and table headers.
+func TestFieldColumns(t *testing.T) {
+	defer viper.Set("fields", "")
+
+	viper.Set("fields", "")
+	if cols, err := fieldColumns("scan_list", scanListHeader, scanListKeys); err != nil || cols != nil {
+		t.Errorf("fieldColumns(unset) = %v, %v, want nil", cols, err)
+	}
+
+	viper.Set("fields", "scan_id, Status,STARTED")
+	cols, err := fieldColumns("scan_list", scanListHeader, scanListKeys)
+	if err != nil || fmt.Sprint(cols) != "[0 3 4]" {
+		t.Fatalf("fieldColumns() = %v, %v, want [0 3 4]", cols, err)
+	}
+	header, rows := selectColumns(scanListHeader, [][]string{{"abc", "n", "t", "RUNNING", "now"}}, cols)
+	if fmt.Sprint(header) != "[ID Status Started]" || fmt.Sprint(rows) != "[[abc RUNNING now]]" {
+		t.Errorf("selectColumns() = %v, %v", header, rows)
+	}
+	picked := selectJSONFields([]scanSummary{{ScanID: "abc", Name: "n", Status: "RUNNING"}}, scanListKeys, cols)
+	if got := fmt.Sprint(picked); got != "[map[scan_id:abc started:0 status:RUNNING]]" {
+		t.Errorf("selectJSONFields() = %s", got)
+	}
+
+	viper.Set("fields", "bogus")
+	if _, err := fieldColumns("scan_list", scanListHeader, scanListKeys); err == nil {
+		t.Error("fieldColumns(bogus) should fail")
+	}
+}
+
+// TestConfigColumns verifies columns.<command> applies only without --fields.
+func TestConfigColumns(t *testing.T) {
+	defer viper.Set("fields", "")
+	defer viper.Set("columns.scan_list", nil)
+
+	viper.Set("fields", "")
+	viper.Set("columns.scan_list", []string{"id", "target", "status", "started", "event_count"})
+	cols, err := fieldColumns("scan_list", scanListHeader, scanListKeys)
+	if err != nil || fmt.Sprint(cols) != "[0 2 3 4 5]" || !hasColumn(cols, scanListEventCount) {
+		t.Fatalf("fieldColumns(config) = %v, %v, want [0 2 3 4 5]", cols, err)
+	}
+
+	viper.Set("fields", "name")
+	if cols, err := fieldColumns("scan_list", scanListHeader, scanListKeys); err != nil || fmt.Sprint(cols) != "[1]" {
+		t.Errorf("fieldColumns(--fields over config) = %v, %v, want [1]", cols, err)
+	}
+
+	viper.Set("fields", "")
+	viper.Set("columns.scan_list", []string{"bogus"})
+	if _, err := fieldColumns("scan_list", scanListHeader, scanListKeys); err == nil || !strings.Contains(err.Error(), "columns.scan_list") {
+		t.Errorf("fieldColumns(bad config) error = %v, want it to name columns.scan_list", err)
+	}
+}
+
+// TestMergeEvents verifies events are de-duplicated by type and data across scans.
+func TestMergeEvents(t *testing.T) {
+	events := map[string][]map[string]interface{}{
+		"a": {
+			{"type": "IP_ADDRESS", "data": "1.2.3.4", "module": "sfp_dns", "generated": 100.0},
+			{"type": "INTERNET_NAME", "data": "www.example.com", "module": "sfp_dns", "generated": 110.0},
+		},
+		"b": {
+			{"type": "IP_ADDRESS", "data": "1.2.3.4", "module": "sfp_shodan", "generated": 200.0},
+			{"type": "IP_ADDRESS", "data": "1.2.3.4", "module": "sfp_dns", "generated": 210.0},
+		},
+	}
+	merged := mergeEvents([]string{"a", "b"}, events)
+	if len(merged) != 2 {
+		t.Fatalf("mergeEvents() = %d events, want 2", len(merged))
+	}
+
+	ip := merged[1]
+	if ip.Type != "IP_ADDRESS" || fmt.Sprint(ip.Scans) != "[a b]" || fmt.Sprint(ip.Modules) != "[sfp_dns sfp_shodan]" {
+		t.Errorf("merged IP event = %+v", ip)
+	}
+	if ip.FirstSeen != 100 || ip.LastSeen != 210 {
+		t.Errorf("merged IP event seen %v-%v, want 100-210", ip.FirstSeen, ip.LastSeen)
+	}
+	if name := merged[0]; fmt.Sprint(name.Scans) != "[a]" {
+		t.Errorf("merged name event scans = %v, want [a]", name.Scans)
+	}
+}
+
+func TestRenderRowTemplate(t *testing.T) {
+	scans := []scanSummary{
+		{ScanID: "abc", Target: "example.com", Status: "FINISHED"},
+		{ScanID: "def", Target: "example.org", Status: "running"},
+	}
+	got, err := renderRowTemplate(`{{upper .Status}}\t{{.Target}}`, scans)
+	if err != nil {
+		t.Fatalf("renderRowTemplate() error = %v", err)
+	}
+	if want := "FINISHED\texample.com\nRUNNING\texample.org\n"; got != want {
+		t.Errorf("renderRowTemplate() = %q, want %q", got, want)
+	}
+
+	if _, err := renderRowTemplate("{{.Missing}}", scans); err == nil {
+		t.Error("renderRowTemplate() with unknown field: expected error")
+	}
+	if _, err := renderRowTemplate("{{.Status", scans); err == nil {
+		t.Error("renderRowTemplate() with bad syntax: expected error")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	s, err := schemaFor("schedule")
+	if err != nil {
+		t.Fatalf("schemaFor() error = %v", err)
+	}
+	props := s["properties"].(map[string]interface{})
+	if got := fmt.Sprint(props["interval_hours"]); got != "map[type:number]" {
+		t.Errorf("interval_hours schema = %s", got)
+	}
+	if got := fmt.Sprint(props["last_run_at"]); got != "map[type:[number null]]" {
+		t.Errorf("last_run_at schema = %s", got)
+	}
+	if got := fmt.Sprint(props["tags"]); got != "map[items:map[type:string] type:array]" {
+		t.Errorf("tags schema = %s", got)
+	}
+
+	h, _ := schemaFor("health")
+	if got := fmt.Sprint(h["required"]); got != "[status version uptime_seconds]" {
+		t.Errorf("health required = %s, want omitempty components excluded", got)
+	}
+
+	if _, err := schemaFor("nope"); err == nil {
+		t.Error("schemaFor(unknown) expected error")
+	}
+}
+
+func TestDisabledModules(t *testing.T) {
+	on, off := true, false
+	modules := []moduleInfo{
+		{Name: "sfp_dns", Enabled: &on},
+		{Name: "sfp_portscan", Enabled: &off},
+		{Name: "sfp_unknown"},
+	}
+	got := disabledModules(modules)
+	if len(got) != 1 || got[0].Name != "sfp_portscan" {
+		t.Errorf("disabledModules() = %+v, want only sfp_portscan", got)
+	}
+	for i, want := range []string{"enabled", "disabled", "-"} {
+		if s := moduleStatus(modules[i]); s != want {
+			t.Errorf("moduleStatus(%s) = %q, want %q", modules[i].Name, s, want)
+		}
+	}
+}
+
+func TestChunkedEvents(t *testing.T) {
+	body := `{"total": 3, "events": [{"type": "A", "data": "1"}, {"type": "B", "data": "2"}, {"type": "C", "data": "3"}]}`
+	w := &chunkWriter{base: filepath.Join(t.TempDir(), "scan"), format: "json", perFile: 2}
+	if err := decodeEventStream(strings.NewReader(body), w.add); err != nil {
+		t.Fatalf("decodeEventStream() error = %v", err)
+	}
+	if err := w.closeChunk(); err != nil {
+		t.Fatalf("closeChunk() error = %v", err)
+	}
+	if len(w.chunks) != 2 || w.chunks[0].Events != 2 || w.chunks[1].Events != 1 {
+		t.Fatalf("chunks = %+v, want 2 then 1 events", w.chunks)
+	}
+	data, err := os.ReadFile(w.base + ".part002.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[\n  {\"data\":\"3\",\"type\":\"C\"}\n]\n"; string(data) != want {
+		t.Errorf("part002 = %q, want %q", data, want)
+	}
+
+	n := 0
+	err = decodeEventStream(strings.NewReader(`[{"type": "A"}, {"type": "B"}]`), func(map[string]interface{}) error {
+		n++
+		return errStopEvents
+	})
+	if err != nil || n != 1 {
+		t.Errorf("decodeEventStream() with stop = %d events, %v; want 1, nil", n, err)
+	}
+	if err := decodeEventStream(strings.NewReader(`{"total": 0}`), w.add); err == nil {
+		t.Error("decodeEventStream() without events array: expected error")
+	}
+}
+
+func TestDetectConfigType(t *testing.T) {
+	for path, want := range map[string]string{
+		"config.yml": "yaml", "config.YAML": "yaml", "config.json": "json", "config.toml": "toml", "missing": "yaml",
+	} {
+		if got := detectConfigType(path); got != want {
+			t.Errorf("detectConfigType(%q) = %q, want %q", path, got, want)
+		}
+	}
+	for path, want := range map[string]string{"sf.yaml": "yaml", "sf.TOML": "toml", "sf.conf": "", "": ""} {
+		if got := configExtType(path); got != want {
+			t.Errorf("configExtType(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	for content, want := range map[string]string{
+		`{"server": "http://x"}`:                 "json",
+		"# comment\nserver = \"http://x\"":       "toml",
+		"[profiles.prod]\nserver = \"http://x\"": "toml",
+		"server: http://x?a=b\nprofile: dev":     "yaml",
+		"":                                       "yaml",
+	} {
+		if got := sniffConfigType([]byte(content)); got != want {
+			t.Errorf("sniffConfigType(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestExtractIOCs(t *testing.T) {
+	events := []map[string]interface{}{
+		{"type": "IP_ADDRESS", "data": "1.2.3.4"},
+		{"type": "IP_ADDRESS", "data": "1.2.3.4"},
+		{"type": "EMAILADDR", "data": "bob@example.com"},
+		{"type": "LINKED_URL_EXTERNAL", "data": "https://evil.example.com/a.b?x=1"},
+		{"type": "IPV6_ADDRESS", "data": "2001:db8::1"},
+		{"type": "RAW_RIR_DATA", "data": "ignored"},
+	}
+	var got []string
+	for _, i := range extractIOCs(events, true) {
+		got = append(got, i.Kind+" "+i.Indicator)
+	}
+	want := "[email bob[@]example[.]com ip 1[.]2[.]3[.]4 ip 2001[:]db8[:][:]1 url hxxps://evil[.]example[.]com/a.b?x=1]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("extractIOCs() = %v, want %v", got, want)
+	}
+
+	if plain := extractIOCs(events[:1], false); plain[0].Indicator != "1.2.3.4" {
+		t.Errorf("extractIOCs(defang=false) = %q, want 1.2.3.4", plain[0].Indicator)
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	hits := 0
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		fmt.Fprintf(w, `{"hits": %d}`, hits)
+	})
+
+	cache := &client.Cache{Dir: t.TempDir(), TTL: time.Hour}
+	c.Cache = cache
+	var resp struct{ Hits int }
+	for i := 0; i < 2; i++ {
+		if err := c.Get("/api/scans", &resp); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if hits != 1 || resp.Hits != 1 {
+		t.Errorf("second Get() within TTL: server hits = %d, response = %d; want 1, 1", hits, resp.Hits)
+	}
+
+	cache.Refresh = true
+	if err := c.Get("/api/scans", &resp); err != nil || resp.Hits != 2 {
+		t.Errorf("Get() with Refresh = %d, %v; want fresh response 2", resp.Hits, err)
+	}
+
+	cache.Refresh, cache.Offline, cache.TTL = false, true, 0
+	if err := c.Get("/api/scans", &resp); err != nil || resp.Hits != 2 || hits != 2 {
+		t.Errorf("offline Get() = %d, %v (server hits %d); want cached 2 without a request", resp.Hits, err, hits)
+	}
+	if err := c.Get("/api/modules", &resp); !errors.Is(err, client.ErrNotCached) {
+		t.Errorf("offline Get() of uncached path error = %v, want ErrNotCached", err)
+	}
+}
+
+func TestParseStdinScan(t *testing.T) {
+	req, err := parseStdinScan(scanStartCmd, `{"target": " example.com ", "modules": ["sfp_dns"]}`)
+	if err != nil {
+		t.Fatalf("parseStdinScan() error = %v", err)
+	}
+	if req.Target != "example.com" || req.ScanName != "CLI scan: example.com" || req.ScanType != "all" || fmt.Sprint(req.Modules) != "[sfp_dns]" {
+		t.Errorf("parseStdinScan() = %+v", req)
+	}
+
+	scanStartCmd.Flags().Set("tags", " Acme-Engagement,q3, acme-engagement,")
+	defer scanStartCmd.Flags().Set("tags", "")
+	req, err = parseStdinScan(scanStartCmd, `{"target": "example.com"}`)
+	if err != nil || fmt.Sprint(req.Tags) != "[acme-engagement q3]" {
+		t.Errorf("parseStdinScan() with --tags = %v, %v, want [acme-engagement q3]", req.Tags, err)
+	}
+	if req, _ = parseStdinScan(scanStartCmd, `{"target": "example.com", "tags": ["own"]}`); fmt.Sprint(req.Tags) != "[own]" {
+		t.Errorf("parseStdinScan() tags = %v, want the line's own tags", req.Tags)
+	}
+
+	for _, bad := range []string{`{"target": ""}`, `{"targt": "example.com"}`, `not json`} {
+		if _, err := parseStdinScan(scanStartCmd, bad); err == nil {
+			t.Errorf("parseStdinScan(%s) expected error", bad)
+		}
+	}
+}
+
+func TestUniqueEvents(t *testing.T) {
+	events := []map[string]interface{}{
+		{"type": "IP_ADDRESS", "data": "1.2.3.4"},
+		{"type": "INTERNET_NAME", "data": "www.example.com"},
+		{"type": "IP_ADDRESS", "data": "1.2.3.4"},
+		{"type": "IP_ADDRESS", "data": "5.6.7.8"},
+		{"type": "INTERNET_NAME", "data": "1.2.3.4"},
+		{"type": "IP_ADDRESS", "data": "1.2.3.4"},
+	}
+	want := "[{IP_ADDRESS 1.2.3.4 3} {INTERNET_NAME 1.2.3.4 1} {INTERNET_NAME www.example.com 1} {IP_ADDRESS 5.6.7.8 1}]"
+	if got := fmt.Sprint(uniqueEvents(events)); got != want {
+		t.Errorf("uniqueEvents() = %s, want %s", got, want)
+	}
+}
+
+// TestTruncateCellWrap verifies cells are shortened by rune with an ellipsis,
+// and left whole with --wrap.
+func TestTruncateCellWrap(t *testing.T) {
+	long := "DNS resolver that looks up A, AAAA, MX, NS and TXT records"
+	if got := truncateCell(long, 20); got != "DNS resolver that l…" {
+		t.Errorf("truncateCell() = %q", got)
+	}
+	if got := truncateCell("résumé.pdf", 5); got != "résu…" {
+		t.Errorf("truncateCell() = %q, want whole runes", got)
+	}
+	viper.Set("wrap", true)
+	defer viper.Set("wrap", false)
+	if got := truncateCell(long, 20); got != long {
+		t.Errorf("truncateCell() with --wrap = %q, want full cell", got)
+	}
+}
+
+func TestTableSettings(t *testing.T) {
+	viper.Set("max_col_width", 8)
+	viper.Set("no_headers", true)
+	viper.Set("align", "left")
+	defer func() {
+		viper.Set("max_col_width", 0)
+		viper.Set("no_headers", false)
+		viper.Set("align", "")
+	}()
+	got := captureStdout(t, func() error {
+		output.PrintTable([]string{"Name", "Count"}, [][]string{{"sfp_dnsresolve", "12"}, {"sfp_a", "3"}})
+		return nil
+	})
+	want := "  sfp_dns…  12   \n  sfp_a     3    \n"
+	if got != want {
+		t.Errorf("PrintTable() = %q, want %q", got, want)
+	}
+
+	viper.Set("align", "centre")
+	if err := output.CheckTableSettings(); err == nil {
+		t.Error("CheckTableSettings() accepted align: centre")
+	}
+}
+
+func TestTableFooter(t *testing.T) {
+	viper.Set("no_color", true)
+	defer viper.Set("no_color", false)
+	modules := []moduleInfo{{Name: "sfp_a", Type: "passive"}, {Name: "sfp_b", Type: "active"}, {Name: "sfp_c", Type: "passive"}}
+	footer := moduleTypeTotals(modules)
+	if len(footer) != 3 || footer[0][0] != "1 module" || footer[1][1] != "passive" || footer[2][0] != "3 modules" {
+		t.Fatalf("moduleTypeTotals() = %v", footer)
+	}
+	if got := countLabel(1, "scan"); got != "1 scan" {
+		t.Errorf("countLabel(1) = %q, want 1 scan", got)
+	}
+
+	got := captureStdout(t, func() error {
+		output.PrintTableFooter([]string{"ID", "Events"}, [][]string{{"a", "2"}, {"b", "10"}}, [][]string{totalsLabel([]string{"", "12"}, "2 scans")})
+		return nil
+	})
+	want := "  ID       Events\n  ───────  ──────\n  a             2\n  b            10\n  ───────  ──────\n  2 scans      12\n"
+	if got != want {
+		t.Errorf("PrintTableFooter() = %q, want %q", got, want)
+	}
+}
+
+func TestScanElapsed(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	start := float64(now.Unix() - 754)
+	if label, d, ok := scanElapsed(scanDetail{Status: "RUNNING", StartedAt: start}, now); !ok || label != "Running for" || humanDuration(d) != "12m34s" {
+		t.Errorf("running scan = %q %v %v", label, d, ok)
+	}
+	if label, d, ok := scanElapsed(scanDetail{Status: "FINISHED", StartedAt: start, EndedAt: start + 2*86400 + 4*3600 + 59}, now); !ok || label != "Duration" || humanDuration(d) != "2d4h" {
+		t.Errorf("finished scan = %q %v %v", label, d, ok)
+	}
+	if _, _, ok := scanElapsed(scanDetail{Status: "FINISHED", StartedAt: start}, now); ok {
+		t.Error("finished scan without an end time reported a duration")
+	}
+	for d, want := range map[time.Duration]string{45 * time.Second: "45s", 3*time.Hour + 5*time.Minute: "3h05m", 1500 * time.Millisecond: "2s"} {
+		if got := humanDuration(d); got != want {
+			t.Errorf("humanDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestModuleCatalog(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/data/modules":
+			w.Write([]byte(`[{"name": "sfp_b", "type": "passive"}, {"name": "sfp_a", "type": "active"}]`))
+		case "/api/data/modules/sfp_a":
+			w.Write([]byte(`{"module": {"name": "sfp_a", "provides": ["IP_ADDRESS", "DOMAIN_NAME"], "opts": {"timeout": 30}}}`))
+		case "/api/data/modules/sfp_a/options":
+			w.Write([]byte(`{"options": {"timeout": "Query timeout"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	catalog, failed, err := fetchModuleCatalog(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog) != 2 || catalog[0]["name"] != "sfp_a" || catalog[0]["type"] != "active" || catalog[0]["options"] == nil {
+		t.Errorf("catalog = %v", catalog)
+	}
+	if len(failed) != 1 || failed[0] != "sfp_b" {
+		t.Errorf("failed = %v, want [sfp_b]", failed)
+	}
+
+	data, err := encodeModuleCatalog(catalog, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `sfp_a,active,,,IP_ADDRESS;DOMAIN_NAME,,,"{""timeout"":""Query timeout""}"`) {
+		t.Errorf("CSV = %s", data)
+	}
+	if f, err := catalogFormat("", "modules.yml"); err != nil || f != "yaml" {
+		t.Errorf("catalogFormat(modules.yml) = %q, %v", f, err)
+	}
+	if _, err := catalogFormat("xml", ""); err == nil {
+		t.Error("catalogFormat(xml) accepted")
+	}
+}
+
+func TestScanSync(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	scans := []scanSummary{
+		{ScanID: "old", StartedAt: 100, EndedAt: 200},
+		{ScanID: "finished", StartedAt: 100, EndedAt: 400},
+		{ScanID: "running", StartedAt: 350},
+	}
+	changed, mark := scansChangedAfter(scans, 300)
+	if len(changed) != 2 || changed[0].ScanID != "finished" || changed[1].ScanID != "running" || mark != 400 {
+		t.Errorf("scansChangedAfter() = %v, %v", changed, mark)
+	}
+	if changed, mark := scansChangedAfter(scans, 400); len(changed) != 0 || mark != 400 {
+		t.Errorf("scansChangedAfter(400) = %v, %v", changed, mark)
+	}
+
+	if err := saveSyncWatermark("http://a", 400); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSyncWatermark("http://b", 7); err != nil {
+		t.Fatal(err)
+	}
+	marks, err := loadSyncWatermarks()
+	if err != nil || marks["http://a"] != 400 || marks["http://b"] != 7 {
+		t.Errorf("loadSyncWatermarks() = %v, %v", marks, err)
+	}
+}
+
+// TestCheckFormat verifies unknown output formats are rejected with a
+// suggestion, and geojson is accepted only by the command that produces it.
+func TestCheckFormat(t *testing.T) {
+	defer viper.Set("output", "")
+	for value, want := range map[string]string{
+		"JSON":    "",
+		"geojson": `invalid output "geojson"`,
+		"jsn":     `invalid output "jsn": did you mean "json"?`,
+		"tabel":   `did you mean "table"?`,
+		"yml":     "",
+		"xml":     `invalid output "xml": did you mean "yaml"? (valid: auto, table, json, yaml, csv)`,
+	} {
+		viper.Set("output", value)
+		err := output.CheckFormat()
+		if want == "" && err != nil || want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Errorf("CheckFormat(%q) = %v, want %q", value, err, want)
+		}
+	}
+
+	viper.Set("output", "geojson")
+	if err := output.CheckFormat(commandFormats(scanEventsCmd)...); err != nil {
+		t.Errorf("CheckFormat() for scan events rejected geojson: %v", err)
+	}
+	if err := output.CheckFormat(commandFormats(modulesTreeCmd)...); err == nil {
+		t.Error("CheckFormat() for modules tree accepted geojson")
+	}
+}
+
+// TestPrintYAML verifies YAML output keeps the JSON field names and order,
+// writes multi-line strings as block scalars and quotes strings that would
+// otherwise read as numbers.
+func TestPrintYAML(t *testing.T) {
+	defer viper.Set("output", "")
+	viper.Set("output", "yml")
+	if output.Current() != output.YAML {
+		t.Fatalf("Current() = %q, want yaml", output.Current())
+	}
+	got := captureStdout(t, func() error {
+		output.PrintStructured(scanDetail{ScanID: "123", Name: "line one\nline two", Status: "FINISHED"})
+		return nil
+	})
+	for _, want := range []string{"scan_id: \"123\"\nname: |-\n  line one\n  line two\n", "status: FINISHED\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("PrintYAML() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestFetchScanUsage(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/scans/s1/stats" {
+			w.Write([]byte(`{"scan_id": "s1", "stats": {"cpu_percent": 87.5, "memory_mb": 512, "request_count": 1200}}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	usage := []scanUsage{{ScanID: "s1"}, {ScanID: "s2"}}
+	available, err := fetchScanUsage(c, usage)
+	if err != nil || !available {
+		t.Fatalf("fetchScanUsage() = %v, %v", available, err)
+	}
+	if got := usageCell(usage[0].Stats, usageCPUKeys) + " " + usageCell(usage[0].Stats, usageMemoryKeys) + " " + usageCell(usage[0].Stats, usageRequestsKeys); got != "87.5 512 1200" {
+		t.Errorf("s1 usage = %q", got)
+	}
+	if usage[1].Stats != nil || usageCell(usage[1].Stats, usageCPUKeys) != "-" {
+		t.Errorf("s2 stats = %v", usage[1].Stats)
+	}
+
+	if available, err := fetchScanUsage(c, []scanUsage{{ScanID: "s2"}}); err != nil || available {
+		t.Errorf("fetchScanUsage() without a stats endpoint = %v, %v", available, err)
+	}
+}
+
+func TestFailureReasons(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/scans/s1":
+			w.Write([]byte(`{"scan_id": "s1", "status": "ERROR-FAILED", "error": "module sfp_dns crashed"}`))
+		case "/api/scans/s2":
+			w.Write([]byte(`{"scan_id": "s2", "status": "ABORTED"}`))
+		case "/api/scans/s2/logs":
+			w.Write([]byte(`{"logs": [{"type": "ERROR", "message": "timed out"}, {"type": "INFO", "message": "stopping"}]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	scans := []scanSummary{
+		{ScanID: "s1", Status: "ERROR-FAILED"},
+		{ScanID: "s2", Status: "ABORTED"},
+		{ScanID: "s3", Status: "FINISHED"},
+	}
+	if got := filterScansByStatus(scans, []string{"failed"}); len(got) != 2 {
+		t.Errorf("filterScansByStatus(failed) kept %d scans, want 2", len(got))
+	}
+	if got := filterScansByStatus(scans, []string{"finished"}); len(got) != 1 || got[0].ScanID != "s3" {
+		t.Errorf("filterScansByStatus(finished) = %v", got)
+	}
+
+	fillFailureReasons(c, scans)
+	for i, want := range []string{"module sfp_dns crashed", "timed out", ""} {
+		if scans[i].Reason != want {
+			t.Errorf("%s reason = %q, want %q", scans[i].ScanID, scans[i].Reason, want)
+		}
+	}
+}
+
+func TestScanListStatusQuery(t *testing.T) {
+	scanListCmd.Flags().Set("status", " running,Finished,running")
+	scanListCmd.Flags().Set("failed", "true")
+	defer scanListCmd.Flags().Set("status", "")
+	defer scanListCmd.Flags().Set("failed", "false")
+	statuses, err := scanStatuses(scanListCmd)
+	if err != nil || strings.Join(statuses, ",") != "RUNNING,FINISHED,FAILED" {
+		t.Fatalf("scanStatuses() = %v, %v", statuses, err)
+	}
+	if got := scanStatusQuery(statuses).Get("status"); got != "RUNNING,FINISHED,FAILED,ERROR,ERROR-FAILED,ABORTED" {
+		t.Errorf("status query = %q", got)
+	}
+
+	scanListCmd.Flags().Set("status", "running,done")
+	if _, err := scanStatuses(scanListCmd); err == nil || !strings.Contains(err.Error(), `"done"`) ||
+		!strings.Contains(err.Error(), "RUNNING, FINISHED, FAILED, ABORTED") {
+		t.Errorf("scanStatuses(done) error = %v, want one listing the allowed values", err)
+	}
+
+	var query string
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("status")
+		w.Write([]byte(`{"scans": [{"scan_id": "s1", "status": "RUNNING"}]}`))
+	})
+	if _, err := fetchScanListQuery(c, scanStatusQuery([]string{"RUNNING"}), time.Time{}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if query != "RUNNING" {
+		t.Errorf("server got status=%q, want RUNNING", query)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.json")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("interrupted")
+	err := writeFileAtomic(path, func(f *os.File) error {
+		f.WriteString("partial")
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("writeFileAtomic() error = %v, want %v", err, failed)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("failed write left %q, want the old file", data)
+	}
+
+	if err := writeBytesAtomic(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("file = %q, want %q", data, "new")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+// TestChunkInterruptCleanup verifies an interrupt removes the chunks of an
+// export whose manifest was never written, temporary file included, and
+// leaves them alone once unregistered.
+func TestChunkInterruptCleanup(t *testing.T) {
+	dir := t.TempDir()
+	w := &chunkWriter{base: filepath.Join(dir, "scan"), format: "json", perFile: 1}
+	for _, typ := range []string{"A", "B"} {
+		if err := w.add(map[string]interface{}{"type": typ}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runInterruptCleanups()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("interrupt left %v", entries)
+	}
+	w.abort()
+
+	w = &chunkWriter{base: filepath.Join(dir, "scan"), format: "json", perFile: 1}
+	if err := w.add(map[string]interface{}{"type": "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.closeChunk(); err != nil {
+		t.Fatal(err)
+	}
+	w.unregister()
+	runInterruptCleanups()
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("interrupt after unregister left %v, want the chunk", entries)
+	}
+}
+
+// TestScanFromBase verifies --from-scan picks the latest scan with exactly that
+// name, and that new options are laid over its config without modifying it.
+func TestScanFromBase(t *testing.T) {
+	scans := []scanSummary{
+		{ScanID: "a", Name: "weekly", StartedAt: 100},
+		{ScanID: "b", Name: "weekly", StartedAt: 300},
+		{ScanID: "c", Name: "Weekly", StartedAt: 400},
+		{ScanID: "d", Name: "other", StartedAt: 500},
+	}
+	if got := latestScanNamed(scans, "weekly"); got == nil || got.ScanID != "b" {
+		t.Errorf("latestScanNamed(weekly) = %v, want scan b", got)
+	}
+	if got := latestScanNamed(scans, "monthly"); got != nil {
+		t.Errorf("latestScanNamed(monthly) = %v, want nil", got)
+	}
+
+	base := map[string]interface{}{"_maxthreads": 3.0, "max_threads": 2.0}
+	got := mergeScanConfig(base, map[string]interface{}{"max_threads": 9})
+	if fmt.Sprint(got) != "map[_maxthreads:3 max_threads:9]" {
+		t.Errorf("mergeScanConfig() = %v", got)
+	}
+	if base["max_threads"] != 2.0 {
+		t.Errorf("mergeScanConfig() modified its base: %v", base)
+	}
+	if got := mergeScanConfig(nil, nil); got != nil {
+		t.Errorf("mergeScanConfig(nil, nil) = %v, want nil", got)
+	}
+}
+
+// TestErrorRequestID verifies a server's X-Request-ID is kept on errors and
+// shown in their message.
+func TestErrorRequestID(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/scans/traced" {
+			w.Header().Set("X-Request-ID", "req-42")
+		}
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	err := notFound(c.Get("/api/scans/traced", nil), "scan", "traced")
+	if got := client.RequestIDOf(err); got != "req-42" {
+		t.Errorf("RequestIDOf() = %q, want req-42", got)
+	}
+	if !strings.Contains(err.Error(), "(request ID req-42)") {
+		t.Errorf("error %q does not mention the request ID", err)
+	}
+
+	err = c.Get("/api/scans/untraced", nil)
+	if got := client.RequestIDOf(err); got != "" || strings.Contains(err.Error(), "request ID") {
+		t.Errorf("without the header: RequestIDOf() = %q, error %q", got, err)
+	}
+	if got := client.RequestIDOf(errors.New("offline")); got != "" {
+		t.Errorf("RequestIDOf(non-HTTP error) = %q", got)
+	}
+}
+
+// TestUpcomingSchedules verifies upcoming schedules leave out disabled and
+// used-up ones, fall back to the interval when the server gives no next run,
+// and come soonest first.
+func TestUpcomingSchedules(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	at := func(offset time.Duration) *float64 {
+		v := float64(now.Add(offset).Unix())
+		return &v
+	}
+	schedules := []schedule{
+		{ID: "later", Enabled: true, NextRunAt: at(30 * time.Hour)},
+		{ID: "soon", Enabled: true, NextRunAt: at(2 * time.Hour)},
+		{ID: "disabled", Enabled: false, NextRunAt: at(time.Hour)},
+		{ID: "used-up", Enabled: true, NextRunAt: at(time.Hour), MaxRuns: 3, RunsCompleted: 3},
+		{ID: "from-last-run", Enabled: true, IntervalHours: 6, LastRunAt: at(-2 * time.Hour)},
+		{ID: "overdue", Enabled: true, IntervalHours: 1, CreatedAt: *at(-5 * time.Hour)},
+		{ID: "no-interval", Enabled: true},
+	}
+
+	var got []string
+	for _, s := range upcomingSchedules(schedules, now, 24*time.Hour) {
+		got = append(got, s.ID)
+	}
+	if want := "overdue soon from-last-run"; strings.Join(got, " ") != want {
+		t.Errorf("upcomingSchedules() = %v, want %s", got, want)
+	}
+	if got := scheduleDueIn(*at(90 * time.Minute), now); got != "in 1h30m" {
+		t.Errorf("scheduleDueIn() = %q", got)
+	}
+}
+
+// TestMaxResponseSize verifies size suffixes are parsed and that responses over
+// the limit are refused, except for downloads.
+func TestMaxResponseSize(t *testing.T) {
+	for in, want := range map[string]int64{"0": 0, "512": 512, "512KB": 512 << 10, "100mb": 100 << 20, "2G": 2 << 30} {
+		if got, err := client.ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "1x", "MB"} {
+		if _, err := client.ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) succeeded, want an error", in)
+		}
+	}
+
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"scans": ["` + strings.Repeat("x", 2048) + `"]}`))
+	})
+	c.MaxResponseSize = 1 << 10
+	err := c.Get("/api/scans", nil)
+	if !errors.Is(err, client.ErrResponseTooLarge) || !strings.Contains(err.Error(), "1KB") {
+		t.Errorf("Get() over the limit = %v, want ErrResponseTooLarge", err)
+	}
+	if _, err := c.Download(context.Background(), "/api/scans", io.Discard); err != nil {
+		t.Errorf("Download() should not be limited: %v", err)
+	}
+	c.MaxResponseSize = 4 << 10
+	if err := c.Get("/api/scans", nil); err != nil {
+		t.Errorf("Get() under the limit = %v", err)
+	}
+}
+
+// TestSortEvents verifies events sort by each --sort key in either direction,
+// and that the response envelope is kept around the sorted events.
+func TestSortEvents(t *testing.T) {
+	events := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"hash": "a", "generated": 3.0, "type": "IP_ADDRESS", "module": "sfp_dns", "risk": 0.0},
+			{"hash": "b", "generated": 1.0, "type": "INTERNET_NAME", "module": "sfp_whois", "risk": "HIGH"},
+			{"hash": "c", "generated": 2.0, "type": "IP_ADDRESS", "module": "sfp_dns", "risk": 50.0},
+			{"hash": "d", "generated": 4.0, "type": "EMAILADDR", "module": "sfp_abc"},
+		}
+	}
+	hashes := func(evs []map[string]interface{}) string {
+		var out []string
+		for _, e := range evs {
+			out = append(out, e["hash"].(string))
+		}
+		return strings.Join(out, "")
+	}
+
+	for _, tc := range []struct {
+		key     string
+		reverse bool
+		want    string
+	}{
+		{"time", false, "bcad"},
+		{"time", true, "dacb"},
+		{"risk", false, "bcad"},
+		{"risk", true, "dacb"},
+		{"module", false, "dcab"},
+		{"type", false, "dbca"},
+	} {
+		evs := events()
+		sortEvents(evs, tc.key, tc.reverse)
+		if got := hashes(evs); got != tc.want {
+			t.Errorf("sortEvents(%s, reverse=%t) = %s, want %s", tc.key, tc.reverse, got, tc.want)
+		}
+	}
+	if got := eventRiskScore("medium"); got <= eventRiskScore("LOW") || got >= eventRiskScore("HIGH") {
+		t.Errorf("eventRiskScore(medium) = %v, not between LOW and HIGH", got)
+	}
+
+	resp := map[string]interface{}{"events": []interface{}{}, "total": 250.0, "scan_id": "s1"}
+	got := withEventItems(resp, events()[:2]).(map[string]interface{})
+	if got["total"] != 250.0 || got["scan_id"] != "s1" || len(got["events"].([]map[string]interface{})) != 2 {
+		t.Errorf("withEventItems() = %v, want envelope kept with 2 events", got)
+	}
+}
+
+// TestConfigDoctor verifies config doctor passes, warns or fails each check
+// against a server without credentials and with a skewed clock, and compares
+// versions.
+func TestConfigDoctor(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+		w.Write([]byte(`{"status": "ok", "version": "5.2.0"}`))
+	})
+	statuses := map[string]string{}
+	for _, ch := range runDoctor(c) {
+		statuses[ch.Name] = ch.Status
+	}
+	want := map[string]string{
+		"Server URL":     doctorPass,
+		"Connectivity":   doctorPass,
+		"Authentication": doctorWarn,
+		"TLS":            doctorPass,
+		"Clock skew":     doctorFail,
+		"Versions":       doctorWarn,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("%s check = %q, want %q", name, statuses[name], status)
+		}
+	}
+
+	if ch := checkVersions("6.0.0", "v6.1.2", "", ""); ch.Status != doctorPass {
+		t.Errorf("checkVersions(6.0.0, v6.1.2) = %q, want pass", ch.Status)
+	}
+	if ch := checkVersions("6.0.0", "5.9.0", "", ""); ch.Status != doctorWarn {
+		t.Errorf("checkVersions(6.0.0, 5.9.0) = %q, want warn", ch.Status)
+	}
+	if ch := checkVersions("6.0.0", "6.0.0", "v1", "v2"); ch.Status != doctorWarn || !strings.Contains(ch.Hint, "--api-version v2") {
+		t.Errorf("checkVersions(API v1, served v2) = %+v, want a warning suggesting --api-version v2", ch)
+	}
+	now := time.Now()
+	if ch := checkClockSkew(now.Add(-time.Minute).UTC().Format(http.TimeFormat), now, now); ch.Status != doctorWarn ||
+		!strings.Contains(ch.Detail, "ahead of") {
+		t.Errorf("checkClockSkew(1m behind) = %+v, want a warning that the local clock is ahead", ch)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("server: [unclosed\n"), 0o600)
+	if ch := checkConfigFile(path); ch.Status != doctorFail {
+		t.Errorf("checkConfigFile(broken YAML) = %q, want fail", ch.Status)
+	}
+	if ch, u := checkServerURL("localhost:5001"); ch.Status != doctorFail || u != nil {
+		t.Errorf("checkServerURL(localhost:5001) = %q, want fail", ch.Status)
+	}
+}
+
+// TestWatchScan verifies scan watch reports progress until the scan ends, and
+// stops at --wait-timeout or when cancelled.
+func TestWatchScan(t *testing.T) {
+	polls := 0
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch {
+		case r.URL.Path == "/api/scans/slow":
+			w.Write([]byte(`{"scan_id": "slow", "status": "RUNNING", "progress": 10}`))
+		case polls < 3:
+			fmt.Fprintf(w, `{"scan_id": "s1", "status": "RUNNING", "progress": %d}`, polls*40)
+		default:
+			w.Write([]byte(`{"scan_id": "s1", "status": "FINISHED", "progress": 100}`))
+		}
+	})
+
+	var seen []int
+	s, err := watchScan(context.Background(), c, "s1", time.Millisecond, 0, func(s scanDetail) {
+		seen = append(seen, s.Progress)
+	})
+	if err != nil || s.Status != "FINISHED" || fmt.Sprint(seen) != "[40 80 100]" {
+		t.Errorf("watchScan() = %s, %v after progress %v", s.Status, err, seen)
+	}
+
+	_, err = watchScan(context.Background(), c, "slow", time.Millisecond, 20*time.Millisecond, func(scanDetail) {})
+	if err == nil || !strings.Contains(err.Error(), "still RUNNING (10%)") {
+		t.Errorf("watchScan() past --wait-timeout = %v, want a timeout error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := watchScan(ctx, c, "slow", time.Millisecond, 0, func(scanDetail) {}); !errors.Is(err, context.Canceled) {
+		t.Errorf("watchScan() after cancel = %v, want context.Canceled", err)
+	}
+}
+
+// TestScanListPages verifies the scan list is paged by offset while the
+// server reports has_next, and paged client-side when the server does not.
+func TestScanListPages(t *testing.T) {
+	var queries []string
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, r.URL.RawQuery)
+		switch {
+		case q.Get("limit") == "2":
+			w.Write([]byte(`{"items": [{"scan_id": "s3"}, {"scan_id": "s4"}], "total": 5, "page": 2, "has_next": true}`))
+		case q.Get("offset") == "":
+			w.Write([]byte(`{"items": [{"scan_id": "s1"}, {"scan_id": "s2"}], "total": 5, "has_next": true}`))
+		case q.Get("offset") == "2":
+			w.Write([]byte(`{"items": [{"scan_id": "s3"}, {"scan_id": "s4"}], "total": 5, "has_next": true}`))
+		default:
+			w.Write([]byte(`{"items": [{"scan_id": "s5"}], "total": 5, "has_next": false}`))
+		}
+	})
+
+	scans, err := fetchScanList(c, time.Time{}, time.Time{})
+	if err != nil || len(scans) != 5 || scans[4].ScanID != "s5" {
+		t.Errorf("fetchScanList() paged to %d scans, %v; want 5", len(scans), err)
+	}
+	if want := []string{"limit=1000", "limit=1000&offset=2", "limit=1000&offset=4"}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("fetchScanList() queries = %q, want %q", queries, want)
+	}
+
+	page, err := fetchScanPage(c, nil, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 5 || page.Returned != 2 || !page.More || page.Field != "items" || page.Envelope["page"] != 2.0 {
+		t.Errorf("fetchScanPage() = %+v", page)
+	}
+	footer, hint := scanPageFooter(page, 2, 2)
+	if footer != "Showing 3-4 of 5" || hint != "Next page: sf scan list --limit 2 --offset 4" {
+		t.Errorf("scanPageFooter() = %q, %q", footer, hint)
+	}
+
+	// A server that does not paginate is paged here, --offset included
+	// without --limit.
+	flat := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"scans": [{"scan_id": "s1"}, {"scan_id": "s2"}, {"scan_id": "s3"}]}`))
+	}))
+	defer flat.Close()
+	c = &client.Client{BaseURL: flat.URL, HTTPClient: flat.Client()}
+	if page, err = fetchScanPage(c, nil, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if footer, hint = scanPageFooter(page, 0, 1); footer != "Showing 2-3 of 3" || hint != "" || page.Scans[0].ScanID != "s2" {
+		t.Errorf("scanPageFooter() = %q, %q for %+v", footer, hint, page.Scans)
+	}
+}
+
+func TestFindingsByRisk(t *testing.T) {
+	findings := []pdfFinding{
+		{Risk: "LOW", EventCount: 2},
+		{Risk: "high", EventCount: 5},
+		{Risk: "HIGH", EventCount: 1},
+		{Risk: "", EventCount: 3},
+	}
+	want := "[{HIGH 2 6} {MEDIUM 0 0} {LOW 1 2} {INFO 1 3}]"
+	if got := fmt.Sprint(findingsByRisk(findings)); got != want {
+		t.Errorf("findingsByRisk() = %s, want %s", got, want)
+	}
+}
+
+func TestAPIVersionMismatch(t *testing.T) {
+	var requested string
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = r.Header.Get("Accept-Version")
+		w.Header().Set("X-API-Version", "v2")
+		w.Write([]byte(`{}`))
+	})
+
+	var warnings []string
+	defer func(f func(string, string)) { client.VersionMismatch = f }(client.VersionMismatch)
+	client.VersionMismatch = func(requested, served string) {
+		warnings = append(warnings, requested+"->"+served)
+	}
+	c.APIVersion = client.APIVersion
+	for i := 0; i < 2; i++ {
+		if err := c.Get("/api/health", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requested != client.APIVersion {
+		t.Errorf("Accept-Version = %q, want %q", requested, client.APIVersion)
+	}
+	if fmt.Sprint(warnings) != "[v1->v2]" {
+		t.Errorf("warnings = %v, want one v1->v2 warning", warnings)
+	}
+}
+
+func TestEventsGeoJSON(t *testing.T) {
+	events := []map[string]interface{}{
+		{"type": "PHYSICAL_COORDINATES", "data": "51.5, -0.12", "module": "sfp_ipapicom"},
+		{"type": "IP_ADDRESS", "data": "1.2.3.4", "latitude": 40.7, "longitude": -74.0},
+		{"type": "IP_ADDRESS", "data": "5.6.7.8"},
+		{"type": "PHYSICAL_COORDINATES", "data": "not a place"},
+		{"type": "PHYSICAL_COORDINATES", "data": "123, 45"},
+	}
+	fc, skipped := eventsGeoJSON(events)
+	if skipped != 3 || len(fc.Features) != 2 {
+		t.Fatalf("eventsGeoJSON() = %d features, %d skipped; want 2, 3", len(fc.Features), skipped)
+	}
+	if got := fmt.Sprint(fc.Features[0].Geometry.Coordinates); got != "[-0.12 51.5]" {
+		t.Errorf("coordinates = %s, want longitude first", got)
+	}
+	if fc.Features[1].Properties["data"] != "1.2.3.4" {
+		t.Errorf("properties = %v", fc.Features[1].Properties)
+	}
+}
+
+func TestScanOptionsConfig(t *testing.T) {
+	resp := scanOptionsResp{Config: map[string]interface{}{
+		"_modulesenabled":         "sfp_geo,sfp__stor_stdout,sfp_dns",
+		"_maxthreads":             float64(3),
+		"sfp_dns:validatereverse": true,
+	}}
+	cfg := resp.scanConfig()
+	if got := fmt.Sprint(cfg.Modules); got != "[sfp_dns sfp_geo]" {
+		t.Errorf("Modules = %s", got)
+	}
+	if len(cfg.Options) != 2 || cfg.Options["sfp_dns:validatereverse"] != true {
+		t.Errorf("Options = %v", cfg.Options)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	calls := 0
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	var notes []string
+	defer func(f func(int, int, int, time.Duration)) { client.RetryNotify = f }(client.RetryNotify)
+	client.RetryNotify = func(attempt, max, status int, wait time.Duration) {
+		notes = append(notes, fmt.Sprintf("%d/%d %d %s", attempt, max, status, wait))
+	}
+	before := client.RetryCount()
+	c.Retries = 3
+	if err := c.Get("/api/scans", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(notes); got != "[1/3 503 0s 2/3 503 0s]" {
+		t.Errorf("retry notes = %s", got)
+	}
+	if n := client.RetryCount() - before; n != 2 {
+		t.Errorf("RetryCount() grew by %d, want 2", n)
+	}
+
+	calls = 0
+	c.Retries = 1
+	if err := c.Get("/api/scans", nil); !errors.Is(err, client.ErrServer) {
+		t.Errorf("Get() with exhausted retries = %v, want server error", err)
+	}
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+	calls = 0
+	c = &client.Client{BaseURL: limited.URL, Retries: 3, HTTPClient: limited.Client()}
+	if err := c.Get("/api/scans", nil); !errors.Is(err, client.ErrRateLimited) || calls != 1 {
+		t.Errorf("Get() on 429 = %v after %d calls, want a rate limited error after 1", err, calls)
+	}
+}
+
+// TestRetryBackoff verifies which failures are retried and that the backoff
+// doubles between attempts.
+func TestRetryBackoff(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/gone", "/api/forbidden":
+			// The first attempt fails, the second finds a different answer.
+			if len(calls) < 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			if r.URL.Path == "/api/gone" {
+				w.WriteHeader(http.StatusNotFound)
+			} else {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		case "/api/flaky":
+			if len(calls) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	var statuses []int
+	defer func(f func(int, int, int, time.Duration)) { client.RetryNotify = f }(client.RetryNotify)
+	client.RetryNotify = func(attempt, max, status int, wait time.Duration) {
+		waits = append(waits, wait)
+		statuses = append(statuses, status)
+	}
+	c := &client.Client{BaseURL: srv.URL, Retries: 2, RetryWait: 10 * time.Millisecond, HTTPClient: srv.Client()}
+	if err := c.Get("/api/flaky", nil); err != nil {
+		t.Fatalf("Get() after two 502s = %v", err)
+	}
+	if len(waits) != 2 || waits[0] < 5*time.Millisecond || waits[0] > 10*time.Millisecond ||
+		waits[1] < 10*time.Millisecond || waits[1] > 20*time.Millisecond {
+		t.Errorf("backoff waits = %v, want one in [5ms,10ms] then one in [10ms,20ms]", waits)
+	}
+
+	calls = nil
+	err := c.Delete("/api/down", nil)
+	if !errors.Is(err, client.ErrServer) || !strings.Contains(err.Error(), "gave up after 3 attempts") || len(calls) != 3 {
+		t.Errorf("Delete() on 503 = %v after %d calls, want a server error after 3 attempts", err, len(calls))
+	}
+	calls = nil
+	if err := c.Post("/api/down", nil, nil); err == nil || len(calls) != 1 {
+		t.Errorf("Post() was sent %d times, want once", len(calls))
+	}
+	calls = nil
+	if err := c.Get("/api/missing", nil); !errors.Is(err, client.ErrNotFound) || len(calls) != 1 {
+		t.Errorf("Get() on 404 was sent %d times, want once", len(calls))
+	}
+	calls = nil
+	var deleted map[string]interface{}
+	if err := c.Delete("/api/gone", &deleted); err != nil || len(calls) != 2 {
+		t.Errorf("Delete() answered 502 then 404 = %v after %d calls, want success after 2", err, len(calls))
+	}
+	calls = nil
+	if err := c.Get("/api/forbidden", nil); err == nil || !strings.Contains(err.Error(), "gave up after 2 attempts") {
+		t.Errorf("Get() answered 502 then 403 = %v, want the attempt count", err)
+	}
+	calls = nil
+	bad := &client.Client{BaseURL: "ftp://example.com", Retries: 2, RetryWait: time.Millisecond, HTTPClient: srv.Client()}
+	if err := bad.Get("/api/scans", nil); err == nil || strings.Contains(err.Error(), "gave up") {
+		t.Errorf("Get() with an unsupported scheme = %v, want one attempt", err)
+	}
+
+	statuses = nil
+	srv.Close()
+	if err := c.Get("/api/flaky", nil); err == nil || !strings.Contains(err.Error(), "gave up after 3 attempts") ||
+		fmt.Sprint(statuses) != "[0 0]" {
+		t.Errorf("Get() with the server down = %v, retry statuses %v", err, statuses)
+	}
+}
+
+// TestDownloadResume verifies an interrupted download continues with a range
+// request when the server allows it and starts over when it does not.
+func TestDownloadResume(t *testing.T) {
+	body := strings.Repeat("0123456789", 100)
+	for _, ranges := range []bool{true, false} {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if ranges {
+				w.Header().Set("Accept-Ranges", "bytes")
+			}
+			if rng := r.Header.Get("Range"); ranges && rng == "bytes=400-" {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 400-%d/%d", len(body)-1, len(body)))
+				w.Header().Set("Content-Length", fmt.Sprint(len(body)-400))
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(body[400:]))
+				return
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			if calls == 1 {
+				w.Write([]byte(body[:400]))
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			w.Write([]byte(body))
+		}))
+
+		var notes []string
+		client.ResumeNotify = func(attempt, max int, kept int64, ranged bool) {
+			notes = append(notes, fmt.Sprintf("%d/%d %d %v", attempt, max, kept, ranged))
+		}
+		f, err := os.CreateTemp(t.TempDir(), "export")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &client.Client{BaseURL: srv.URL, Retries: 2, HTTPClient: srv.Client()}
+		n, err := c.Download(context.Background(), "/api/scans/abc/export", f)
+		f.Close()
+		srv.Close()
+		if err != nil {
+			t.Fatalf("Download(ranges=%v) error = %v", ranges, err)
+		}
+		data, _ := os.ReadFile(f.Name())
+		if n != int64(len(body)) || string(data) != body {
+			t.Errorf("Download(ranges=%v) wrote %d bytes, file matches = %v", ranges, n, string(data) == body)
+		}
+		want := fmt.Sprintf("[1/2 %d %v]", map[bool]int{true: 400, false: 0}[ranges], ranges)
+		if got := fmt.Sprint(notes); got != want {
+			t.Errorf("Download(ranges=%v) notes = %s, want %s", ranges, got, want)
+		}
+	}
+	client.ResumeNotify = nil
+}
+
+// TestEventForwarder verifies webhook batching, headers and retries.
+func TestEventForwarder(t *testing.T) {
+	var bodies []string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer hook" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+	}))
+	defer srv.Close()
+
+	fwd := &eventForwarder{url: srv.URL, header: http.Header{"Authorization": {"Bearer hook"}}, batch: 2, http: srv.Client()}
+	fwd.forward(context.Background(), []map[string]interface{}{{"data": "a"}, {"data": "b"}, {"data": "c"}})
+	if fmt.Sprint(bodies) != `[[{"data":"a"},{"data":"b"}] [{"data":"c"}]]` || fwd.delivered != 3 || fwd.failed != 0 {
+		t.Errorf("forward() bodies = %v, delivered %d, failed %d", bodies, fwd.delivered, fwd.failed)
+	}
+
+	fwd.header = nil
+	fwd.batch = 1
+	fwd.forward(context.Background(), []map[string]interface{}{{"data": "d"}})
+	if fwd.failed != 1 {
+		t.Errorf("forward() rejected delivery counted %d failed, want 1", fwd.failed)
+	}
+}
+
+// TestModulesInCategories verifies --module-categories matches categories
+// case-insensitively, lists the valid ones for an unknown name, and merges the
+// chosen modules with --modules minus --exclude-modules.
+func TestModulesInCategories(t *testing.T) {
+	modules := []moduleInfo{
+		{Name: "sfp_dnsresolve", Categories: []string{"DNS"}},
+		{Name: "sfp_spider", Categories: []string{"Crawling and Scanning"}},
+		{Name: "sfp_haveibeenpwned", Categories: []string{"Leaks, Dumps and Breaches"}},
+		{Name: "sfp_dnsbrute", Categories: []string{"DNS"}},
+	}
+	got, err := modulesInCategories(modules, "dns, leaks, dumps and breaches")
+	if err != nil || fmt.Sprint(got) != "[sfp_dnsbrute sfp_dnsresolve sfp_haveibeenpwned]" {
+		t.Errorf("modulesInCategories() = %v, %v", got, err)
+	}
+	if _, err := modulesInCategories(modules, "dns,web"); err == nil || !strings.Contains(err.Error(), "Crawling and Scanning; DNS") {
+		t.Errorf("modulesInCategories(unknown) error = %v, want the valid categories listed", err)
+	}
+	if got := combineModules([]string{"sfp_whois", "sfp_dnsbrute"}, []string{"sfp_dnsbrute", "sfp_dnsresolve"}, []string{"sfp_dnsresolve"}); fmt.Sprint(got) != "[sfp_whois sfp_dnsbrute]" {
+		t.Errorf("combineModules() = %v", got)
+	}
+}
+
+// TestLogLine verifies scan log rows and legacy log entries both format as one
+// line.
+func TestLogLine(t *testing.T) {
+	var resp interface{}
+	json.Unmarshal([]byte(`{"logs": [{"generated": 1700000000000, "component": "sfp_dns", "type": "INFO", "message": "resolving"}], "total": 1}`), &resp)
+	items, ok := logItems(resp)
+	if !ok || len(items) != 1 {
+		t.Fatalf("logItems() = %v, %v", items, ok)
+	}
+	want := "[" + time.Unix(1700000000, 0).Format("2006-01-02 15:04:05") + "] INFO sfp_dns: resolving"
+	if got := logLine(items[0]); got != want {
+		t.Errorf("logLine(scan log row) = %q, want %q", got, want)
+	}
+	if got := logLine(map[string]interface{}{"timestamp": "t1", "level": "WARN", "message": "slow"}); got != "[t1] WARN: slow" {
+		t.Errorf("logLine(legacy) = %q", got)
+	}
+}
+
+// captureStdout returns what f writes to stdout.
+func captureStdout(t *testing.T, f func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	ferr := f()
+	w.Close()
+	os.Stdout = stdout
+	data, _ := io.ReadAll(r)
+	if ferr != nil {
+		t.Fatal(ferr)
+	}
+	return string(data)
+}
+
+// TestCSVQuoting verifies values with commas, quotes and line breaks survive
+// CSV output of scan list, modules list and scan events intact.
+func TestCSVQuoting(t *testing.T) {
+	const tricky = "SSH-2.0-OpenSSH_8.9\r\nWelcome, \"admin\"\nlast line"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/scans":
+			json.NewEncoder(w).Encode(scansResp{Scans: []scanSummary{{ScanID: "abc", Name: tricky, Target: "example.com", Status: "FINISHED"}}})
+		case "/api/data/modules":
+			json.NewEncoder(w).Encode([]moduleInfo{{Name: "sfp_banner", Type: "active", Description: tricky}})
+		case "/api/scans/abc/events":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"type": "TCP_PORT_OPEN_BANNER", "module": "sfp_banner", "data": tricky, "source": "ROOT"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(server, format interface{}) {
+		viper.Set("server", server)
+		viper.Set("output", format)
+	}(viper.Get("server"), viper.Get("output"))
+	viper.Set("server", srv.URL)
+	viper.Set("output", "csv")
+
+	for _, tc := range []struct {
+		name string
+		run  func() error
+		col  int
+	}{
+		{"scan list", func() error { return scanListCmd.RunE(scanListCmd, nil) }, 1},
+		{"modules list", func() error { return modulesListCmd.RunE(modulesListCmd, nil) }, 2},
+		{"scan events", func() error { return scanEventsCmd.RunE(scanEventsCmd, []string{"abc"}) }, 2},
+	} {
+		out := captureStdout(t, tc.run)
+		records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+		if err != nil {
+			t.Errorf("%s: CSV does not parse: %v\n%s", tc.name, err, out)
+			continue
+		}
+		// Readers fold a quoted CRLF to LF.
+		if want := strings.ReplaceAll(tricky, "\r\n", "\n"); len(records) != 2 || records[1][tc.col] != want {
+			t.Errorf("%s: records = %q, want %q in column %d", tc.name, records, want, tc.col)
+		}
+	}
+}
+
+// TestFetchEventPages verifies events are fetched page by page up to the limit,
+// and in one request from a server that ignores paging.
+func TestFetchEventPages(t *testing.T) {
+	var all []map[string]interface{}
+	for i := 0; i < 25; i++ {
+		all = append(all, map[string]interface{}{"hash": fmt.Sprintf("h%d", i), "type": "IP_ADDRESS"})
+	}
+	paginate := true
+	var requests []string
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		page := all
+		if paginate {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			page = all[min(offset, len(all)):min(offset+limit, len(all))]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"events": page, "total": len(page)})
+	})
+
+	events, err := fetchEventPages(c, "abc", "", 10, 0)
+	if err != nil || len(events) != 25 || len(requests) != 3 || events[24]["hash"] != "h24" {
+		t.Errorf("fetchEventPages(all) = %d events, %v, requests %v", len(events), err, requests)
+	}
+
+	requests = nil
+	events, _ = fetchEventPages(c, "abc", "", 10, 15)
+	if len(events) != 15 || fmt.Sprint(requests) != "[limit=10&offset=0 limit=5&offset=10]" {
+		t.Errorf("fetchEventPages(limit 15) = %d events, requests %v", len(events), requests)
+	}
+
+	paginate, requests = false, nil
+	events, _ = fetchEventPages(c, "abc", "", 10, 0)
+	if len(events) != 25 || len(requests) != 1 {
+		t.Errorf("fetchEventPages(unpaginated server) = %d events in %d requests, want 25 in 1", len(events), len(requests))
+	}
+}
+
+// TestAnonymizer verifies pseudonyms are stable per key, keep subdomains under
+// a common parent, stay distinct when IPv4 hashes collide and leave file
+// names alone.
+func TestAnonymizer(t *testing.T) {
+	a := newAnonymizer("secret")
+	www, mail := a.text("www.example.com"), a.text("mail.example.com")
+	if www == "www.example.com" || !strings.HasSuffix(www, ".com") {
+		t.Fatalf("text(domain) = %q", www)
+	}
+	if www[strings.Index(www, "."):] != mail[strings.Index(mail, "."):] {
+		t.Errorf("subdomains lost their common parent: %q, %q", www, mail)
+	}
+	if again := newAnonymizer("secret").text("www.example.com"); again != www {
+		t.Errorf("pseudonyms differ for the same key: %q, %q", www, again)
+	}
+	if other := newAnonymizer("other").text("www.example.com"); other == www {
+		t.Errorf("pseudonyms match across keys: %q", other)
+	}
+
+	got := a.text("admin@example.com resolved to 192.0.2.10 and 2001:db8::1 at 12:30:05")
+	if strings.Contains(got, "example") || strings.Contains(got, "192.0.2.10") || strings.Contains(got, "2001:db8") {
+		t.Errorf("text() leaked a value: %q", got)
+	}
+	if !strings.HasSuffix(got, " at 12:30:05") || !strings.Contains(got, "@"+www[strings.Index(www, ".")+1:]) {
+		t.Errorf("text() = %q, want the time kept and the email domain pseudonymized like the host's parent", got)
+	}
+
+	r := &redactor{fields: map[string]bool{}, anon: a}
+	event := map[string]interface{}{"type": "INTERNET_NAME", "module": "sfp_dns", "data": "www.example.com"}
+	r.value(event)
+	if event["type"] != "INTERNET_NAME" || event["module"] != "sfp_dns" || event["data"] != www {
+		t.Errorf("value() = %v, want only data pseudonymized", event)
+	}
+
+	if got := a.text("see report.pdf and setup.exe"); got != "see report.pdf and setup.exe" {
+		t.Errorf("text() = %q, want file names kept", got)
+	}
+	second := newAnonymizer("secret").ip(net.ParseIP("192.0.2.2"))
+	b := newAnonymizer("secret")
+	b.taken[second] = true
+	if got := b.ip(net.ParseIP("192.0.2.2")); got == second || got != b.ip(net.ParseIP("192.0.2.2")) {
+		t.Errorf("ip() after a collision = %q, want a stable pseudonym other than %q", got, second)
+	}
+}
+
+// TestSummarizeHealth verifies the health report counts only the chosen
+// server's checks since the cut-off, and derives availability, latency and the
+// last outage from them.
+func TestSummarizeHealth(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	checks := []healthCheck{
+		{Timestamp: "2024-04-30T23:00:00Z", Server: "http://sf", Status: healthUnreachable},
+		{Timestamp: "2024-05-01T01:00:00Z", Server: "http://sf", Status: "healthy", LatencyMS: 20},
+		{Timestamp: "2024-05-01T02:00:00Z", Server: "http://sf", Status: "degraded", LatencyMS: 80},
+		{Timestamp: "2024-05-01T03:00:00Z", Server: "http://sf", Status: healthUnreachable, LatencyMS: 5},
+		{Timestamp: "2024-05-01T04:00:00Z", Server: "http://sf", Status: "ok", LatencyMS: 15},
+		{Timestamp: "2024-05-01T05:00:00Z", Server: "http://other", Status: healthUnreachable},
+	}
+	r := summarizeHealth(checks, "http://sf", since)
+	if r.Checks != 4 || r.Up != 2 || r.Degraded != 1 || r.Down != 1 {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.Availability != 75 || r.AvgLatencyMS != 30 || r.MaxLatencyMS != 80 {
+		t.Errorf("availability/latency = %v/%d/%d", r.Availability, r.AvgLatencyMS, r.MaxLatencyMS)
+	}
+	if r.LastDown != "2024-05-01T03:00:00Z" {
+		t.Errorf("LastDown = %q", r.LastDown)
+	}
+}
+
+// TestGroupScansByTarget verifies scans are grouped by normalized target,
+// newest scan first in each group.
+func TestGroupScansByTarget(t *testing.T) {
+	groups := groupScansByTarget([]scanSummary{
+		{ScanID: "a", Target: "b.example", StartedAt: 10},
+		{ScanID: "b", Target: "acme.com", StartedAt: 20, Status: "FINISHED"},
+		{ScanID: "c", Target: " ACME.com", StartedAt: 30, Status: "RUNNING"},
+	})
+	if len(groups) != 2 || groups[0].Target != "acme.com" || groups[1].Target != "b.example" {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if got := groups[0].Scans; len(got) != 2 || got[0].ScanID != "c" || got[1].ScanID != "b" {
+		t.Errorf("acme.com scans = %+v, want newest first", got)
+	}
+}
+
+func TestCACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600)
+	badFile := filepath.Join(dir, "bad.pem")
+	os.WriteFile(badFile, []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydA==\n-----END CERTIFICATE-----\n"), 0600)
+
+	viper.Set("server", srv.URL)
+	defer viper.Set("server", "")
+	if err := client.New().Get("/", nil); err == nil {
+		t.Fatal("request to a server with an unknown CA succeeded")
+	}
+	for _, only := range []bool{false, true} {
+		viper.Set("ca_cert", caFile)
+		viper.Set("ca_cert_only", only)
+		if err := client.New().Get("/", nil); err != nil {
+			t.Errorf("with --ca-cert (only=%v): %v", only, err)
+		}
+	}
+	viper.Set("ca_cert", "")
+	viper.Set("ca_cert_only", false)
+
+	if _, err := client.LoadCACerts(badFile, false); err == nil || !strings.Contains(err.Error(), "parsing certificate 1") {
+		t.Errorf("LoadCACerts(bad) = %v", err)
+	}
+	if _, err := client.LoadCACerts(filepath.Join(dir, "missing.pem"), false); err == nil {
+		t.Error("LoadCACerts(missing) succeeded")
+	}
+}
+
+// TestCheckAuthTransport verifies credentials are refused over plain HTTP to a
+// remote server unless allowed, and accepted over HTTPS or to loopback.
+func TestCheckAuthTransport(t *testing.T) {
+	cases := []struct {
+		base    string
+		key     string
+		allow   bool
+		refused bool
+	}{
+		{"http://sf.example.com:8001", "k", false, true},
+		{"http://sf.example.com:8001", "k", true, false},
+		{"http://sf.example.com:8001", "", false, false},
+		{"https://sf.example.com", "k", false, false},
+		{"http://localhost:8001", "k", false, false},
+		{"http://127.0.0.1:8001", "k", false, false},
+		{"http://[::1]:8001", "k", false, false},
+	}
+	for _, tc := range cases {
+		c := &client.Client{BaseURL: tc.base, APIKey: tc.key, AllowInsecureAuth: tc.allow}
+		if err := c.CheckAuthTransport(); errors.Is(err, client.ErrInsecureAuth) != tc.refused {
+			t.Errorf("%s (key %q, allow %v): err = %v", tc.base, tc.key, tc.allow, err)
+		}
+	}
+}
+
+// TestEventFilter verifies filter expressions match on fields, lists, string
+// operators and nested keys, and that malformed expressions are rejected.
+func TestEventFilter(t *testing.T) {
+	events := []map[string]interface{}{
+		{"type": "IP_ADDRESS", "risk": "HIGH", "data": "10.0.0.1", "confidence": float64(90)},
+		{"type": "DOMAIN_NAME", "risk": "LOW", "data": "a.example.com", "meta": map[string]interface{}{"asn": "AS1"}},
+		{"type": "EMAILADDR", "risk": "HIGH", "data": "x@example.com"},
+	}
+	cases := map[string]int{
+		`risk == "HIGH" && type in ["IP_ADDRESS","DOMAIN_NAME"]`:     1,
+		`type not in ['IP_ADDRESS'] and data endsWith "example.com"`: 2,
+		`!(risk == "LOW") || confidence > 95`:                        2,
+		`confidence >= 90`:                                           1,
+		`data matches "^10\\."`:                                      1,
+		`meta.asn == "AS1"`:                                          1,
+		`missing == nil`:                                             3,
+		`risk in ["HIGH"] && len(data) > 10`:                         1,
+	}
+	for src, want := range cases {
+		f, err := compileFilter(src)
+		if err != nil {
+			t.Errorf("compileFilter(%q): %v", src, err)
+			continue
+		}
+		if got := len(filterEvents(f, events)); got != want {
+			t.Errorf("%s matched %d events, want %d", src, got, want)
+		}
+	}
+	for _, bad := range []string{`type ==`, `type = "x"`, `(risk == "HIGH"`, `data matches "("`, `"unterminated`} {
+		if _, err := compileFilter(bad); err == nil {
+			t.Errorf("compileFilter(%q) accepted", bad)
+		}
+	}
+}
+
+func TestFetchEventTypeDescriptions(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"event_types": [["IP Address", "IP_ADDRESS", 0, "ENTITY"],
+			{"event": "SSL_CERTIFICATE_ISSUED", "event_descr": "SSL Certificate - Issued to"}]}`))
+	})
+
+	got, err := fetchEventTypeDescriptions(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["IP_ADDRESS"] != "IP Address" || got["SSL_CERTIFICATE_ISSUED"] != "SSL Certificate - Issued to" {
+		t.Errorf("descriptions = %v", got)
+	}
+
+	header, rows := eventRows([]map[string]interface{}{{"type": "IP_ADDRESS", "type_description": "IP Address", "module": "m", "data": "1.2.3.4"}}, false, true, false)
+	if header[1] != "Description" || rows[0][1] != "IP Address" || rows[0][3] != "1.2.3.4" {
+		t.Errorf("eventRows() = %v %v", header, rows)
+	}
+}
+
+func TestDiffByModule(t *testing.T) {
+	a := []map[string]interface{}{
+		{"type": "IP_ADDRESS", "data": "1.2.3.4", "module": "sfp_dnsresolve"},
+		{"type": "INTERNET_NAME", "data": "old.example.com", "module": "sfp_crt"},
+	}
+	b := []map[string]interface{}{
+		{"type": "IP_ADDRESS", "data": "1.2.3.4", "module": "sfp_dnsresolve"},
+		{"type": "INTERNET_NAME", "data": "b.example.com", "module": "sfp_dnsresolve"},
+		{"type": "INTERNET_NAME", "data": "a.example.com", "module": "sfp_dnsresolve"},
+		{"type": "INTERNET_NAME", "data": "a.example.com", "module": "sfp_dnsresolve"},
+		{"type": "EMAILADDR", "data": "x@example.com"},
+	}
+	got := diffByModule(a, b)
+	if len(got) != 3 {
+		t.Fatalf("got %d modules, want 3: %+v", len(got), got)
+	}
+	if got[0].Module != "sfp_dnsresolve" || len(got[0].Added) != 2 || got[0].Added[0].Data != "a.example.com" || len(got[0].Removed) != 0 {
+		t.Errorf("first module = %+v", got[0])
+	}
+	if got[1].Module != unknownModule || len(got[1].Added) != 1 {
+		t.Errorf("second module = %+v", got[1])
+	}
+	if got[2].Module != "sfp_crt" || len(got[2].Removed) != 1 || got[2].Removed[0].Data != "old.example.com" {
+		t.Errorf("third module = %+v", got[2])
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total": 3, "events": [{"type": "IP_ADDRESS", "data": "1.2.3.4"},
+			{"type": "INTERNET_NAME", "data": "a.example.com"}, {"type": "IP_ADDRESS", "data": "5.6.7.8"}]}`))
+	})
+	viper.Set("compact", true)
+	defer viper.Set("compact", false)
+
+	filter, err := compileFilter(`type == "IP_ADDRESS"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := captureStdout(t, func() error {
+		return streamEvents(c, "s1", "/api/scans/s1/events", "", 0, 0, filter, map[string]string{"IP_ADDRESS": "IP Address"})
+	})
+	var got struct {
+		Events []map[string]interface{} `json:"events"`
+		Total  int                      `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("output is not a JSON object: %v\n%s", err, out)
+	}
+	if len(got.Events) != 2 || got.Total != 2 || got.Events[1]["data"] != "5.6.7.8" || got.Events[0]["type_description"] != "IP Address" {
+		t.Errorf("streamEvents() = %+v", got)
+	}
+
+	out = captureStdout(t, func() error {
+		return streamEvents(c, "s1", "/api/scans/s1/events", "", 0, 1, nil, nil)
+	})
+	if out != `{"events":[{"data":"1.2.3.4","type":"IP_ADDRESS"}],"total":1}`+"\n" {
+		t.Errorf("streamEvents() with limit 1 = %q", out)
+	}
+
+	// Indented, the stream matches what PrintJSON prints for the same events.
+	viper.Set("compact", false)
+	out = captureStdout(t, func() error {
+		return streamEvents(c, "s1", "/api/scans/s1/events", "", 0, 1, nil, nil)
+	})
+	want := captureStdout(t, func() error {
+		output.PrintJSON(map[string]interface{}{"events": []map[string]string{{"data": "1.2.3.4", "type": "IP_ADDRESS"}}, "total": 1})
+		return nil
+	})
+	if out != want {
+		t.Errorf("indented streamEvents() = %q, want %q", out, want)
+	}
+
+	big := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"scan_id": "s1", "event_count": 250000}`))
+	}))
+	defer big.Close()
+	if !largeEventList(&client.Client{BaseURL: big.URL, HTTPClient: big.Client()}, "s1") {
+		t.Error("largeEventList() = false for 250000 events")
+	}
+	if largeEventList(c, "s1") {
+		t.Error("largeEventList() = true without an event count")
+	}
+}
+
+// TestExplodeEvents verifies --explode splits a column into one row per
+// non-empty value without modifying the input events.
+func TestExplodeEvents(t *testing.T) {
+	key, err := eventColumnKey("Data", false)
+	if err != nil || key != "data" {
+		t.Fatalf("eventColumnKey(Data) = %q, %v", key, err)
+	}
+	if _, err := eventColumnKey("source_data", false); err == nil {
+		t.Error("source_data accepted without --resolve-source")
+	}
+
+	events := []map[string]interface{}{
+		{"type": "EMAILADDR", "module": "sfp_a", "data": "a@x.com; b@x.com;;"},
+		{"type": "IP_ADDRESS", "module": "sfp_b", "data": "1.2.3.4"},
+		{"type": "RAW", "module": "sfp_c"},
+	}
+	got := explodeEvents(events, "data", ";")
+	if len(got) != 4 {
+		t.Fatalf("got %d rows, want 4: %v", len(got), got)
+	}
+	if got[0]["data"] != "a@x.com" || got[1]["data"] != "b@x.com" || got[1]["module"] != "sfp_a" {
+		t.Errorf("exploded rows = %v", got[:2])
+	}
+	if events[0]["data"] != "a@x.com; b@x.com;;" {
+		t.Error("explodeEvents modified its input")
+	}
+}
+
+// TestCurlCommand verifies --curl prints a shell-quoted command that reads the
+// API key from $SF_API_KEY rather than embedding it.
+func TestCurlCommand(t *testing.T) {
+	c := &client.Client{BaseURL: "http://localhost:8001", APIKey: "secret"}
+	req, err := c.StreamRequest("POST", "/api/scans", strings.NewReader("x"), "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := curlCommand(req, `{"target": "it's"}`)
+	want := `curl -X POST -H 'Content-Type: application/json' -H 'User-Agent: SpiderFoot-CLI/` + client.Version +
+		`' -H "X-API-Key: $SF_API_KEY" --data-binary '{"target": "it'\''s"}' http://localhost:8001/api/scans`
+	if got != want {
+		t.Errorf("curlCommand() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "secret") {
+		t.Error("curl command contains the API key")
+	}
+}
+
+func TestConfigExportImport(t *testing.T) {
+	shared := viper.New()
+	shared.Set("server", "https://sf.example.com")
+	shared.Set("api_key", "k-123")
+	shared.Set("profiles.monkey.server", "http://monkey")
+	shared.Set("profiles.monkey.token", "t-456")
+	if got := sanitizeConfig(shared); strings.Join(got, ",") != "api_key,profiles.monkey.token" {
+		t.Fatalf("sanitizeConfig() replaced %v", got)
+	}
+	if shared.GetString("profiles.monkey.server") != "http://monkey" {
+		t.Error("sanitizeConfig() replaced a non-secret")
+	}
+
+	local := viper.New()
+	local.Set("server", "http://localhost:8001")
+	local.Set("api_key", "mine")
+	local.Set("output", "json")
+	changed, skipped := mergeConfig(local, shared)
+	if strings.Join(changed, ",") != "profiles.monkey.server,server" || strings.Join(skipped, ",") != "api_key,profiles.monkey.token" {
+		t.Errorf("mergeConfig() changed %v, skipped %v", changed, skipped)
+	}
+	if local.GetString("api_key") != "mine" || local.GetString("output") != "json" || local.GetString("server") != "https://sf.example.com" {
+		t.Errorf("merged config = %v", local.AllSettings())
+	}
+}
+
+func TestWaitAndExport(t *testing.T) {
+	polls := 0
+	_, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/export") {
+			t.Error("a failed scan was exported")
+		}
+		polls++
+		status := "RUNNING"
+		if polls > 2 {
+			status = "ERROR-FAILED"
+		}
+		fmt.Fprintf(w, `{"scan_id": "s1", "status": %q, "event_count": 7, "started": 1000, "ended": 1754}`, status)
+	})
+
+	var notified []scanNotification
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n scanNotification
+		json.NewDecoder(r.Body).Decode(&n)
+		notified = append(notified, n)
+	}))
+	defer hook.Close()
+	notifier := &scanNotifier{fwd: &eventForwarder{url: hook.URL, header: make(http.Header), batch: 1, http: hook.Client()}, on: notifyFailed}
+
+	err := waitAndExport(c, "s1", time.Millisecond, "json", "", notifier)
+	if err == nil || !strings.Contains(err.Error(), "ERROR-FAILED") {
+		t.Errorf("waitAndExport() = %v, want the failed status", err)
+	}
+	if polls != 3 {
+		t.Errorf("polled %d times, want 3", polls)
+	}
+	if len(notified) != 1 || notified[0].Status != "ERROR-FAILED" || notified[0].Succeeded || notified[0].EventCount != 7 || notified[0].Duration != "12m34s" {
+		t.Errorf("notifications = %+v", notified)
+	}
+
+	notifier.on = notifyFinished
+	notifier.notify(context.Background(), scanDetail{ScanID: "s1", Status: "ABORTED"})
+	if len(notified) != 1 {
+		t.Error("--notify-on finished reported an aborted scan")
+	}
+}
+
+func TestRecommendModules(t *testing.T) {
+	modules := []moduleInfo{
+		{Name: "sfp_dnsresolve", Type: "passive", Consumes: []string{"INTERNET_NAME"}, Provides: []string{"IP_ADDRESS"}},
+		{Name: "sfp_whois", Type: "passive", Consumes: []string{"INTERNET_NAME", "IP_ADDRESS"}, Provides: []string{"DOMAIN_WHOIS"}},
+		{Name: "sfp_portscan", Type: "active", Consumes: []string{"IP_ADDRESS"}, Provides: []string{"TCP_PORT_OPEN"}},
+		{Name: "sfp_banner", Type: "passive", Consumes: []string{"TCP_PORT_OPEN"}},
+		{Name: "sfp__stor_db", Consumes: []string{"*"}},
+	}
+	names := func(recs []moduleRecommendation) string {
+		var s []string
+		for _, r := range recs {
+			s = append(s, fmt.Sprintf("%s@%d", r.Module, r.Hop))
+		}
+		return strings.Join(s, ",")
+	}
+	if got := names(recommendModules(modules, "INTERNET_NAME", 3, false)); got != "sfp_dnsresolve@1,sfp_whois@1,sfp_portscan@2,sfp_banner@3" {
+		t.Errorf("recommendModules() = %s", got)
+	}
+	if got := names(recommendModules(modules, "INTERNET_NAME", 3, true)); got != "sfp_dnsresolve@1,sfp_whois@1" {
+		t.Errorf("recommendModules() passive only = %s", got)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	viper.Set("redact.patterns", []string{`[a-z0-9.-]+\.corp\.local`})
+	viper.Set("redact.fields", []string{"Password"})
+	defer viper.Set("redact.patterns", nil)
+	defer viper.Set("redact.fields", nil)
+	r, err := newRedactor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.redact("json", []byte(`[{"type":"INTERNET_NAME","data":"db1.corp.local and web.corp.local","risk":3,"password":"hunter2"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []map[string]interface{}
+	if err := json.Unmarshal(got, &events); err != nil {
+		t.Fatal(err)
+	}
+	if events[0]["data"] != "[REDACTED] and [REDACTED]" || events[0]["password"] != "[REDACTED]" || events[0]["risk"] != float64(3) {
+		t.Errorf("redacted JSON = %s", got)
+	}
+
+	got, err = r.redact("csv", []byte("type,data,password\nINTERNET_NAME,app.corp.local,secret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "type,data,password\nINTERNET_NAME,[REDACTED],[REDACTED]\n"; string(got) != want {
+		t.Errorf("redacted CSV = %q, want %q", got, want)
+	}
+	if n := r.count.Load(); n != 5 {
+		t.Errorf("count = %d, want 5", n)
+	}
+}
+
+func TestCheckCredential(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer stale" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-API-Key") == "good" {
+			w.Write([]byte(`{"authenticated": true, "user": {"username": "alice"}}`))
+			return
+		}
+		w.Write([]byte(`{"authenticated": false}`))
+	}))
+	defer srv.Close()
+
+	token := checkCredential(&client.Client{BaseURL: srv.URL, Token: "stale", HTTPClient: srv.Client()}, "token", true)
+	key := checkCredential(&client.Client{BaseURL: srv.URL, APIKey: "good", HTTPClient: srv.Client()}, "api_key", true)
+	if token.Valid || token.Result != "rejected" {
+		t.Errorf("token check = %+v", token)
+	}
+	if !key.Valid || key.User != "alice" {
+		t.Errorf("api_key check = %+v", key)
+	}
+	if advice := authAdvice(token, key); !strings.Contains(advice, "stale") {
+		t.Errorf("authAdvice() = %q", advice)
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/spiderfoot/spiderfoot-cli/internal/client"
 	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
@@ -32,10 +39,29 @@ type scanSummary struct {
 	Status    string  `json:"status"`
 	StartedAt float64 `json:"started"`
 	EndedAt   float64 `json:"ended"`
+
+	// EventCount is only filled in when the event_count column is selected,
+	// since the list endpoint does not report it.
+	EventCount *int `json:"event_count,omitempty"`
+	// Reason is only filled in for failed scans with --show-reason.
+	Reason string `json:"reason,omitempty"`
 }
 
 type scansResp struct {
 	Scans []scanSummary `json:"scans"`
+	// Items, Total and HasNext are set by servers that paginate the scan
+	// list, which return the scans as items.
+	Items   []scanSummary `json:"items,omitempty"`
+	Total   *int          `json:"total,omitempty"`
+	HasNext *bool         `json:"has_next,omitempty"`
+}
+
+// list returns the scans of the response, whichever field holds them.
+func (r scansResp) list() []scanSummary {
+	if r.Items != nil {
+		return r.Items
+	}
+	return r.Scans
 }
 
 type scanDetail struct {
@@ -52,10 +78,12 @@ type scanDetail struct {
 }
 
 type scanStartReq struct {
-	Target   string   `json:"target"`
-	ScanName string   `json:"scan_name"`
-	ScanType string   `json:"scan_type"`
-	Modules  []string `json:"modules,omitempty"`
+	Target   string                 `json:"target"`
+	ScanName string                 `json:"scan_name"`
+	ScanType string                 `json:"scan_type"`
+	Modules  []string               `json:"modules,omitempty"`
+	Config   map[string]interface{} `json:"config,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
 }
 
 // --- Commands ---
@@ -69,34 +97,496 @@ var scanListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all scans",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+
+		now := time.Now()
+		var since, until time.Time
+		var err error
+		if sinceStr != "" {
+			if since, err = parseTimeBound(sinceStr, now); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+		}
+		if untilStr != "" {
+			if until, err = parseTimeBound(untilStr, now); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+		}
+
+		// --after and --sync return only scans started or ended since a
+		// point in time; --sync remembers that point per server.
+		afterStr, _ := cmd.Flags().GetString("after")
+		syncMode, _ := cmd.Flags().GetBool("sync")
+		server := strings.TrimRight(viper.GetString("server"), "/")
+		var after float64
+		if afterStr != "" {
+			t, err := parseTimeBound(afterStr, now)
+			if err != nil {
+				return fmt.Errorf("invalid --after: %w", err)
+			}
+			after = float64(t.UnixNano()) / float64(time.Second)
+		} else if syncMode {
+			marks, err := loadSyncWatermarks()
+			if err != nil {
+				return err
+			}
+			after = marks[server]
+		}
+
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if watch && (afterStr != "" || syncMode) {
+			return fmt.Errorf("--after and --sync cannot be combined with --watch")
+		}
+		tmpl, _ := cmd.Flags().GetString("template")
+		if tmpl != "" && watch {
+			return fmt.Errorf("--template cannot be combined with --watch")
+		}
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		totals, _ := cmd.Flags().GetBool("totals")
+		switch {
+		case groupBy != "" && groupBy != "target":
+			return fmt.Errorf("invalid --group-by %q: only \"target\" is supported", groupBy)
+		case groupBy != "" && (watch || tmpl != ""):
+			return fmt.Errorf("--group-by cannot be combined with --watch or --template")
+		}
+
+		cols, err := fieldColumns("scan_list", scanListHeader, scanListKeys)
+		if err != nil {
+			return err
+		}
+		withCounts := hasColumn(cols, scanListEventCount)
+		if cols == nil && !output.Structured() {
+			cols = scanListDefaultColumns
+		}
+
+		statuses, err := scanStatuses(cmd)
+		if err != nil {
+			return err
+		}
+		showReason, _ := cmd.Flags().GetBool("show-reason")
+		if showReason && cols != nil && !hasColumn(cols, scanListReason) {
+			cols = append(cols[:len(cols):len(cols)], scanListReason)
+		}
+		showReason = showReason || hasColumn(cols, scanListReason)
+		if watch && (len(statuses) > 0 || showReason) {
+			return fmt.Errorf("--status, --failed and --show-reason cannot be combined with --watch")
+		}
+		// --limit and --offset fetch one page of scans rather than all of them.
+		limit, _ := cmd.Flags().GetInt("limit")
+		offset, _ := cmd.Flags().GetInt("offset")
+		paged := cmd.Flags().Changed("limit") || cmd.Flags().Changed("offset")
+		// The server answers a larger page size with 422.
+		limit = min(limit, maxScanPageSize)
+		switch {
+		case limit < 0 || offset < 0:
+			return fmt.Errorf("--limit and --offset must not be negative")
+		case paged && (watch || afterStr != "" || syncMode):
+			return fmt.Errorf("--limit and --offset cannot be combined with --watch, --after or --sync")
+		}
+
 		c := client.New()
-		var resp scansResp
-		if err := c.Get("/api/scans", &resp); err != nil {
+		if watch {
+			if output.Current() != output.Table {
+				return fmt.Errorf("--watch requires table output")
+			}
+			if offline, _ := cmd.Flags().GetBool("offline"); offline {
+				return fmt.Errorf("--watch cannot be combined with --offline")
+			}
+			return watchScanList(c, since, until, interval, cols, withCounts, totals)
+		}
+		if c.Cache, err = responseCache(cmd); err != nil {
 			return err
 		}
 
+		var page *scanPage
+		var scans []scanSummary
+		if paged {
+			page, err = fetchScanPage(c, scanStatusQuery(statuses), limit, offset)
+			if err == nil {
+				scans = filterScansByWindow(page.Scans, since, until)
+			}
+		} else {
+			scans, err = fetchScanListQuery(c, scanStatusQuery(statuses), since, until)
+		}
+		if err != nil {
+			return err
+		}
+		if afterStr != "" || syncMode {
+			scans, _ = scansChangedAfter(scans, after)
+		}
+		// Servers that ignore status= return every scan, so filter here too.
+		returned := len(scans)
+		scans = filterScansByStatus(scans, statuses)
+		// The watermark only covers the scans printed, so --status does not
+		// move it past scans the next sync should still return.
+		var mark float64
+		if syncMode {
+			_, mark = scansChangedAfter(scans, after)
+		}
+		if withCounts {
+			fillEventCounts(c, scans)
+		}
+		if showReason {
+			fillFailureReasons(c, scans)
+		}
+		// The watermark moves only once the scans have been printed.
+		commitSync := func() error {
+			if syncMode && mark > after {
+				return saveSyncWatermark(server, mark)
+			}
+			return nil
+		}
+
+		if tmpl != "" {
+			out, err := renderRowTemplate(tmpl, scans)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return commitSync()
+		}
+
+		var groups []scanGroup
+		if groupBy != "" {
+			groups = groupScansByTarget(scans)
+		}
+
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp.Scans)
+		case output.JSON, output.YAML:
+			var v interface{} = selectJSONFields(scans, scanListKeys, cols)
+			if groups != nil {
+				byTarget := make(map[string]interface{}, len(groups))
+				for _, g := range groups {
+					byTarget[g.Target] = selectJSONFields(g.Scans, scanListKeys, cols)
+				}
+				v = byTarget
+			}
+			// A page keeps the server's envelope (total, has_next, ...).
+			if page != nil {
+				page.Envelope[page.Field] = v
+				v = page.Envelope
+			}
+			output.PrintStructured(v)
 		case output.CSV:
-			header := []string{"ID", "Name", "Target", "Status", "Started"}
-			rows := make([][]string, 0, len(resp.Scans))
-			for _, s := range resp.Scans {
-				rows = append(rows, []string{s.ScanID, s.Name, s.Target, s.Status, formatEpoch(s.StartedAt)})
+			if groups != nil {
+				// CSV stays flat; grouping orders the rows by target.
+				scans = scans[:0]
+				for _, g := range groups {
+					scans = append(scans, g.Scans...)
+				}
+			}
+			rows := make([][]string, 0, len(scans))
+			for _, s := range scans {
+				rows = append(rows, []string{s.ScanID, s.Name, s.Target, s.Status, formatEpoch(s.StartedAt), eventCountCell(s), s.Reason})
 			}
-			output.PrintCSV(header, rows)
+			output.PrintCSV(selectColumns(scanListHeader, rows, cols))
 		default:
-			header := []string{"ID", "Name", "Target", "Status", "Started"}
-			rows := make([][]string, 0, len(resp.Scans))
-			for _, s := range resp.Scans {
-				rows = append(rows, []string{truncID(s.ScanID), s.Name, s.Target, colorStatus(s.Status), formatEpoch(s.StartedAt)})
+			if groups != nil {
+				printScanGroups(groups, cols, totals)
+			} else {
+				printScanTable(scans, nil, cols, totals)
+			}
+			if len(statuses) > 0 {
+				output.Note("%d of %d scans matched --status %s", len(scans), returned, strings.Join(statuses, ","))
+			}
+			if page != nil {
+				footer, hint := scanPageFooter(page, limit, offset)
+				if footer != "" {
+					output.Note("%s", footer)
+				}
+				if hint != "" {
+					output.Note("%s", hint)
+				}
 			}
-			output.PrintTable(header, rows)
 		}
-		return nil
+		return commitSync()
 	},
 }
 
+// scanListHeader and scanListKeys are the scan list columns and their JSON
+// field names, as accepted by --fields. The Events and Reason columns cost
+// requests per scan, so they are shown only when asked for.
+var (
+	scanListHeader         = []string{"ID", "Name", "Target", "Status", "Started", "Events", "Reason"}
+	scanListKeys           = []string{"scan_id", "name", "target", "status", "started", "event_count", "reason"}
+	scanListDefaultColumns = []int{0, 1, 2, 3, 4}
+)
+
+// Indexes of the Target, Events and Reason columns.
+const (
+	scanListTarget     = 2
+	scanListEventCount = 5
+	scanListReason     = 6
+)
+
+// hasColumn reports whether cols includes col.
+func hasColumn(cols []int, col int) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// fillEventCounts sets EventCount on each scan from its detail, fetching at
+// most resolveConcurrency at a time. Scans whose detail cannot be fetched are
+// left without a count.
+func fillEventCounts(c *client.Client, scans []scanSummary) {
+	sem := make(chan struct{}, resolveConcurrency)
+	var wg sync.WaitGroup
+	for i := range scans {
+		if validateSafeID(scans[i].ScanID, "scan ID") != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(s *scanSummary) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var detail scanDetail
+			if err := c.Get("/api/scans/"+s.ScanID, &detail); err != nil {
+				return
+			}
+			s.EventCount = &detail.EventCount
+		}(&scans[i])
+	}
+	wg.Wait()
+}
+
+// eventCountCell formats a scan's event count, which is blank when unknown.
+func eventCountCell(s scanSummary) string {
+	if s.EventCount == nil {
+		return ""
+	}
+	return strconv.Itoa(*s.EventCount)
+}
+
+// fetchScanList retrieves all scans, keeping those started within [since, until].
+// A zero bound is ignored.
+func fetchScanList(c *client.Client, since, until time.Time) ([]scanSummary, error) {
+	return fetchScanListQuery(c, nil, since, until)
+}
+
+// fetchScanListQuery is fetchScanList with query parameters for the server.
+// A server that paginates is asked for its largest pages, by offset, for as
+// long as it reports has_next.
+func fetchScanListQuery(c *client.Client, query url.Values, since, until time.Time) ([]scanSummary, error) {
+	var scans []scanSummary
+	for {
+		params := url.Values{}
+		for k, v := range query {
+			params[k] = v
+		}
+		params.Set("limit", strconv.Itoa(maxScanPageSize))
+		if len(scans) > 0 {
+			params.Set("offset", strconv.Itoa(len(scans)))
+		}
+		var resp scansResp
+		if err := c.Get("/api/scans?"+params.Encode(), &resp); err != nil {
+			return nil, err
+		}
+		items := resp.list()
+		scans = append(scans, items...)
+		// A server that ignores offset would otherwise be paged forever.
+		if resp.HasNext == nil || !*resp.HasNext || len(items) == 0 || resp.Total != nil && len(scans) >= *resp.Total {
+			break
+		}
+		if err := commandContext().Err(); err != nil {
+			return nil, err
+		}
+	}
+	return filterScansByWindow(scans, since, until), nil
+}
+
+// filterScansByWindow keeps the scans started within [since, until]. A zero
+// bound is ignored.
+func filterScansByWindow(scans []scanSummary, since, until time.Time) []scanSummary {
+	if since.IsZero() && until.IsZero() {
+		return scans
+	}
+
+	filtered := scans[:0]
+	for _, s := range scans {
+		started := time.Unix(int64(s.StartedAt), 0)
+		if !since.IsZero() && started.Before(since) {
+			continue
+		}
+		if !until.IsZero() && started.After(until) {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	return filtered
+}
+
+// scanFinishedAt returns when a scan ended, falling back to its start time.
+func scanFinishedAt(s scanSummary) float64 {
+	if s.EndedAt > 0 {
+		return s.EndedAt
+	}
+	return s.StartedAt
+}
+
+// recentScan returns the most recent finished scan of target that ended at or
+// after since, or nil if there is none.
+func recentScan(scans []scanSummary, target string, since time.Time) *scanSummary {
+	var best *scanSummary
+	for i, s := range scans {
+		status := strings.ToUpper(s.Status)
+		if status != "FINISHED" && status != "COMPLETED" {
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(s.Target), target) {
+			continue
+		}
+		if time.Unix(int64(scanFinishedAt(s)), 0).Before(since) {
+			continue
+		}
+		if best == nil || scanFinishedAt(s) > scanFinishedAt(*best) {
+			best = &scans[i]
+		}
+	}
+	return best
+}
+
+// scanGroup is the scans of one target, newest first.
+type scanGroup struct {
+	Target string
+	Scans  []scanSummary
+}
+
+// groupScansByTarget groups scans by target, ignoring case and surrounding
+// space. Groups are ordered by target and each lists its scans newest first,
+// so the first scan holds the target's latest status.
+func groupScansByTarget(scans []scanSummary) []scanGroup {
+	index := make(map[string]int)
+	var groups []scanGroup
+	for _, s := range scans {
+		key := strings.ToLower(strings.TrimSpace(s.Target))
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, scanGroup{Target: strings.TrimSpace(s.Target)})
+		}
+		groups[i].Scans = append(groups[i].Scans, s)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].Target) < strings.ToLower(groups[j].Target)
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.Scans, func(i, j int) bool { return g.Scans[i].StartedAt > g.Scans[j].StartedAt })
+	}
+	return groups
+}
+
+// printScanGroups prints a header per target with its latest status, and its
+// scans beneath without the repeated Target column.
+func printScanGroups(groups []scanGroup, cols []int, totals bool) {
+	nested := []int{}
+	for _, c := range cols {
+		if c != scanListTarget {
+			nested = append(nested, c)
+		}
+	}
+	if len(nested) == 0 {
+		nested = cols
+	}
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s  %d scan(s), latest %s\n", color.New(color.Bold).Sprint(g.Target), len(g.Scans), colorStatus(g.Scans[0].Status))
+		printScanTable(g.Scans, nil, nested, totals)
+	}
+}
+
+// printScanTable renders the cols columns of scans as a table. Scans whose IDs
+// are in changed are marked and highlighted. With totals, a footer counts the
+// scans and sums their known event counts.
+func printScanTable(scans []scanSummary, changed map[string]bool, cols []int, totals bool) {
+	rows := make([][]string, 0, len(scans))
+	for _, s := range scans {
+		id := truncID(s.ScanID)
+		if changed[s.ScanID] {
+			id = color.New(color.ReverseVideo).Sprint("* " + id)
+		}
+		rows = append(rows, []string{id, s.Name, s.Target, colorStatus(s.Status), formatEpoch(s.StartedAt), eventCountCell(s), s.Reason})
+	}
+	if !totals {
+		output.PrintTable(selectColumns(scanListHeader, rows, cols))
+		return
+	}
+	events, counted := 0, false
+	for _, s := range scans {
+		if s.EventCount != nil {
+			events += *s.EventCount
+			counted = true
+		}
+	}
+	footer := make([]string, len(scanListHeader))
+	if counted {
+		footer[scanListEventCount] = strconv.Itoa(events)
+	}
+	header, rows := selectColumns(scanListHeader, append(rows, footer), cols)
+	output.PrintTableFooter(header, rows[:len(rows)-1], [][]string{totalsLabel(rows[len(rows)-1], countLabel(len(scans), "scan"))})
+}
+
+// watchScanList redraws the scan table every interval until interrupted,
+// highlighting scans whose status changed since the previous refresh.
+func watchScanList(c *client.Client, since, until time.Time, interval time.Duration, cols []int, withCounts, totals bool) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ctx := commandContext()
+
+	// Redraw in place on an interactive terminal; otherwise (piped, CI,
+	// --no-progress) print each refresh after the previous one.
+	live := output.Interactive(os.Stdout)
+	if live {
+		// Hide the cursor while redrawing and always restore it on exit.
+		fmt.Print("\033[?25l")
+		defer fmt.Print("\033[?25h")
+	}
+
+	prev := make(map[string]string)
+	for refresh := 0; ; refresh++ {
+		scans, err := fetchScanList(c, since, until)
+		if err != nil {
+			return err
+		}
+		if withCounts {
+			fillEventCounts(c, scans)
+		}
+		changed := make(map[string]bool)
+		for _, s := range scans {
+			if old, ok := prev[s.ScanID]; ok && old != s.Status {
+				changed[s.ScanID] = true
+			}
+			prev[s.ScanID] = s.Status
+		}
+
+		if live {
+			fmt.Print("\033[H\033[2J")
+		} else if refresh > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Every %s — updated %s (Ctrl-C to exit)\n\n", interval, time.Now().Format("15:04:05"))
+		printScanTable(scans, changed, cols, totals)
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
 var scanGetCmd = &cobra.Command{
 	Use:   "get [scan-id]",
 	Short: "Get scan details",
@@ -106,31 +596,152 @@ var scanGetCmd = &cobra.Command{
 			return err
 		}
 		c := client.New()
+		if followLogs, _ := cmd.Flags().GetBool("follow-logs"); followLogs {
+			if output.Current() != output.Table {
+				return fmt.Errorf("--follow-logs requires table output")
+			}
+			if cmd.Flags().Changed("include-config") || cmd.Flags().Changed("json-path") {
+				return fmt.Errorf("--follow-logs cannot be combined with --include-config or --json-path")
+			}
+			interval, _ := cmd.Flags().GetDuration("interval")
+			return followScanLogs(c, args[0], interval)
+		}
+		cache, err := responseCache(cmd)
+		if err != nil {
+			return err
+		}
+		c.Cache = cache
 		var s scanDetail
 		if err := c.Get(fmt.Sprintf("/api/scans/%s", args[0]), &s); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
+		}
+		var cfg *scanConfig
+		if includeConfig, _ := cmd.Flags().GetBool("include-config"); includeConfig {
+			var resp scanOptionsResp
+			if err := c.Get(fmt.Sprintf("/api/scans/%s/options", args[0]), &resp); err != nil {
+				return notFound(err, "scan", args[0])
+			}
+			cfg = resp.scanConfig()
+		}
+		detail := struct {
+			scanDetail
+			Config *scanConfig `json:"config,omitempty"`
+		}{s, cfg}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(detail, path)
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(s)
+		case output.JSON, output.YAML:
+			output.PrintStructured(detail)
 		default:
-			fmt.Printf("Scan ID:       %s\n", s.ScanID)
-			fmt.Printf("Name:          %s\n", s.Name)
-			fmt.Printf("Target:        %s\n", s.Target)
-			fmt.Printf("Status:        %s\n", colorStatus(s.Status))
-			fmt.Printf("Progress:      %d%%\n", s.Progress)
-			fmt.Printf("Modules:       %d / %d\n", s.ModulesDone, s.ModulesTotal)
-			fmt.Printf("Events:        %d\n", s.EventCount)
-			fmt.Printf("Started:       %s\n", formatEpoch(s.StartedAt))
-			if s.EndedAt > 0 {
-				fmt.Printf("Ended:         %s\n", formatEpoch(s.EndedAt))
+			printScanDetail(os.Stdout, s)
+			if cfg != nil {
+				printScanConfig(cfg)
 			}
 		}
 		return nil
 	},
 }
 
+// printScanDetail writes the human-readable scan detail block to w.
+func printScanDetail(w io.Writer, s scanDetail) {
+	fmt.Fprintf(w, "Scan ID:       %s\n", s.ScanID)
+	fmt.Fprintf(w, "Name:          %s\n", s.Name)
+	fmt.Fprintf(w, "Target:        %s\n", s.Target)
+	fmt.Fprintf(w, "Status:        %s\n", colorStatus(s.Status))
+	fmt.Fprintf(w, "Progress:      %s\n", output.ProgressBar(s.Progress, 20))
+	fmt.Fprintf(w, "Modules:       %d / %d\n", s.ModulesDone, s.ModulesTotal)
+	fmt.Fprintf(w, "Events:        %d\n", s.EventCount)
+	fmt.Fprintf(w, "Started:       %s\n", formatEpoch(s.StartedAt))
+	if s.EndedAt > 0 {
+		fmt.Fprintf(w, "Ended:         %s\n", formatEpoch(s.EndedAt))
+	}
+	if label, d, ok := scanElapsed(s, time.Now()); ok {
+		fmt.Fprintf(w, "%-15s%s\n", label+":", humanDuration(d))
+	}
+}
+
+// scanElapsed returns how long an active scan has been running ("Running
+// for") or how long a finished one took ("Duration"). ok is false when the
+// timestamps needed are missing.
+func scanElapsed(s scanDetail, now time.Time) (label string, d time.Duration, ok bool) {
+	if s.StartedAt <= 0 {
+		return "", 0, false
+	}
+	started := time.Unix(0, int64(s.StartedAt*float64(time.Second)))
+	switch {
+	case s.EndedAt >= s.StartedAt:
+		return "Duration", time.Unix(0, int64(s.EndedAt*float64(time.Second))).Sub(started), true
+	case !scanDone(s.Status) && now.After(started):
+		return "Running for", now.Sub(started), true
+	}
+	return "", 0, false
+}
+
+// humanDuration renders d to the second in its two largest units, e.g. "45s",
+// "12m34s", "3h05m" or "2d4h".
+func humanDuration(d time.Duration) string {
+	secs := int64(d.Round(time.Second) / time.Second)
+	switch {
+	case secs < 60:
+		return fmt.Sprintf("%ds", secs)
+	case secs < 3600:
+		return fmt.Sprintf("%dm%02ds", secs/60, secs%60)
+	case secs < 86400:
+		return fmt.Sprintf("%dh%02dm", secs/3600, secs%3600/60)
+	}
+	return fmt.Sprintf("%dd%dh", secs/86400, secs%86400/3600)
+}
+
+// scanOptionsResp is the response of GET /api/scans/{id}/options. Config
+// holds the options the scan ran with; "_modulesenabled" lists its modules.
+type scanOptionsResp struct {
+	Config map[string]interface{} `json:"config"`
+}
+
+type scanConfig struct {
+	Modules []string               `json:"modules"`
+	Options map[string]interface{} `json:"options"`
+}
+
+// scanConfig splits the stored scan options into the module list and the
+// remaining global ("_name") and module ("sfp_x:name") options.
+func (r scanOptionsResp) scanConfig() *scanConfig {
+	cfg := &scanConfig{Modules: []string{}, Options: make(map[string]interface{})}
+	for k, v := range r.Config {
+		if k != "_modulesenabled" {
+			cfg.Options[k] = v
+			continue
+		}
+		for _, m := range strings.Split(fmt.Sprint(v), ",") {
+			if m = strings.TrimSpace(m); m != "" && m != "sfp__stor_stdout" {
+				cfg.Modules = append(cfg.Modules, m)
+			}
+		}
+	}
+	sort.Strings(cfg.Modules)
+	return cfg
+}
+
+func printScanConfig(cfg *scanConfig) {
+	fmt.Printf("\nModules (%d):\n", len(cfg.Modules))
+	for _, m := range cfg.Modules {
+		fmt.Printf("  %s\n", m)
+	}
+	keys := make([]string, 0, len(cfg.Options))
+	for k := range cfg.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Printf("\nOptions (%d):\n", len(keys))
+	rows := make([][]string, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, []string{k, truncateCell(fmt.Sprint(cfg.Options[k]), 60)})
+	}
+	output.PrintTable([]string{"Option", "Value"}, rows)
+}
+
 var scanStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start a new scan",
@@ -139,10 +750,78 @@ var scanStartCmd = &cobra.Command{
 		name, _ := cmd.Flags().GetString("name")
 		scanType, _ := cmd.Flags().GetString("type")
 		modules, _ := cmd.Flags().GetString("modules")
+		targetType, _ := cmd.Flags().GetString("target-type")
+		noValidate, _ := cmd.Flags().GetBool("no-validate")
+
+		categories, _ := cmd.Flags().GetString("module-categories")
+		excluded, _ := cmd.Flags().GetString("exclude-modules")
+
+		wait, _ := cmd.Flags().GetBool("wait")
+		exportFormat, _ := cmd.Flags().GetString("export")
+		exportFile, _ := cmd.Flags().GetString("file")
+		switch {
+		case exportFormat != "" && !wait:
+			return fmt.Errorf("--export requires --wait")
+		case exportFile != "" && exportFormat == "":
+			return fmt.Errorf("--file requires --export")
+		case exportFormat != "" && exportFormats[exportFormat] == "":
+			return fmt.Errorf("invalid --export %q (valid: csv, json, pdf, sarif, sqlite, stix)", exportFormat)
+		}
+		notifier, err := newScanNotifier(cmd)
+		if err != nil {
+			return err
+		}
+		if notifier != nil && !wait {
+			return fmt.Errorf("--notify-webhook requires --wait")
+		}
+
+		fromScan, _ := cmd.Flags().GetString("from-scan")
+		if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+			if fromScan != "" {
+				return fmt.Errorf("--stdin cannot be combined with --from-scan")
+			}
+			if wait {
+				return fmt.Errorf("--stdin cannot be combined with --wait")
+			}
+			if target != "" {
+				return fmt.Errorf("--stdin cannot be combined with --target")
+			}
+			if categories != "" || excluded != "" {
+				return fmt.Errorf("--stdin cannot be combined with --module-categories or --exclude-modules")
+			}
+			if estimate, _ := cmd.Flags().GetBool("estimate"); estimate {
+				return fmt.Errorf("--stdin cannot be combined with --estimate")
+			}
+			return startScansFromStdin(cmd, client.New(), os.Stdin)
+		}
 
+		c := client.New()
+		var base *scanConfig
+		if fromScan != "" {
+			from, cfg, err := fetchScanBase(c, fromScan)
+			if err != nil {
+				return err
+			}
+			base = cfg
+			if target == "" {
+				target = from.Target
+			}
+			if name == "" {
+				name = from.Name
+			}
+		}
 		if target == "" {
 			return fmt.Errorf("--target is required")
 		}
+		if targetType != "" && !validTargetType(targetType) {
+			return fmt.Errorf("invalid --target-type %q (valid: %s)", targetType, strings.Join(targetTypes, ", "))
+		}
+		if !noValidate {
+			for _, w := range validateTarget(target, targetType) {
+				output.Warn("%s", w)
+			}
+			target = strings.TrimSpace(target)
+		}
 		if name == "" {
 			name = "CLI scan: " + target
 		}
@@ -156,19 +835,94 @@ var scanStartCmd = &cobra.Command{
 			body.Modules = strings.Split(modules, ",")
 		}
 
+		body.Config = scanTuningConfig(cmd)
+		body.Tags = scanTags(cmd)
+		if base != nil {
+			// The inherited scan is only a base: any flag given overrides it.
+			if modules == "" && categories == "" && len(base.Modules) > 0 {
+				body.Modules = base.Modules
+			}
+			body.Config = mergeScanConfig(base.Options, body.Config)
+		}
+
+		if categories != "" || excluded != "" {
+			if len(body.Modules) == 0 && categories == "" {
+				return fmt.Errorf("--exclude-modules needs --modules, --module-categories or --from-scan")
+			}
+			var fromCategories []string
+			if categories != "" {
+				available, err := fetchModules(c, "")
+				if err != nil {
+					return fmt.Errorf("fetching modules for --module-categories: %w", err)
+				}
+				if fromCategories, err = modulesInCategories(available, categories); err != nil {
+					return err
+				}
+			}
+			body.Modules = combineModules(body.Modules, fromCategories, strings.Split(excluded, ","))
+			if len(body.Modules) == 0 {
+				return fmt.Errorf("no modules left to run after --exclude-modules")
+			}
+		}
+		if recent, _ := cmd.Flags().GetString("skip-if-recent"); recent != "" {
+			since, err := parseTimeBound(recent, time.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --skip-if-recent: %w", err)
+			}
+			scans, err := fetchScanList(c, time.Time{}, time.Time{})
+			if err != nil {
+				return err
+			}
+			if existing := recentScan(scans, target, since); existing != nil {
+				switch output.Current() {
+				case output.JSON, output.YAML:
+					output.PrintStructured(map[string]interface{}{"skipped": true, "scan_id": existing.ScanID})
+				default:
+					output.Warn("Skipped: scan %s for %s finished %s", existing.ScanID, existing.Target, formatEpoch(scanFinishedAt(*existing)))
+				}
+				return nil
+			}
+		}
+		if estimate, _ := cmd.Flags().GetBool("estimate"); estimate {
+			available, err := fetchModules(c, "")
+			if err != nil {
+				return fmt.Errorf("fetching modules for estimate: %w", err)
+			}
+			threads, _ := cmd.Flags().GetInt("max-threads")
+			est := estimateScan(available, scanType, body.Modules, threads)
+			fmt.Fprintf(os.Stderr, "Estimate: %d modules (%d active), roughly %s to %s\n",
+				est.Modules, est.Active, formatMinutes(est.MinMinutes), formatMinutes(est.MaxMinutes))
+			if !confirm("Start scan?") {
+				return fmt.Errorf("scan not started")
+			}
+		}
+
 		payload, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("marshaling request: %w", err)
 		}
-		c := client.New()
 		var resp map[string]interface{}
 		if err := c.Post("/api/scans", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
 
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["scan_id"]))
+
+		if wait {
+			id, ok := resp["scan_id"].(string)
+			if !ok {
+				return fmt.Errorf("server did not return a scan ID to wait for")
+			}
+			if !output.Structured() {
+				output.Success("Scan started: %s", id)
+			}
+			interval, _ := cmd.Flags().GetDuration("interval")
+			return waitAndExport(c, id, interval, exportFormat, exportFile, notifier)
+		}
+
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if id, ok := resp["scan_id"]; ok {
 				output.Success("Scan started: %v", id)
@@ -181,6 +935,83 @@ var scanStartCmd = &cobra.Command{
 	},
 }
 
+// waitAndExport polls a scan every interval until it ends and reports the
+// outcome to notifier, if set. A scan that finished successfully is then
+// exported in format to file, if a format is given; any other outcome skips
+// the export and is returned as an error. In JSON mode the final scan detail
+// is printed.
+func waitAndExport(c *client.Client, id string, interval time.Duration, format, file string, notifier *scanNotifier) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ctx := commandContext()
+	stop := output.StartSpinner(fmt.Sprintf("Waiting for scan %s to finish...", id))
+	s, err := watchScan(ctx, c, id, interval, 0, func(scanDetail) {})
+	stop()
+	if err != nil {
+		return err
+	}
+
+	if notifier != nil {
+		notifier.notify(ctx, s)
+	}
+	if output.Structured() {
+		output.PrintStructured(s)
+	}
+	if status := strings.ToUpper(s.Status); status != "FINISHED" && status != "COMPLETED" {
+		if format != "" {
+			output.Warn("Skipping export of scan %s", id)
+		}
+		return fmt.Errorf("scan %s ended with status %s", id, s.Status)
+	}
+	if !output.Structured() {
+		output.Success("Scan %s %s", id, s.Status)
+	}
+	if format == "" {
+		return nil
+	}
+	return doExport(id, format, exportFormats[format], file, nil)
+}
+
+// scanTags returns the --tags list, trimmed, lowercased and de-duplicated the
+// way the server stores tags, or nil if none were given.
+func scanTags(cmd *cobra.Command) []string {
+	raw, _ := cmd.Flags().GetString("tags")
+	var tags []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// scanTuningConfig returns the scan config options set by tuning flags, or
+// nil if none were given. Only options the user explicitly set are sent.
+func scanTuningConfig(cmd *cobra.Command) map[string]interface{} {
+	config := make(map[string]interface{})
+	if cmd.Flags().Changed("max-threads") {
+		v, _ := cmd.Flags().GetInt("max-threads")
+		config["max_threads"] = v
+	}
+	if cmd.Flags().Changed("dedupe") {
+		v, _ := cmd.Flags().GetBool("dedupe")
+		config["dedupe"] = v
+	}
+	if cmd.Flags().Changed("timeout-minutes") {
+		v, _ := cmd.Flags().GetInt("timeout-minutes")
+		config["timeout_minutes"] = v
+	}
+	if len(config) == 0 {
+		return nil
+	}
+	return config
+}
+
 var scanStopCmd = &cobra.Command{
 	Use:   "stop [scan-id]",
 	Short: "Stop a running scan",
@@ -191,8 +1022,9 @@ var scanStopCmd = &cobra.Command{
 		}
 		c := client.New()
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/stop", args[0]), nil, nil); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Scan %s stopped", args[0])
 		return nil
 	},
@@ -208,65 +1040,14 @@ var scanDeleteCmd = &cobra.Command{
 		}
 		c := client.New()
 		if err := c.Delete(fmt.Sprintf("/api/scans/%s", args[0]), nil); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Scan %s deleted", args[0])
 		return nil
 	},
 }
 
-var scanEventsCmd = &cobra.Command{
-	Use:   "events [scan-id]",
-	Short: "List events collected in a scan",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := validateSafeID(args[0], "scan ID"); err != nil {
-			return err
-		}
-		c := client.New()
-		eventType, _ := cmd.Flags().GetString("type")
-		limit, _ := cmd.Flags().GetInt("limit")
-
-		path := fmt.Sprintf("/api/scans/%s/events?limit=%d", args[0], limit)
-		if eventType != "" {
-			path += "&type=" + eventType
-		}
-
-		var resp interface{}
-		if err := c.Get(path, &resp); err != nil {
-			return err
-		}
-
-		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
-		default:
-			if items, ok := resp.([]interface{}); ok {
-				header := []string{"Type", "Module", "Data", "Source"}
-				rows := make([][]string, 0, len(items))
-				for _, item := range items {
-					if m, ok := item.(map[string]interface{}); ok {
-						data := fmt.Sprintf("%v", m["data"])
-						if len(data) > 60 {
-							data = data[:57] + "..."
-						}
-						rows = append(rows, []string{
-							fmt.Sprintf("%v", m["type"]),
-							fmt.Sprintf("%v", m["module"]),
-							data,
-							fmt.Sprintf("%v", m["source"]),
-						})
-					}
-				}
-				output.PrintTable(header, rows)
-			} else {
-				printGenericResponse(resp)
-			}
-		}
-		return nil
-	},
-}
-
 var scanCorrelationsCmd = &cobra.Command{
 	Use:   "correlations [scan-id]",
 	Short: "Show correlations found in a scan",
@@ -278,12 +1059,12 @@ var scanCorrelationsCmd = &cobra.Command{
 		c := client.New()
 		var resp interface{}
 		if err := c.Get(fmt.Sprintf("/api/scans/%s/correlations", args[0]), &resp); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"Rule", "Severity", "Title", "Entities"}
@@ -322,6 +1103,15 @@ func colorStatus(s string) string {
 	}
 }
 
+// scanDone reports whether a scan status is final.
+func scanDone(status string) bool {
+	switch strings.ToUpper(status) {
+	case "FINISHED", "COMPLETED", "ABORTED", "FAILED", "ERROR", "ERROR-FAILED", "STOPPED":
+		return true
+	}
+	return false
+}
+
 func truncID(id string) string {
 	if len(id) > 12 {
 		return id[:12]
@@ -337,6 +1127,46 @@ func formatEpoch(epoch float64) string {
 	return t.Local().Format("2006-01-02 15:04")
 }
 
+// parseTimeBound parses an absolute time (RFC3339 or YYYY-MM-DD, local time)
+// or a relative duration before now such as "90m", "24h", "7d" or "2w".
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := parseWindow(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a valid time or duration (use RFC3339, YYYY-MM-DD, or e.g. 24h, 7d)", s)
+	}
+	return now.Add(-d), nil
+}
+
+// parseWindow parses a non-negative duration: a Go duration such as 90m or
+// 24h, or a whole number of days or weeks such as 7d or 2w.
+func parseWindow(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a valid duration", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q is not a valid duration (use e.g. 90m, 24h, 7d)", s)
+	}
+	return d, nil
+}
+
 // --- Additional scan subcommands matching real API ---
 
 var scanSearchCmd = &cobra.Command{
@@ -365,8 +1195,8 @@ var scanSearchCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -391,8 +1221,8 @@ var scanSummaryCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -411,17 +1241,15 @@ var scanLogsCmd = &cobra.Command{
 		c := client.New()
 		var resp interface{}
 		if err := c.Get(fmt.Sprintf("/api/scans/%s/logs", args[0]), &resp); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
-			if items, ok := resp.([]interface{}); ok {
-				for _, item := range items {
-					if m, ok := item.(map[string]interface{}); ok {
-						fmt.Printf("[%v] %v: %v\n", m["timestamp"], m["level"], m["message"])
-					}
+			if items, ok := logItems(resp); ok {
+				for _, m := range items {
+					fmt.Println(logLine(m))
 				}
 			} else {
 				printGenericResponse(resp)
@@ -448,11 +1276,12 @@ var scanRerunCmd = &cobra.Command{
 		c := client.New()
 		var resp map[string]interface{}
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/rerun", args[0]), nil, &resp); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["scan_id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Scan rerun started: %v", resp["scan_id"])
 		}
@@ -471,11 +1300,12 @@ var scanCloneCmd = &cobra.Command{
 		c := client.New()
 		var resp map[string]interface{}
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/clone", args[0]), nil, &resp); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["scan_id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Scan cloned: %v", resp["scan_id"])
 		}
@@ -494,11 +1324,12 @@ var scanRetryCmd = &cobra.Command{
 		c := client.New()
 		var resp map[string]interface{}
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/retry", args[0]), nil, &resp); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["scan_id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Scan retry started")
 		}
@@ -516,8 +1347,9 @@ var scanArchiveCmd = &cobra.Command{
 		}
 		c := client.New()
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/archive", args[0]), nil, nil); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Scan %s archived", args[0])
 		return nil
 	},
@@ -533,8 +1365,9 @@ var scanUnarchiveCmd = &cobra.Command{
 		}
 		c := client.New()
 		if err := c.Post(fmt.Sprintf("/api/scans/%s/unarchive", args[0]), nil, nil); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Scan %s unarchived", args[0])
 		return nil
 	},
@@ -550,14 +1383,17 @@ var scanCompareCmd = &cobra.Command{
 			return fmt.Errorf("--scan-a and --scan-b are required")
 		}
 		c := client.New()
+		if byModule, _ := cmd.Flags().GetBool("by-module"); byModule {
+			return compareByModule(c, scanA, scanB)
+		}
 		path := fmt.Sprintf("/api/scans/compare?scan_a=%s&scan_b=%s", scanA, scanB)
 		var resp interface{}
 		if err := c.Get(path, &resp); err != nil {
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -576,11 +1412,11 @@ var scanHistoryCmd = &cobra.Command{
 		c := client.New()
 		var resp interface{}
 		if err := c.Get(fmt.Sprintf("/api/scans/%s/history", args[0]), &resp); err != nil {
-			return err
+			return notFound(err, "scan", args[0])
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -591,11 +1427,48 @@ var scanHistoryCmd = &cobra.Command{
 func init() {
 	scanStartCmd.Flags().StringP("target", "t", "", "Scan target (required)")
 	scanStartCmd.Flags().StringP("name", "n", "", "Scan name")
+	scanStartCmd.Flags().String("from-scan", "", "Start from the modules and options of the latest scan with this name; its target and name are used unless given")
+	scanStartCmd.Flags().String("tags", "", "Comma-separated tags to attach to the scan")
 	scanStartCmd.Flags().String("type", "all", "Scan type: all, passive, investigate, footprint")
 	scanStartCmd.Flags().String("modules", "", "Comma-separated list of modules to use")
+	scanStartCmd.Flags().String("module-categories", "", "Comma-separated module categories to run (see 'sf modules categories'), added to --modules")
+	scanStartCmd.Flags().String("exclude-modules", "", "Comma-separated modules to leave out of --modules and --module-categories")
+	scanStartCmd.Flags().String("target-type", "", "Expected target type: "+strings.Join(targetTypes, ", "))
+	scanStartCmd.Flags().Bool("no-validate", false, "Skip client-side target validation")
+	scanStartCmd.Flags().Int("max-threads", 0, "Maximum concurrent module threads for this scan")
+	scanStartCmd.Flags().Bool("dedupe", true, "De-duplicate events (--dedupe=false keeps raw output)")
+	scanStartCmd.Flags().Int("timeout-minutes", 0, "Abort the scan after this many minutes")
+	scanStartCmd.Flags().String("skip-if-recent", "", "Skip if the target has a scan that finished within this window (e.g. 24h, 7d)")
+	scanStartCmd.Flags().Bool("stdin", false, "Read scan requests from stdin as NDJSON (one scan_start object per line) and print results as NDJSON")
+	scanStartCmd.Flags().Int("concurrency", 4, "Maximum scans started at once with --stdin")
+	scanStartCmd.Flags().Bool("wait", false, "Wait for the scan to end; exits non-zero unless it finished successfully")
+	scanStartCmd.Flags().Duration("interval", 5*time.Second, "Polling interval for --wait")
+	scanStartCmd.Flags().String("export", "", "With --wait, export the finished scan in this format: json, csv, stix, sarif, pdf or sqlite")
+	scanStartCmd.Flags().String("file", "", "File for --export (auto-generated if omitted)")
+	scanStartCmd.Flags().String("notify-webhook", "", "With --wait, POST the scan's ID, status, event count and duration as JSON to this URL when it ends")
+	scanStartCmd.Flags().String("notify-on", notifyAll, "Which outcomes --notify-webhook reports: all, failed or finished")
+	scanStartCmd.Flags().Bool("estimate", false, "Print a rough module count and duration estimate and confirm before starting")
 
-	scanEventsCmd.Flags().String("type", "", "Filter by event type")
-	scanEventsCmd.Flags().Int("limit", 100, "Maximum events to return")
+	scanListCmd.Flags().String("since", "", "Only scans started at or after this time (RFC3339, YYYY-MM-DD, or relative like 24h, 7d)")
+	scanListCmd.Flags().String("until", "", "Only scans started at or before this time (RFC3339, YYYY-MM-DD, or relative like 24h, 7d)")
+	scanListCmd.Flags().String("status", "", "Only scans with one of these comma-separated statuses: RUNNING, FINISHED, FAILED or ABORTED (FAILED matches every failed or aborted status)")
+	scanListCmd.Flags().Int("limit", 0, "Fetch one page of at most N scans, up to 1000 (0 = the server's page size)")
+	scanListCmd.Flags().Int("offset", 0, "Skip the first N scans, to fetch later pages")
+	scanListCmd.Flags().Bool("failed", false, "Only failed or aborted scans (shortcut for --status failed)")
+	scanListCmd.Flags().Bool("show-reason", false, "Add a Reason column explaining each failed scan, from its detail or last logged error")
+	scanListCmd.Flags().String("after", "", "Only scans started or ended after this time (RFC3339, YYYY-MM-DD, or relative like 24h, 7d)")
+	scanListCmd.Flags().Bool("sync", false, "Only scans started or ended since the last --sync run against this server (or --after), then record the newest as the next starting point")
+	scanListCmd.Flags().Bool("watch", false, "Continuously refresh the table, highlighting status changes")
+	scanListCmd.Flags().Duration("interval", 5*time.Second, "Refresh interval for --watch")
+	scanListCmd.Flags().String("group-by", "", "Group scans under a header per target, newest first (only \"target\" is supported)")
+	scanListCmd.Flags().Bool("totals", false, "End the table with a totals row: the number of scans and, with the Events column (--fields), their total events")
+	scanListCmd.Flags().String("template", "", "Go template applied to each scan, e.g. '{{.Status}}\\t{{.Target}}' (fields: ScanID, Name, Target, Status, StartedAt, EndedAt)")
+	scanGetCmd.Flags().Bool("include-config", false, "Also show the modules and options the scan was configured with")
+	scanGetCmd.Flags().Bool("follow-logs", false, "Keep the scan detail updated with its log tail below until the scan finishes")
+	scanGetCmd.Flags().Duration("interval", 5*time.Second, "Polling interval for --follow-logs")
+	addJSONPathFlag(scanGetCmd)
+	addCacheFlags(scanGetCmd)
+	addCacheFlags(scanListCmd)
 
 	scanSearchCmd.Flags().String("target", "", "Filter by target")
 	scanSearchCmd.Flags().String("status", "", "Filter by status")
@@ -606,6 +1479,7 @@ func init() {
 
 	scanCompareCmd.Flags().String("scan-a", "", "First scan ID (required)")
 	scanCompareCmd.Flags().String("scan-b", "", "Second scan ID (required)")
+	scanCompareCmd.Flags().Bool("by-module", false, "Group the events added in scan B and removed since scan A by the module that produced them")
 
 	scanCmd.AddCommand(scanListCmd)
 	scanCmd.AddCommand(scanGetCmd)
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// categoryKey normalises a category name for matching: case and spacing are
+// ignored.
+func categoryKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// modulesInCategories returns the names of the modules in any of the
+// comma-separated categories in raw, sorted. Category names that themselves
+// contain commas ("Leaks, Dumps and Breaches") are recognised whole. Unknown
+// names are an error listing the valid ones.
+func modulesInCategories(modules []moduleInfo, raw string) ([]string, error) {
+	known := make(map[string]string)
+	for _, m := range modules {
+		for _, c := range m.Categories {
+			known[categoryKey(c)] = c
+		}
+	}
+
+	selected := make(map[string]bool)
+	parts := strings.Split(raw, ",")
+	for i := 0; i < len(parts); i++ {
+		name := strings.TrimSpace(parts[i])
+		if name == "" {
+			continue
+		}
+		key := categoryKey(name)
+		for j := i + 1; known[key] == "" && j < len(parts); j++ {
+			if k := categoryKey(strings.Join(parts[i:j+1], ",")); known[k] != "" {
+				key, i = k, j
+			}
+		}
+		if known[key] == "" {
+			valid := make([]string, 0, len(known))
+			for _, c := range known {
+				valid = append(valid, c)
+			}
+			sort.Strings(valid)
+			return nil, fmt.Errorf("unknown module category %q (valid: %s)", name, strings.Join(valid, "; "))
+		}
+		selected[key] = true
+	}
+
+	var names []string
+	for _, m := range modules {
+		for _, c := range m.Categories {
+			if selected[categoryKey(c)] {
+				names = append(names, m.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// combineModules merges explicit and category-selected module names, keeping
+// the first occurrence of each, then drops the excluded ones.
+func combineModules(explicit, fromCategories, excluded []string) []string {
+	drop := make(map[string]bool)
+	for _, m := range excluded {
+		drop[strings.TrimSpace(m)] = true
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, m := range append(append([]string{}, explicit...), fromCategories...) {
+		m = strings.TrimSpace(m)
+		if m == "" || seen[m] || drop[m] {
+			continue
+		}
+		seen[m] = true
+		out = append(out, m)
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// unknownModule labels events that do not name the module that produced them.
+const unknownModule = "(unknown)"
+
+// diffEvent is an event found in only one of two compared scans.
+type diffEvent struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// moduleDiff is the part of a scan comparison attributed to one module:
+// events only scan B found (added) and events only scan A found (removed).
+type moduleDiff struct {
+	Module  string      `json:"-"`
+	Added   []diffEvent `json:"added"`
+	Removed []diffEvent `json:"removed"`
+}
+
+// diffByModule compares two scans' events by type and data, like the server's
+// compare endpoint, and attributes each difference to the modules that
+// produced it: added events to their modules in scan B, removed events to
+// their modules in scan A. Modules are ordered by the number of differences,
+// most first, and their events by type, then data.
+func diffByModule(eventsA, eventsB []map[string]interface{}) []moduleDiff {
+	keysA, keysB := eventKeySet(eventsA), eventKeySet(eventsB)
+	byModule := make(map[string]*moduleDiff)
+	seen := make(map[string]bool)
+	attribute := func(events []map[string]interface{}, other map[string]bool, added bool) {
+		for _, e := range events {
+			ev := diffEvent{Type: fmt.Sprintf("%v", e["type"]), Data: fmt.Sprintf("%v", e["data"])}
+			key := ev.Type + "\x00" + ev.Data
+			if other[key] {
+				continue
+			}
+			mod := unknownModule
+			if m, ok := e["module"].(string); ok && m != "" {
+				mod = m
+			}
+			// Count each event once per module even if reported repeatedly.
+			dup := fmt.Sprintf("%t\x00%s\x00%s", added, mod, key)
+			if seen[dup] {
+				continue
+			}
+			seen[dup] = true
+			d, ok := byModule[mod]
+			if !ok {
+				d = &moduleDiff{Module: mod, Added: []diffEvent{}, Removed: []diffEvent{}}
+				byModule[mod] = d
+			}
+			if added {
+				d.Added = append(d.Added, ev)
+			} else {
+				d.Removed = append(d.Removed, ev)
+			}
+		}
+	}
+	attribute(eventsB, keysA, true)
+	attribute(eventsA, keysB, false)
+
+	diffs := make([]moduleDiff, 0, len(byModule))
+	for _, d := range byModule {
+		sortDiffEvents(d.Added)
+		sortDiffEvents(d.Removed)
+		diffs = append(diffs, *d)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		ni, nj := len(diffs[i].Added)+len(diffs[i].Removed), len(diffs[j].Added)+len(diffs[j].Removed)
+		if ni != nj {
+			return ni > nj
+		}
+		return diffs[i].Module < diffs[j].Module
+	})
+	return diffs
+}
+
+func eventKeySet(events []map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(events))
+	for _, e := range events {
+		keys[fmt.Sprintf("%v\x00%v", e["type"], e["data"])] = true
+	}
+	return keys
+}
+
+func sortDiffEvents(events []diffEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Type != events[j].Type {
+			return events[i].Type < events[j].Type
+		}
+		return events[i].Data < events[j].Data
+	})
+}
+
+// compareByModule fetches the events of both scans and prints their
+// differences grouped by module. JSON output maps each module name to its
+// added and removed events.
+func compareByModule(c *client.Client, scanA, scanB string) error {
+	for _, id := range []string{scanA, scanB} {
+		if err := validateSafeID(id, "scan ID"); err != nil {
+			return err
+		}
+	}
+	stop := output.StartSpinner("Fetching events from both scans...")
+	eventsByScan, err := fetchScanEvents(c, []string{scanA, scanB}, "")
+	stop()
+	if err != nil {
+		return err
+	}
+	diffs := diffByModule(eventsByScan[scanA], eventsByScan[scanB])
+
+	switch output.Current() {
+	case output.JSON, output.YAML:
+		byName := make(map[string]moduleDiff, len(diffs))
+		for _, d := range diffs {
+			byName[d.Module] = d
+		}
+		output.PrintStructured(byName)
+	case output.CSV:
+		var rows [][]string
+		for _, d := range diffs {
+			for _, ev := range d.Added {
+				rows = append(rows, []string{d.Module, "added", ev.Type, ev.Data})
+			}
+			for _, ev := range d.Removed {
+				rows = append(rows, []string{d.Module, "removed", ev.Type, ev.Data})
+			}
+		}
+		output.PrintCSV([]string{"module", "change", "type", "data"}, rows)
+	default:
+		if len(diffs) == 0 {
+			fmt.Printf("Scans %s and %s found the same events.\n", scanA, scanB)
+			return nil
+		}
+		rows := make([][]string, 0, len(diffs))
+		for _, d := range diffs {
+			rows = append(rows, []string{d.Module, strconv.Itoa(len(d.Added)), strconv.Itoa(len(d.Removed))})
+		}
+		fmt.Printf("Events only in %s are added, events only in %s removed.\n\n", scanB, scanA)
+		output.PrintTable([]string{"Module", "Added", "Removed"}, rows)
+		for _, d := range diffs {
+			fmt.Printf("\n%s\n", color.New(color.Bold).Sprint(d.Module))
+			for _, ev := range d.Added {
+				fmt.Printf("  %s %s  %s\n", color.GreenString("+"), ev.Type, truncateCell(ev.Data, 80))
+			}
+			for _, ev := range d.Removed {
+				fmt.Printf("  %s %s  %s\n", color.RedString("-"), ev.Type, truncateCell(ev.Data, 80))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rough per-module run times and default parallelism used by estimateScan.
+// The server exposes no estimate endpoint, so these are deliberately coarse.
+const (
+	passiveModuleMinutes = 1.0
+	activeModuleMinutes  = 4.0
+	defaultScanThreads   = 3
+)
+
+// scanEstimate is a rough, client-side prediction of a scan's size and duration.
+type scanEstimate struct {
+	Modules    int     `json:"modules"`
+	Active     int     `json:"active_modules"`
+	MinMinutes float64 `json:"min_minutes"`
+	MaxMinutes float64 `json:"max_minutes"`
+}
+
+// estimateScan predicts how many modules a scan will run and roughly how long
+// it will take. selected, if non-empty, is the explicit --modules list;
+// otherwise the scan type decides: "passive" runs passive modules only, any
+// other type runs everything.
+func estimateScan(available []moduleInfo, scanType string, selected []string, threads int) scanEstimate {
+	if threads <= 0 {
+		threads = defaultScanThreads
+	}
+
+	var chosen []moduleInfo
+	if len(selected) > 0 {
+		byName := make(map[string]moduleInfo, len(available))
+		for _, m := range available {
+			byName[m.Name] = m
+		}
+		for _, name := range selected {
+			name = strings.TrimSpace(name)
+			if m, ok := byName[name]; ok {
+				chosen = append(chosen, m)
+			} else if name != "" {
+				chosen = append(chosen, moduleInfo{Name: name})
+			}
+		}
+	} else {
+		for _, m := range available {
+			if strings.EqualFold(scanType, "passive") && !strings.EqualFold(m.Type, "passive") {
+				continue
+			}
+			chosen = append(chosen, m)
+		}
+	}
+
+	est := scanEstimate{Modules: len(chosen)}
+	minutes := 0.0
+	for _, m := range chosen {
+		if strings.EqualFold(m.Type, "active") {
+			est.Active++
+			minutes += activeModuleMinutes
+		} else {
+			minutes += passiveModuleMinutes
+		}
+	}
+	minutes /= float64(threads)
+	est.MinMinutes, est.MaxMinutes = minutes/2, minutes*2
+	return est
+}
+
+// formatMinutes renders a duration in minutes as e.g. "<1m", "25m" or "3.5h".
+func formatMinutes(m float64) string {
+	switch {
+	case m < 1:
+		return "<1m"
+	case m < 60:
+		return fmt.Sprintf("%.0fm", m)
+	default:
+		return fmt.Sprintf("%.1fh", m/60)
+	}
+}
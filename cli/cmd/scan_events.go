@@ -0,0 +1,778 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// streamEventsThreshold is the event count above which -o json output is
+// streamed without --stream.
+const streamEventsThreshold = 10000
+
+// resolveConcurrency bounds the parallel per-scan or per-module fetches made
+// to fill in details a list response lacks.
+const resolveConcurrency = 8
+
+// eventFilter is a compiled --filter-expr, an expr
+// (github.com/expr-lang/expr) expression evaluated against each event's
+// fields, e.g. risk == "HIGH" && type in ["IP_ADDRESS", "DOMAIN_NAME"].
+type eventFilter struct {
+	program *vm.Program
+}
+
+// compileFilter compiles src, which must evaluate to a boolean. Fields
+// missing from an event are nil. expr's type() builtin is disabled so that
+// type names the event field.
+func compileFilter(src string) (*eventFilter, error) {
+	program, err := expr.Compile(src, expr.Env(map[string]interface{}{}), expr.AllowUndefinedVariables(),
+		expr.DisableBuiltin("type"), expr.AsBool())
+	if err != nil {
+		return nil, err
+	}
+	return &eventFilter{program: program}, nil
+}
+
+// match reports whether the expression is true for the event. Runtime
+// errors, such as matching a regexp against a number, count as false.
+func (f *eventFilter) match(event map[string]interface{}) bool {
+	out, err := expr.Run(f.program, event)
+	if err != nil {
+		return false
+	}
+	b, _ := out.(bool)
+	return b
+}
+
+// filterEvents returns the events f matches; a nil filter keeps them all.
+func filterEvents(f *eventFilter, events []map[string]interface{}) []map[string]interface{} {
+	if f == nil {
+		return events
+	}
+	kept := events[:0:0]
+	for _, m := range events {
+		if f.match(m) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+var scanEventsCmd = &cobra.Command{
+	Use:         "events [scan-id]",
+	Short:       "List events collected in a scan",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{formatsAnnotation: string(output.GeoJSON)},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateSafeID(args[0], "scan ID"); err != nil {
+			return err
+		}
+		c := client.New()
+		eventType, _ := cmd.Flags().GetString("type")
+		limit, _ := cmd.Flags().GetInt("limit")
+		resolveSource, _ := cmd.Flags().GetBool("resolve-source")
+		enrich, _ := cmd.Flags().GetBool("enrich")
+
+		iocs, _ := cmd.Flags().GetBool("iocs")
+		unique, _ := cmd.Flags().GetBool("unique")
+		if iocs && unique {
+			return fmt.Errorf("--iocs and --unique are mutually exclusive")
+		}
+		geoJSON := strings.EqualFold(viper.GetString("output"), string(output.GeoJSON))
+		if geoJSON && (iocs || unique) {
+			return fmt.Errorf("-o geojson cannot be combined with --iocs or --unique")
+		}
+		var filter *eventFilter
+		if src, _ := cmd.Flags().GetString("filter-expr"); src != "" {
+			var err error
+			if filter, err = compileFilter(src); err != nil {
+				return fmt.Errorf("invalid --filter-expr: %w", err)
+			}
+		}
+		sortKey, _ := cmd.Flags().GetString("sort")
+		reverse, _ := cmd.Flags().GetBool("reverse")
+		top, _ := cmd.Flags().GetInt("top")
+		if !slices.Contains(eventSortKeys, sortKey) {
+			return fmt.Errorf("invalid --sort %q (expected %s)", sortKey, strings.Join(eventSortKeys, ", "))
+		}
+		if top < 0 {
+			return fmt.Errorf("--top must not be negative")
+		}
+		sorted := cmd.Flags().Changed("sort") || reverse || top > 0
+		if sorted && (iocs || unique || geoJSON) {
+			return fmt.Errorf("--sort, --reverse and --top cannot be combined with --iocs, --unique or -o geojson")
+		}
+		noDefang, _ := cmd.Flags().GetBool("no-defang")
+		if cmd.Flags().Changed("defang") && noDefang {
+			return fmt.Errorf("--defang and --no-defang are mutually exclusive")
+		}
+		defang, _ := cmd.Flags().GetBool("defang")
+		defang = defang && !noDefang
+
+		delim, _ := cmd.Flags().GetString("explode")
+		explodeCol, _ := cmd.Flags().GetString("explode-column")
+		var explodeKey string
+		if cmd.Flags().Changed("explode") || explodeCol != "" {
+			if delim == "" || explodeCol == "" {
+				return fmt.Errorf("--explode needs a non-empty delimiter and --explode-column naming the column to split")
+			}
+			var err error
+			if explodeKey, err = eventColumnKey(explodeCol, resolveSource); err != nil {
+				return err
+			}
+			if iocs || unique || geoJSON || output.Structured() {
+				return fmt.Errorf("--explode only applies to table and CSV event output")
+			}
+			delim = explodeEscapes.Replace(delim)
+		}
+
+		if follow, _ := cmd.Flags().GetBool("follow"); follow {
+			if explodeKey != "" || enrich {
+				return fmt.Errorf("--explode and --enrich cannot be combined with --follow")
+			}
+			if iocs || unique || geoJSON {
+				return fmt.Errorf("--iocs, --unique and -o geojson cannot be combined with --follow")
+			}
+			if sorted {
+				return fmt.Errorf("--sort, --reverse and --top cannot be combined with --follow")
+			}
+			interval, _ := cmd.Flags().GetDuration("interval")
+			fwd, err := newEventForwarder(cmd)
+			if err != nil {
+				return err
+			}
+			return followEvents(c, args[0], eventType, interval, fwd, filter)
+		}
+		if cmd.Flags().Changed("webhook") {
+			return fmt.Errorf("--webhook requires --follow")
+		}
+
+		// Indicator lists, counts, maps and top-N picks should cover the
+		// whole scan unless a limit is given.
+		wholeScan := (iocs || unique || geoJSON || top > 0) && !cmd.Flags().Changed("limit")
+		path := fmt.Sprintf("/api/scans/%s/events?limit=%d", args[0], limit)
+		if wholeScan {
+			path = fmt.Sprintf("/api/scans/%s/events", args[0])
+		}
+		if eventType != "" {
+			sep := "&"
+			if !strings.Contains(path, "?") {
+				sep = "?"
+			}
+			path += sep + "type=" + eventType
+		}
+
+		// JSON output of plain event lists is streamed, in server order,
+		// when paging, asked to or large; everything else needs the whole
+		// list first.
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		stream, _ := cmd.Flags().GetBool("stream")
+		plainJSON := output.Current() == output.JSON && !iocs && !unique && !geoJSON && !resolveSource && !sorted
+		if stream && !plainJSON {
+			return fmt.Errorf("--stream needs -o json and cannot be combined with --iocs, --unique, -o geojson, --resolve-source or --sort, --reverse and --top")
+		}
+		if plainJSON && batchSize == 0 && !cmd.Flags().Changed("stream") && (limit <= 0 || limit > streamEventsThreshold) {
+			stream = largeEventList(c, args[0])
+		}
+		if plainJSON && (stream || batchSize > 0) {
+			var descriptions map[string]string
+			if enrich {
+				var err error
+				if descriptions, err = fetchEventTypeDescriptions(c); err != nil {
+					return fmt.Errorf("fetching event types for --enrich: %w", err)
+				}
+			}
+			return streamEvents(c, args[0], path, eventType, batchSize, limit, filter, descriptions)
+		}
+
+		var resp interface{}
+		var events []map[string]interface{}
+		ok := true
+		if batchSize > 0 {
+			want := limit
+			if wholeScan {
+				want = 0
+			}
+			var err error
+			if events, err = fetchEventPages(c, args[0], eventType, batchSize, want); err != nil {
+				return err
+			}
+			resp = map[string]interface{}{"events": events, "total": len(events)}
+		} else {
+			if err := c.Get(path, &resp); err != nil {
+				return notFound(err, "scan", args[0])
+			}
+			events, ok = eventItems(resp)
+		}
+		if filter != nil {
+			if !ok {
+				return fmt.Errorf("unexpected events response for scan %s", args[0])
+			}
+			events = filterEvents(filter, events)
+			resp = map[string]interface{}{"events": events, "total": len(events)}
+		}
+		if iocs {
+			if !ok {
+				return fmt.Errorf("unexpected events response for scan %s", args[0])
+			}
+			printIOCs(extractIOCs(events, defang))
+			return nil
+		}
+		if unique {
+			if !ok {
+				return fmt.Errorf("unexpected events response for scan %s", args[0])
+			}
+			printEventCounts(uniqueEvents(events))
+			return nil
+		}
+		if geoJSON {
+			if !ok {
+				return fmt.Errorf("unexpected events response for scan %s", args[0])
+			}
+			fc, skipped := eventsGeoJSON(events)
+			output.PrintJSON(fc)
+			output.Note("GeoJSON: %d events with coordinates included, %d without excluded", len(fc.Features), skipped)
+			return nil
+		}
+		if ok {
+			// Tables and CSV are in time order by default; JSON keeps the
+			// server's response unless an order was asked for.
+			sortEvents(events, sortKey, reverse)
+			if top > 0 && len(events) > top {
+				events = events[:top]
+			}
+			if sorted {
+				resp = withEventItems(resp, events)
+			}
+		}
+		if ok && resolveSource {
+			resolveEventSources(c, args[0], events)
+		}
+		if enrich {
+			if !ok {
+				return fmt.Errorf("unexpected events response for scan %s", args[0])
+			}
+			descriptions, err := fetchEventTypeDescriptions(c)
+			if err != nil {
+				return fmt.Errorf("fetching event types for --enrich: %w", err)
+			}
+			for _, m := range events {
+				m["type_description"] = descriptions[fmt.Sprintf("%v", m["type"])]
+			}
+		}
+		if ok && explodeKey != "" {
+			events = explodeEvents(events, explodeKey, delim)
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
+		case output.CSV:
+			if !ok {
+				printGenericResponse(resp)
+				break
+			}
+			// CSV keeps data whole; the writer quotes commas, quotes and
+			// line breaks in multi-line values such as banners.
+			output.PrintCSV(eventRows(events, resolveSource, enrich, false))
+		default:
+			if !ok {
+				printGenericResponse(resp)
+				break
+			}
+			output.PrintTable(eventRows(events, resolveSource, enrich, true))
+		}
+		return nil
+	},
+}
+
+// eventRows lays out events for the events table or CSV. With enrich, a
+// Description column follows Type. With truncate, long data is shortened to
+// fit a terminal.
+func eventRows(events []map[string]interface{}, resolveSource, enrich, truncate bool) ([]string, [][]string) {
+	cell := func(v interface{}, n int) string {
+		s := fmt.Sprintf("%v", v)
+		if truncate {
+			return truncateCell(s, n)
+		}
+		return s
+	}
+	header := []string{"Type"}
+	if enrich {
+		header = append(header, "Description")
+	}
+	header = append(header, "Module", "Data")
+	if resolveSource {
+		header = append(header, "Source Module", "Source Data")
+	} else {
+		header = append(header, "Source")
+	}
+	rows := make([][]string, 0, len(events))
+	for _, m := range events {
+		row := []string{fmt.Sprintf("%v", m["type"])}
+		if enrich {
+			desc, _ := m["type_description"].(string)
+			row = append(row, cell(desc, 30))
+		}
+		row = append(row, fmt.Sprintf("%v", m["module"]), cell(m["data"], 60))
+		if resolveSource {
+			row = append(row, fmt.Sprintf("%v", m["source_module"]), cell(m["source_data"], 40))
+		} else {
+			row = append(row, fmt.Sprintf("%v", m["source"]))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// fetchEventTypeDescriptions maps event type codes to their descriptions from
+// /api/event-types. Types are listed either as [description, code, raw,
+// category] rows, as they come from the database, or as objects with "event"
+// and "event_descr" fields.
+func fetchEventTypeDescriptions(c *client.Client) (map[string]string, error) {
+	var resp struct {
+		EventTypes []interface{} `json:"event_types"`
+	}
+	if err := c.Get("/api/event-types", &resp); err != nil {
+		return nil, err
+	}
+	descriptions := make(map[string]string, len(resp.EventTypes))
+	for _, t := range resp.EventTypes {
+		switch v := t.(type) {
+		case []interface{}:
+			if len(v) >= 2 {
+				descriptions[fmt.Sprintf("%v", v[1])] = fmt.Sprintf("%v", v[0])
+			}
+		case map[string]interface{}:
+			if code, ok := v["event"].(string); ok {
+				descriptions[code], _ = v["event_descr"].(string)
+			}
+		}
+	}
+	return descriptions, nil
+}
+
+// explodeEscapes lets --explode name line breaks and tabs.
+var explodeEscapes = strings.NewReplacer(`\n`, "\n", `\t`, "\t")
+
+// eventColumnKey maps an events table column, by header or event field name,
+// to the field --explode splits.
+func eventColumnKey(name string, resolveSource bool) (string, error) {
+	keys := map[string]string{"type": "type", "module": "module", "data": "data", "source": "source"}
+	if resolveSource {
+		delete(keys, "source")
+		keys["source module"], keys["source_module"] = "source_module", "source_module"
+		keys["source data"], keys["source_data"] = "source_data", "source_data"
+	}
+	if key, ok := keys[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return key, nil
+	}
+	valid := make([]string, 0, len(keys))
+	for k := range keys {
+		if !strings.Contains(k, " ") {
+			valid = append(valid, k)
+		}
+	}
+	sort.Strings(valid)
+	return "", fmt.Errorf("unknown --explode-column %q (valid: %s)", name, strings.Join(valid, ", "))
+}
+
+// explodeEvents splits the key field of each event on delim into one event
+// per value, copying the other fields. Values are trimmed and empty ones
+// dropped; an event with nothing to split is kept as it is.
+func explodeEvents(events []map[string]interface{}, key, delim string) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(events))
+	for _, m := range events {
+		v, ok := m[key]
+		if !ok || v == nil {
+			out = append(out, m)
+			continue
+		}
+		var parts []string
+		for _, p := range strings.Split(fmt.Sprintf("%v", v), delim) {
+			if p = strings.TrimSpace(p); p != "" {
+				parts = append(parts, p)
+			}
+		}
+		if len(parts) <= 1 {
+			out = append(out, m)
+			continue
+		}
+		for _, p := range parts {
+			row := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				row[k] = v
+			}
+			row[key] = p
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+type eventCount struct {
+	Type  string `json:"type"`
+	Data  string `json:"data"`
+	Count int    `json:"count"`
+}
+
+// uniqueEvents collapses events with the same type and data, counting
+// occurrences. The result is sorted by count, most frequent first.
+func uniqueEvents(events []map[string]interface{}) []eventCount {
+	index := make(map[string]int)
+	counts := []eventCount{}
+	for _, m := range events {
+		t, d := fmt.Sprintf("%v", m["type"]), fmt.Sprintf("%v", m["data"])
+		key := t + "\x00" + d
+		if i, ok := index[key]; ok {
+			counts[i].Count++
+			continue
+		}
+		index[key] = len(counts)
+		counts = append(counts, eventCount{Type: t, Data: d, Count: 1})
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Data < b.Data
+	})
+	return counts
+}
+
+func printEventCounts(counts []eventCount) {
+	switch output.Current() {
+	case output.JSON, output.YAML:
+		output.PrintStructured(counts)
+	case output.CSV:
+		rows := make([][]string, 0, len(counts))
+		for _, c := range counts {
+			rows = append(rows, []string{c.Type, c.Data, strconv.Itoa(c.Count)})
+		}
+		output.PrintCSV([]string{"Type", "Data", "Count"}, rows)
+	default:
+		rows := make([][]string, 0, len(counts))
+		for _, c := range counts {
+			rows = append(rows, []string{c.Type, truncateCell(c.Data, 60), strconv.Itoa(c.Count)})
+		}
+		output.PrintTable([]string{"Type", "Data", "Count"}, rows)
+	}
+}
+
+// followEvents polls a scan's events every interval, printing events not seen
+// before, until the scan finishes or the user interrupts. Events are tracked
+// by hash since the API has no "since" parameter. Only events matching filter,
+// if set, are printed, and they are also passed to fwd, if set.
+func followEvents(c *client.Client, scanID, eventType string, interval time.Duration, fwd *eventForwarder, filter *eventFilter) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ctx := commandContext()
+
+	path := fmt.Sprintf("/api/scans/%s/events", scanID)
+	if eventType != "" {
+		path += "?type=" + eventType
+	}
+
+	format := output.Current()
+	var csvOut *csv.Writer
+	if format == output.CSV {
+		csvOut = csv.NewWriter(os.Stdout)
+		_ = csvOut.Write([]string{"Generated", "Type", "Module", "Data", "Hash"})
+		csvOut.Flush()
+	}
+
+	if fwd != nil {
+		defer func() {
+			output.Note("Forwarded %d events to the webhook (%d failed)", fwd.delivered, fwd.failed)
+		}()
+	}
+
+	seen := make(map[string]bool)
+	for {
+		// Check status first so events produced before the scan finished are
+		// still printed by the final poll.
+		var s scanDetail
+		if err := c.Get(fmt.Sprintf("/api/scans/%s", scanID), &s); err != nil {
+			return notFound(err, "scan", scanID)
+		}
+		done := scanDone(s.Status)
+
+		var resp interface{}
+		if err := c.Get(path, &resp); err != nil {
+			return notFound(err, "scan", scanID)
+		}
+		events, ok := eventItems(resp)
+		if !ok {
+			return fmt.Errorf("unexpected events response for scan %s", scanID)
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			gi, _ := events[i]["generated"].(float64)
+			gj, _ := events[j]["generated"].(float64)
+			return gi < gj
+		})
+
+		var fresh []map[string]interface{}
+		for _, m := range events {
+			key := fmt.Sprintf("%v", m["hash"])
+			if m["hash"] == nil {
+				key = fmt.Sprintf("%v|%v|%v", m["type"], m["module"], m["data"])
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if filter != nil && !filter.match(m) {
+				continue
+			}
+			fresh = append(fresh, m)
+
+			generated, _ := m["generated"].(float64)
+			switch format {
+			case output.JSON:
+				line, _ := json.Marshal(m)
+				fmt.Println(string(line))
+			case output.YAML:
+				// One YAML document per event.
+				fmt.Println("---")
+				output.PrintYAML(m)
+			case output.CSV:
+				_ = csvOut.Write([]string{formatEpoch(generated), fmt.Sprintf("%v", m["type"]), fmt.Sprintf("%v", m["module"]), fmt.Sprintf("%v", m["data"]), key})
+				csvOut.Flush()
+			default:
+				fmt.Printf("%s  %-24s %-20s %s\n", time.Unix(int64(generated), 0).Format("15:04:05"),
+					fmt.Sprintf("%v", m["type"]), fmt.Sprintf("%v", m["module"]), truncateCell(fmt.Sprintf("%v", m["data"]), 80))
+			}
+		}
+
+		if fwd != nil {
+			fwd.forward(ctx, fresh)
+		}
+
+		if done {
+			if format == output.Table {
+				output.Success("Scan %s %s", scanID, s.Status)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// fetchEventPages fetches up to limit events (all when limit is 0) in pages of
+// batchSize using limit and offset, so no single request has to carry the
+// whole scan.
+func fetchEventPages(c *client.Client, scanID, eventType string, batchSize, limit int) ([]map[string]interface{}, error) {
+	var events []map[string]interface{}
+	err := eachEventPage(c, scanID, eventType, batchSize, limit, func(page []map[string]interface{}) error {
+		events = append(events, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// eachEventPage fetches up to limit events (all when limit is 0) in pages of
+// batchSize, passing each page to fn as it arrives. Paging stops early when
+// the command is interrupted or fn fails. A server that ignores the page size
+// or offset is detected, so its answer is used as-is rather than fetched over
+// and over.
+func eachEventPage(c *client.Client, scanID, eventType string, batchSize, limit int, fn func([]map[string]interface{}) error) error {
+	ctx := commandContext()
+	var firstHash interface{}
+	for offset := 0; limit <= 0 || offset < limit; offset += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size := batchSize
+		if limit > 0 {
+			size = min(size, limit-offset)
+		}
+		params := url.Values{"limit": {strconv.Itoa(size)}, "offset": {strconv.Itoa(offset)}}
+		if eventType != "" {
+			params.Set("type", eventType)
+		}
+		var resp interface{}
+		if err := c.Get(fmt.Sprintf("/api/scans/%s/events?%s", scanID, params.Encode()), &resp); err != nil {
+			return notFound(err, "scan", scanID)
+		}
+		page, ok := eventItems(resp)
+		if !ok {
+			return fmt.Errorf("unexpected events response for scan %s", scanID)
+		}
+		if len(page) > size {
+			output.Warn("server does not paginate events; all %d were returned in one response", len(page))
+			if limit > 0 && len(page) > limit {
+				page = page[:limit]
+			}
+			return fn(page)
+		}
+		if len(page) > 0 {
+			if offset == 0 {
+				firstHash = page[0]["hash"]
+			} else if firstHash != nil && page[0]["hash"] == firstHash {
+				output.Warn("server ignores the events offset; stopping after the first page")
+				break
+			}
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if len(page) < size {
+			break
+		}
+	}
+	return nil
+}
+
+// eventItems extracts the event objects from an events response, which is
+// either a bare array or an object with an "events" array.
+func eventItems(resp interface{}) ([]map[string]interface{}, bool) {
+	items, ok := resp.([]interface{})
+	if !ok {
+		m, isMap := resp.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		if items, ok = m["events"].([]interface{}); !ok {
+			return nil, false
+		}
+	}
+	events := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			events = append(events, m)
+		}
+	}
+	return events, true
+}
+
+// largeEventList reports whether a scan has more than streamEventsThreshold
+// events, going by the event count in its detail. A scan whose detail cannot
+// be fetched is not treated as large; the events request reports the error.
+func largeEventList(c *client.Client, scanID string) bool {
+	var s scanDetail
+	if err := c.Get(fmt.Sprintf("/api/scans/%s", scanID), &s); err != nil {
+		return false
+	}
+	return s.EventCount > streamEventsThreshold
+}
+
+// withEventItems returns resp with its events replaced, keeping the rest of
+// the server's envelope. A bare array response is replaced outright.
+func withEventItems(resp interface{}, events []map[string]interface{}) interface{} {
+	if m, ok := resp.(map[string]interface{}); ok {
+		m["events"] = events
+		return m
+	}
+	return events
+}
+
+// resolveEventSources annotates each event with the module ("source_module")
+// and data ("source_data") of its source event. The API has no per-event
+// route, so when parents are missing from events (a --type, --limit or
+// --filter-expr subset) the scan's full event list is fetched once and
+// indexed by hash.
+func resolveEventSources(c *client.Client, scanID string, events []map[string]interface{}) {
+	byHash := eventsByHash(events)
+	for _, m := range events {
+		if h := sourceHash(m); h != "" && h != "ROOT" && byHash[h] == nil {
+			var resp interface{}
+			if err := c.Get(fmt.Sprintf("/api/scans/%s/events", scanID), &resp); err == nil {
+				if all, ok := eventItems(resp); ok {
+					byHash = eventsByHash(all)
+				}
+			}
+			break
+		}
+	}
+
+	for _, m := range events {
+		h := sourceHash(m)
+		switch parent := byHash[h]; {
+		case h == "ROOT":
+			m["source_module"], m["source_data"] = "ROOT", "(scan target)"
+		case parent != nil:
+			m["source_module"], m["source_data"] = parent["module"], parent["data"]
+		default:
+			m["source_module"], m["source_data"] = "?", "?"
+		}
+	}
+}
+
+// eventsByHash indexes events by their hash.
+func eventsByHash(events []map[string]interface{}) map[string]map[string]interface{} {
+	byHash := make(map[string]map[string]interface{}, len(events))
+	for _, m := range events {
+		if h, ok := m["hash"].(string); ok && h != "" {
+			byHash[h] = m
+		}
+	}
+	return byHash
+}
+
+// sourceHash returns the hash of an event's source event.
+func sourceHash(m map[string]interface{}) string {
+	if h, ok := m["source_event_hash"].(string); ok {
+		return h
+	}
+	h, _ := m["source_hash"].(string)
+	return h
+}
+
+// truncateCell shortens s to at most n characters for table display, unless
+// --wrap asks for full cells.
+func truncateCell(s string, n int) string {
+	if output.Wrap() {
+		return s
+	}
+	return output.Truncate(s, n)
+}
+
+func init() {
+	scanEventsCmd.Flags().String("type", "", "Filter by event type")
+	scanEventsCmd.Flags().Int("limit", 100, "Maximum events to return")
+	scanEventsCmd.Flags().String("sort", "time", "Order events by time, risk (most severe first), module or type")
+	scanEventsCmd.Flags().Bool("reverse", false, "Reverse the --sort order")
+	scanEventsCmd.Flags().Int("top", 0, "Show only the first N events after sorting, picked from the whole scan unless --limit is given")
+	scanEventsCmd.Flags().Int("batch-size", 0, "Fetch events in pages of N (limit/offset) instead of one request")
+	scanEventsCmd.Flags().Bool("stream", false, "With -o json, write events while they are decoded instead of after the whole response (implied by --batch-size, and the default above 10000 events)")
+	scanEventsCmd.Flags().Bool("resolve-source", false, "Show the module and data of each event's source event")
+	scanEventsCmd.Flags().BoolP("follow", "f", false, "Print new events as they arrive until the scan finishes")
+	scanEventsCmd.Flags().Duration("interval", 5*time.Second, "Polling interval for --follow")
+	scanEventsCmd.Flags().String("webhook", "", "With --follow, POST each new event as JSON to this URL")
+	scanEventsCmd.Flags().StringArray("webhook-header", nil, "Header for --webhook requests, as \"Name: value\" (repeatable)")
+	scanEventsCmd.Flags().Int("webhook-batch", 1, "With --webhook, send up to N events per request as a JSON array")
+	scanEventsCmd.Flags().Bool("enrich", false, "Add each event type's human-readable description (a Description column, or type_description in JSON)")
+	scanEventsCmd.Flags().String("filter-expr", "", "Only events for which this expression is true, e.g. 'risk == \"HIGH\" && type in [\"IP_ADDRESS\", \"DOMAIN_NAME\"]'")
+	scanEventsCmd.Flags().String("explode", "", "Split --explode-column on this delimiter into one row per value (\\n and \\t allowed), copying the other columns")
+	scanEventsCmd.Flags().String("explode-column", "", "Column split by --explode: type, module, data or source (source_module, source_data with --resolve-source)")
+	scanEventsCmd.Flags().Bool("unique", false, "Collapse events with the same type and data, with an occurrence count, most frequent first")
+	scanEventsCmd.Flags().Bool("iocs", false, "Only print indicators (IPs, domains, emails, hashes, URLs), one per line or as CSV")
+	scanEventsCmd.Flags().Bool("defang", true, "With --iocs, defang indicators (example[.]com, hxxp://)")
+	scanEventsCmd.Flags().Bool("no-defang", false, "With --iocs, print indicators as-is")
+}
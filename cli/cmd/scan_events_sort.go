@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"sort"
+	"strconv"
+)
+
+// eventSortKeys are the orders accepted by scan events --sort.
+var eventSortKeys = []string{"time", "risk", "module", "type"}
+
+// eventRiskScore places an event's risk on one 0-100 scale so numeric risks
+// and risk level names sort together: INFO, LOW, MEDIUM and HIGH are spread
+// evenly from 0 to 100. A missing or unrecognized risk scores -1.
+func eventRiskScore(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+		if rank := riskRank(v); rank >= 0 {
+			return float64(rank) * 100 / float64(len(riskLevels)-1)
+		}
+	}
+	return -1
+}
+
+// sortEvents orders events by one of eventSortKeys: time, module and type
+// ascending, risk most severe first. Ties keep time order. reverse flips the
+// whole order.
+func sortEvents(events []map[string]interface{}, key string, reverse bool) {
+	generated := func(e map[string]interface{}) float64 {
+		t, _ := e["generated"].(float64)
+		return t
+	}
+	less := func(a, b map[string]interface{}) bool {
+		switch key {
+		case "risk":
+			if ra, rb := eventRiskScore(a["risk"]), eventRiskScore(b["risk"]); ra != rb {
+				return ra > rb
+			}
+		case "module", "type":
+			if sa, sb := eventCell(a[key]), eventCell(b[key]); sa != sb {
+				return sa < sb
+			}
+		}
+		return generated(a) < generated(b)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if reverse {
+			return less(events[j], events[i])
+		}
+		return less(events[i], events[j])
+	})
+}
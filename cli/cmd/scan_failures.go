@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+// statusFailed is the --status value matching every failed scan status.
+const statusFailed = "failed"
+
+// scanStatusValues are the statuses scan list --status accepts.
+var scanStatusValues = []string{"RUNNING", "FINISHED", "FAILED", "ABORTED"}
+
+// failedStatuses are the scan statuses that mean the scan failed or was
+// aborted.
+var failedStatuses = []string{"FAILED", "ERROR", "ERROR-FAILED", "ABORTED"}
+
+// scanFailed reports whether a scan status means the scan failed or was
+// aborted.
+func scanFailed(status string) bool {
+	return slices.Contains(failedStatuses, strings.ToUpper(status))
+}
+
+// scanStatuses returns the statuses selected by --status and --failed,
+// upper-cased and without duplicates, or nil if neither was given. Values
+// outside scanStatusValues are an error.
+func scanStatuses(cmd *cobra.Command) ([]string, error) {
+	raw, _ := cmd.Flags().GetString("status")
+	if failed, _ := cmd.Flags().GetBool("failed"); failed {
+		raw += "," + statusFailed
+	}
+	var statuses []string
+	for _, v := range strings.Split(raw, ",") {
+		st := strings.ToUpper(strings.TrimSpace(v))
+		switch {
+		case st == "", slices.Contains(statuses, st):
+		case !slices.Contains(scanStatusValues, st):
+			return nil, fmt.Errorf("invalid --status %q: must be one of %s", strings.TrimSpace(v), strings.Join(scanStatusValues, ", "))
+		default:
+			statuses = append(statuses, st)
+		}
+	}
+	return statuses, nil
+}
+
+// scanStatusQuery returns the status= query parameter asking the server for
+// scans with one of statuses. FAILED is widened to every failed status so the
+// server returns what filterScansByStatus keeps.
+func scanStatusQuery(statuses []string) url.Values {
+	if len(statuses) == 0 {
+		return nil
+	}
+	var values []string
+	for _, st := range statuses {
+		if strings.EqualFold(st, statusFailed) {
+			for _, f := range failedStatuses {
+				if !slices.Contains(values, f) {
+					values = append(values, f)
+				}
+			}
+		} else if !slices.Contains(values, st) {
+			values = append(values, st)
+		}
+	}
+	return url.Values{"status": {strings.Join(values, ",")}}
+}
+
+// filterScansByStatus keeps the scans whose status is one of statuses,
+// compared case-insensitively; "failed" matches every failed status. An
+// empty list keeps them all.
+func filterScansByStatus(scans []scanSummary, statuses []string) []scanSummary {
+	if len(statuses) == 0 {
+		return scans
+	}
+	kept := scans[:0:0]
+	for _, s := range scans {
+		for _, want := range statuses {
+			if strings.EqualFold(s.Status, want) || (strings.EqualFold(want, statusFailed) && scanFailed(s.Status)) {
+				kept = append(kept, s)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// failureReasonKeys are the scan detail fields that may explain a failure.
+var failureReasonKeys = []string{"error", "error_message", "failure_reason", "abort_reason", "reason"}
+
+// failureReason explains a failed scan from its detail or, failing that, the
+// last error in its log.
+func failureReason(detail map[string]interface{}, logs []map[string]interface{}) string {
+	for _, k := range failureReasonKeys {
+		if v, ok := detail[k]; ok && v != nil && fmt.Sprint(v) != "" {
+			return fmt.Sprint(v)
+		}
+	}
+	for i := len(logs) - 1; i >= 0; i-- {
+		switch strings.ToUpper(fmt.Sprint(logs[i]["type"])) {
+		case "ERROR", "FATAL", "CRITICAL":
+			return fmt.Sprint(logs[i]["message"])
+		}
+	}
+	return ""
+}
+
+// fillFailureReasons sets Reason on each failed scan, fetching at most
+// resolveConcurrency scans at a time. Scans whose reason cannot be found are
+// left blank.
+func fillFailureReasons(c *client.Client, scans []scanSummary) {
+	sem := make(chan struct{}, resolveConcurrency)
+	var wg sync.WaitGroup
+	for i := range scans {
+		if !scanFailed(scans[i].Status) || validateSafeID(scans[i].ScanID, "scan ID") != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(s *scanSummary) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var detail map[string]interface{}
+			if err := c.Get("/api/scans/"+s.ScanID, &detail); err != nil {
+				return
+			}
+			var logs []map[string]interface{}
+			if failureReason(detail, nil) == "" {
+				var resp interface{}
+				if err := c.Get("/api/scans/"+s.ScanID+"/logs", &resp); err == nil {
+					logs, _ = logItems(resp)
+				}
+			}
+			s.Reason = failureReason(detail, logs)
+		}(&scans[i])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+// latestScanNamed returns the most recently started scan called name, or nil
+// if there is none.
+func latestScanNamed(scans []scanSummary, name string) *scanSummary {
+	var best *scanSummary
+	for i, s := range scans {
+		if s.Name == name && (best == nil || s.StartedAt > best.StartedAt) {
+			best = &scans[i]
+		}
+	}
+	return best
+}
+
+// fetchScanBase finds the latest scan called name for 'sf scan start
+// --from-scan' and fetches the modules and options it ran with.
+func fetchScanBase(c *client.Client, name string) (*scanSummary, *scanConfig, error) {
+	scans, err := fetchScanList(c, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, nil, err
+	}
+	base := latestScanNamed(scans, name)
+	if base == nil {
+		return nil, nil, fmt.Errorf("no scan named %q", name)
+	}
+	if err := validateSafeID(base.ScanID, "scan ID"); err != nil {
+		return nil, nil, err
+	}
+	var resp scanOptionsResp
+	if err := c.Get(fmt.Sprintf("/api/scans/%s/options", base.ScanID), &resp); err != nil {
+		return nil, nil, notFound(err, "scan", base.ScanID)
+	}
+	return base, resp.scanConfig(), nil
+}
+
+// mergeScanConfig returns the options of base overridden by overrides, or nil
+// if both are empty.
+func mergeScanConfig(base, overrides map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
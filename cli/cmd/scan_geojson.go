@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+)
+
+type geoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// eventCoordinates returns an event's latitude and longitude, taken from
+// numeric latitude/longitude fields or, for PHYSICAL_COORDINATES events, from
+// data of the form "lat, long".
+func eventCoordinates(e map[string]interface{}) (lat, lon float64, ok bool) {
+	lat, latOK := e["latitude"].(float64)
+	lon, lonOK := e["longitude"].(float64)
+	if !latOK || !lonOK {
+		if e["type"] != "PHYSICAL_COORDINATES" {
+			return 0, 0, false
+		}
+		data, _ := e["data"].(string)
+		a, b, found := strings.Cut(data, ",")
+		if !found {
+			return 0, 0, false
+		}
+		var err error
+		if lat, err = strconv.ParseFloat(strings.TrimSpace(a), 64); err != nil {
+			return 0, 0, false
+		}
+		if lon, err = strconv.ParseFloat(strings.TrimSpace(b), 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// eventsGeoJSON builds a FeatureCollection with a point for each event that
+// has coordinates, returning it with the number of events skipped.
+func eventsGeoJSON(events []map[string]interface{}) (geoJSONCollection, int) {
+	fc := geoJSONCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	skipped := 0
+	for _, e := range events {
+		lat, lon, ok := eventCoordinates(e)
+		if !ok {
+			skipped++
+			continue
+		}
+		props := map[string]interface{}{"type": e["type"], "data": e["data"]}
+		for _, k := range []string{"module", "hash", "generated"} {
+			if v, ok := e[k]; ok {
+				props[k] = v
+			}
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type: "Feature",
+			// GeoJSON positions are longitude first.
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: []float64{lon, lat}},
+			Properties: props,
+		})
+	}
+	return fc, skipped
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// iocKinds maps the SpiderFoot event types treated as indicators of
+// compromise to the kind of indicator they carry.
+var iocKinds = map[string]string{
+	"IP_ADDRESS":              "ip",
+	"IPV6_ADDRESS":            "ip",
+	"AFFILIATE_IPADDR":        "ip",
+	"AFFILIATE_IPV6_ADDRESS":  "ip",
+	"INTERNET_NAME":           "domain",
+	"DOMAIN_NAME":             "domain",
+	"AFFILIATE_INTERNET_NAME": "domain",
+	"AFFILIATE_DOMAIN_NAME":   "domain",
+	"CO_HOSTED_SITE":          "domain",
+	"EMAILADDR":               "email",
+	"AFFILIATE_EMAILADDR":     "email",
+	"HASH":                    "hash",
+	"LINKED_URL_INTERNAL":     "url",
+	"LINKED_URL_EXTERNAL":     "url",
+}
+
+type ioc struct {
+	Kind      string `json:"kind"`
+	Indicator string `json:"indicator"`
+	EventType string `json:"event_type"`
+}
+
+// extractIOCs returns the distinct indicators in events, sorted by kind and
+// value, defanging them if requested.
+func extractIOCs(events []map[string]interface{}, defang bool) []ioc {
+	seen := make(map[string]bool)
+	var iocs []ioc
+	for _, e := range events {
+		eventType := fmt.Sprintf("%v", e["type"])
+		kind, ok := iocKinds[eventType]
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(fmt.Sprintf("%v", e["data"]))
+		if value == "" || seen[kind+"|"+value] {
+			continue
+		}
+		seen[kind+"|"+value] = true
+		if defang {
+			value = defangIOC(kind, value)
+		}
+		iocs = append(iocs, ioc{Kind: kind, Indicator: value, EventType: eventType})
+	}
+	sort.SliceStable(iocs, func(i, j int) bool {
+		if iocs[i].Kind != iocs[j].Kind {
+			return iocs[i].Kind < iocs[j].Kind
+		}
+		return iocs[i].Indicator < iocs[j].Indicator
+	})
+	return iocs
+}
+
+// defangIOC rewrites an indicator so it cannot be clicked or resolved by
+// accident: dots become [.], @ becomes [@], IPv6 colons become [:], and
+// http(s) URLs use the hxxp(s) scheme. Hashes are returned unchanged.
+func defangIOC(kind, value string) string {
+	switch kind {
+	case "ip":
+		if strings.Contains(value, ":") {
+			return strings.ReplaceAll(value, ":", "[:]")
+		}
+		return strings.ReplaceAll(value, ".", "[.]")
+	case "domain":
+		return strings.ReplaceAll(value, ".", "[.]")
+	case "email":
+		return strings.ReplaceAll(strings.ReplaceAll(value, ".", "[.]"), "@", "[@]")
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" {
+			return strings.ReplaceAll(value, ".", "[.]")
+		}
+		scheme := strings.Replace(u.Scheme, "http", "hxxp", 1)
+		rest := strings.TrimPrefix(value, u.Scheme+"://")
+		host := strings.ReplaceAll(u.Host, ".", "[.]")
+		return scheme + "://" + host + strings.TrimPrefix(rest, u.Host)
+	}
+	return value
+}
+
+// printIOCs writes indicators one per line, or with their kind and event
+// type in CSV and JSON output.
+func printIOCs(iocs []ioc) {
+	switch output.Current() {
+	case output.JSON, output.YAML:
+		if iocs == nil {
+			iocs = []ioc{}
+		}
+		output.PrintStructured(iocs)
+	case output.CSV:
+		rows := make([][]string, 0, len(iocs))
+		for _, i := range iocs {
+			rows = append(rows, []string{i.Kind, i.Indicator, i.EventType})
+		}
+		output.PrintCSV([]string{"kind", "indicator", "event_type"}, rows)
+	default:
+		for _, i := range iocs {
+			fmt.Println(i.Indicator)
+		}
+	}
+}
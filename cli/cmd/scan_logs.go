@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// logItems extracts the entries from a logs response, which is either a bare
+// array or an object with a "logs" array.
+func logItems(resp interface{}) ([]map[string]interface{}, bool) {
+	if m, ok := resp.(map[string]interface{}); ok {
+		resp = m["logs"]
+	}
+	items, ok := resp.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	logs := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			logs = append(logs, m)
+		}
+	}
+	return logs, true
+}
+
+// logLine formats a log entry, given either as {timestamp, level, message} or
+// as a scan log row {generated, type, component, message}.
+func logLine(m map[string]interface{}) string {
+	if _, ok := m["timestamp"]; ok {
+		return fmt.Sprintf("[%v] %v: %v", m["timestamp"], m["level"], m["message"])
+	}
+	generated, _ := m["generated"].(float64)
+	if generated > 1e12 {
+		// Scan log rows are stamped in milliseconds.
+		generated /= 1000
+	}
+	return fmt.Sprintf("[%s] %v %v: %v", time.Unix(int64(generated), 0).Format("2006-01-02 15:04:05"),
+		m["type"], m["component"], m["message"])
+}
+
+// followScanLogs polls a scan and its logs every interval until the scan
+// finishes or the user interrupts. On a terminal the scan detail is redrawn at
+// the top with as much of the log tail as fits below it; otherwise status
+// changes and new log lines are printed as they arrive.
+func followScanLogs(c *client.Client, scanID string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ctx := commandContext()
+
+	live := output.Interactive(os.Stdout)
+	if live {
+		fmt.Print("\033[?25l")
+		defer fmt.Print("\033[?25h")
+	}
+
+	var lines []string
+	printed, lastStatus := 0, ""
+	for refresh := 0; ; refresh++ {
+		var s scanDetail
+		if err := c.Get(fmt.Sprintf("/api/scans/%s", scanID), &s); err != nil {
+			return notFound(err, "scan", scanID)
+		}
+		var resp interface{}
+		if err := c.Get(fmt.Sprintf("/api/scans/%s/logs", scanID), &resp); err != nil {
+			return notFound(err, "scan", scanID)
+		}
+		items, ok := logItems(resp)
+		if !ok {
+			return fmt.Errorf("unexpected logs response for scan %s", scanID)
+		}
+		// The full log is returned each time; a shorter one means it was
+		// trimmed, so start over rather than skip entries.
+		if len(items) < len(lines) {
+			lines, printed = nil, 0
+		}
+		for _, m := range items[len(lines):] {
+			lines = append(lines, logLine(m))
+		}
+
+		if live {
+			var top bytes.Buffer
+			fmt.Fprintf(&top, "Every %s — updated %s (Ctrl-C to exit)\n\n", interval, time.Now().Format("15:04:05"))
+			printScanDetail(&top, s)
+			fmt.Fprintln(&top, strings.Repeat("─", 40))
+			room := max(output.TerminalHeight()-strings.Count(top.String(), "\n")-1, 1)
+			fmt.Print("\033[H\033[2J")
+			fmt.Print(top.String())
+			for _, l := range lines[max(len(lines)-room, 0):] {
+				fmt.Println(l)
+			}
+		} else {
+			status := fmt.Sprintf("%s  %d%%  modules %d/%d  events %d", colorStatus(s.Status), s.Progress, s.ModulesDone, s.ModulesTotal, s.EventCount)
+			switch {
+			case refresh == 0:
+				printScanDetail(os.Stdout, s)
+				fmt.Println()
+			case status != lastStatus:
+				fmt.Printf("%s  %s\n", time.Now().Format("15:04:05"), status)
+			}
+			lastStatus = status
+			for _, l := range lines[printed:] {
+				fmt.Println(l)
+			}
+			printed = len(lines)
+		}
+
+		if scanDone(s.Status) {
+			output.Success("Scan %s %s", scanID, s.Status)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
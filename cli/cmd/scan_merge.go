@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// mergedEvent is a distinct type+data pair seen across one or more scans.
+type mergedEvent struct {
+	Type      string   `json:"type"`
+	Data      string   `json:"data"`
+	Modules   []string `json:"modules"`
+	Scans     []string `json:"scans"`
+	FirstSeen float64  `json:"first_seen"`
+	LastSeen  float64  `json:"last_seen"`
+}
+
+// mergeEvents de-duplicates events from several scans by type and data,
+// recording which scans and modules produced each. eventsByScan is keyed by
+// scan ID; scanIDs fixes the order scans are listed in. The result is sorted
+// by type, then data.
+func mergeEvents(scanIDs []string, eventsByScan map[string][]map[string]interface{}) []mergedEvent {
+	byKey := make(map[string]*mergedEvent)
+	for _, id := range scanIDs {
+		for _, e := range eventsByScan[id] {
+			typ, data := fmt.Sprintf("%v", e["type"]), fmt.Sprintf("%v", e["data"])
+			key := typ + "\x00" + data
+			m, ok := byKey[key]
+			if !ok {
+				m = &mergedEvent{Type: typ, Data: data}
+				byKey[key] = m
+			}
+			if len(m.Scans) == 0 || m.Scans[len(m.Scans)-1] != id {
+				m.Scans = append(m.Scans, id)
+			}
+			if mod := fmt.Sprintf("%v", e["module"]); e["module"] != nil && !slices.Contains(m.Modules, mod) {
+				m.Modules = append(m.Modules, mod)
+			}
+			if g, ok := e["generated"].(float64); ok {
+				if m.FirstSeen == 0 || g < m.FirstSeen {
+					m.FirstSeen = g
+				}
+				if g > m.LastSeen {
+					m.LastSeen = g
+				}
+			}
+		}
+	}
+
+	merged := make([]mergedEvent, 0, len(byKey))
+	for _, m := range byKey {
+		merged = append(merged, *m)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Type != merged[j].Type {
+			return merged[i].Type < merged[j].Type
+		}
+		return merged[i].Data < merged[j].Data
+	})
+	return merged
+}
+
+// writeMergedEvents writes merged events to path as CSV if it ends in .csv,
+// otherwise as JSON.
+func writeMergedEvents(path string, merged []mergedEvent) error {
+	return writeFileAtomic(path, func(f *os.File) error {
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			w := csv.NewWriter(f)
+			_ = w.Write([]string{"type", "data", "modules", "scans", "first_seen", "last_seen"})
+			for _, m := range merged {
+				_ = w.Write([]string{m.Type, m.Data, strings.Join(m.Modules, ";"), strings.Join(m.Scans, ";"),
+					formatEpoch(m.FirstSeen), formatEpoch(m.LastSeen)})
+			}
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return fmt.Errorf("writing file: %w", err)
+			}
+			return nil
+		}
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(merged); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		return nil
+	})
+}
+
+var scanMergeCmd = &cobra.Command{
+	Use:   "merge [scan-id] [scan-id]...",
+	Short: "Combine events from several scans into one de-duplicated view",
+	Long: `Combine events from several scans into one de-duplicated view.
+
+Events are merged by type and data. Each merged event lists the scans and
+modules that produced it and when it was first and last seen, which is useful
+for tracking the same target across runs. Use --export to write the result to
+a file (CSV if the name ends in .csv, JSON otherwise).`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventType, _ := cmd.Flags().GetString("type")
+		exportFile, _ := cmd.Flags().GetString("export")
+		for _, id := range args {
+			if err := validateSafeID(id, "scan ID"); err != nil {
+				return err
+			}
+		}
+
+		c := client.New()
+		stop := output.StartSpinner(fmt.Sprintf("Fetching events from %d scans...", len(args)))
+		eventsByScan, err := fetchScanEvents(c, args, eventType)
+		stop()
+		if err != nil {
+			return err
+		}
+		merged := mergeEvents(args, eventsByScan)
+
+		if exportFile != "" {
+			if err := writeMergedEvents(exportFile, merged); err != nil {
+				return err
+			}
+			output.Success("Exported %d merged events to %s", len(merged), exportFile)
+			return nil
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(merged)
+		case output.CSV:
+			header := []string{"Type", "Data", "Modules", "Scans", "First Seen", "Last Seen"}
+			rows := make([][]string, 0, len(merged))
+			for _, m := range merged {
+				rows = append(rows, []string{m.Type, m.Data, strings.Join(m.Modules, ";"), strings.Join(m.Scans, ";"), formatEpoch(m.FirstSeen), formatEpoch(m.LastSeen)})
+			}
+			output.PrintCSV(header, rows)
+		default:
+			header := []string{"Type", "Data", "Seen In", "Scans", "Last Seen"}
+			rows := make([][]string, 0, len(merged))
+			for _, m := range merged {
+				ids := make([]string, len(m.Scans))
+				for i, id := range m.Scans {
+					ids[i] = truncID(id)
+				}
+				rows = append(rows, []string{m.Type, truncateCell(m.Data, 60), fmt.Sprintf("%d/%d", len(m.Scans), len(args)), strings.Join(ids, ","), formatEpoch(m.LastSeen)})
+			}
+			output.PrintTable(header, rows)
+			fmt.Printf("\n%d distinct events across %d scans\n", len(merged), len(args))
+		}
+		return nil
+	},
+}
+
+// fetchScanEvents retrieves the events of each scan, optionally filtered by type.
+func fetchScanEvents(c *client.Client, scanIDs []string, eventType string) (map[string][]map[string]interface{}, error) {
+	eventsByScan := make(map[string][]map[string]interface{}, len(scanIDs))
+	for _, id := range scanIDs {
+		path := fmt.Sprintf("/api/scans/%s/events", id)
+		if eventType != "" {
+			path += "?type=" + eventType
+		}
+		var resp interface{}
+		if err := c.Get(path, &resp); err != nil {
+			return nil, notFound(err, "scan", id)
+		}
+		events, ok := eventItems(resp)
+		if !ok {
+			return nil, fmt.Errorf("unexpected events response for scan %s", id)
+		}
+		eventsByScan[id] = events
+	}
+	return eventsByScan, nil
+}
+
+func init() {
+	scanMergeCmd.Flags().String("type", "", "Only merge events of this type")
+	scanMergeCmd.Flags().String("export", "", "Write merged events to this file (.csv for CSV, otherwise JSON)")
+
+	scanCmd.AddCommand(scanMergeCmd)
+}
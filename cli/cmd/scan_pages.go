@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+// maxScanPageSize is the largest page of scans the server returns; it
+// rejects a larger limit.
+const maxScanPageSize = 1000
+
+// scanPage is one page of the scan list, as asked for with --limit and
+// --offset.
+type scanPage struct {
+	Scans []scanSummary
+	// Returned is how many scans the page held before client-side filtering.
+	Returned int
+	// Total is how many scans there are in all, or -1 if the server did not
+	// say.
+	Total int
+	// More reports whether scans follow the page.
+	More bool
+	// Envelope is the response object, kept for JSON output, and Field the
+	// member of it that holds the scans.
+	Envelope map[string]interface{}
+	Field    string
+}
+
+// fetchScanPage fetches the scans from offset on, at most limit of them (0 =
+// as many as the server pages by default). A server that does not paginate
+// returns every scan, which is then paged here.
+func fetchScanPage(c *client.Client, query url.Values, limit, offset int) (*scanPage, error) {
+	params := url.Values{}
+	for k, v := range query {
+		params[k] = v
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+	var raw json.RawMessage
+	if err := c.Get("/api/scans?"+params.Encode(), &raw); err != nil {
+		return nil, err
+	}
+	var resp scansResp
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	page := &scanPage{Scans: resp.list(), Total: -1, Field: "scans"}
+	json.Unmarshal(raw, &page.Envelope)
+	if page.Envelope == nil {
+		page.Envelope = make(map[string]interface{})
+	}
+	if resp.Items != nil {
+		page.Field = "items"
+	}
+	if resp.Total != nil {
+		page.Total = *resp.Total
+	}
+	switch {
+	case resp.HasNext != nil:
+		page.More = *resp.HasNext
+	case resp.Total == nil:
+		// The server ignored limit and offset and sent every scan.
+		all := page.Scans
+		start := min(offset, len(all))
+		end := len(all)
+		if limit > 0 {
+			end = min(start+limit, end)
+		}
+		page.Scans = all[start:end]
+		page.Total = len(all)
+		page.More = end < len(all)
+	default:
+		page.More = offset+len(page.Scans) < page.Total
+	}
+	page.Returned = len(page.Scans)
+	return page, nil
+}
+
+// scanPageFooter describes which scans a page showed, e.g. "Showing 51-100
+// of 1240", and how to fetch the next page, if there is one.
+func scanPageFooter(page *scanPage, limit, offset int) (footer, hint string) {
+	if page.Returned == 0 {
+		return "", ""
+	}
+	footer = fmt.Sprintf("Showing %d-%d", offset+1, offset+page.Returned)
+	if page.Total >= 0 {
+		footer += fmt.Sprintf(" of %d", page.Total)
+	}
+	if page.More {
+		if limit == 0 {
+			limit = page.Returned
+		}
+		hint = fmt.Sprintf("Next page: sf scan list --limit %d --offset %d", limit, offset+page.Returned)
+	}
+	return footer, hint
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// scanUsage is the resource usage of one scan. Stats is nil when the server
+// reported none for it.
+type scanUsage struct {
+	ScanID string                 `json:"scan_id"`
+	Target string                 `json:"target"`
+	Status string                 `json:"status"`
+	Stats  map[string]interface{} `json:"stats"`
+}
+
+// The stats keys each column is read from, in order of preference; servers
+// name the same figure differently.
+var (
+	usageCPUKeys      = []string{"cpu_percent", "cpu"}
+	usageMemoryKeys   = []string{"memory_mb", "memory_rss_mb", "rss_mb"}
+	usageRequestsKeys = []string{"requests", "request_count", "http_requests"}
+)
+
+// usageCell formats the first of keys present in stats, or "-".
+func usageCell(stats map[string]interface{}, keys []string) string {
+	for _, k := range keys {
+		switch v := stats[k].(type) {
+		case nil:
+			continue
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return "-"
+}
+
+// fetchScanUsage fills in the stats of each scan from /api/scans/{id}/stats,
+// concurrently. It returns false when the server has no stats endpoint, which
+// shows as every request answering 404.
+func fetchScanUsage(c *client.Client, usage []scanUsage) (bool, error) {
+	sem := make(chan struct{}, resolveConcurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	missing := 0
+	for i := range usage {
+		wg.Add(1)
+		go func(u *scanUsage) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var stats map[string]interface{}
+			err := c.Get(fmt.Sprintf("/api/scans/%s/stats", u.ScanID), &stats)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, client.ErrNotFound):
+				missing++
+			case err != nil:
+				if firstErr == nil {
+					firstErr = fmt.Errorf("scan %s: %w", u.ScanID, err)
+				}
+			default:
+				// Some endpoints wrap their figures: {"scan_id": ..., "stats": {...}}.
+				if inner, ok := stats["stats"].(map[string]interface{}); ok {
+					stats = inner
+				}
+				u.Stats = stats
+			}
+		}(&usage[i])
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return false, firstErr
+	}
+	return len(usage) == 0 || missing < len(usage), nil
+}
+
+var scanResourceUsageCmd = &cobra.Command{
+	Use:   "resource-usage [scan-id]",
+	Short: "Show CPU, memory and request counts per scan",
+	Long: `Show CPU, memory and request counts per scan.
+
+Usage comes from the server's per-scan stats (GET /api/scans/{id}/stats),
+for one scan or, without a scan ID, for every scan (--running for only the
+active ones). Figures the server does not report show as "-".`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		running, _ := cmd.Flags().GetBool("running")
+		c := client.New()
+
+		var usage []scanUsage
+		if len(args) == 1 {
+			if running {
+				return fmt.Errorf("--running only applies without a scan ID")
+			}
+			if err := validateSafeID(args[0], "scan ID"); err != nil {
+				return err
+			}
+			var s scanDetail
+			if err := c.Get("/api/scans/"+args[0], &s); err != nil {
+				return notFound(err, "scan", args[0])
+			}
+			usage = append(usage, scanUsage{ScanID: s.ScanID, Target: s.Target, Status: s.Status})
+		} else {
+			scans, err := fetchScanList(c, time.Time{}, time.Time{})
+			if err != nil {
+				return err
+			}
+			for _, s := range scans {
+				if (running && scanDone(s.Status)) || validateSafeID(s.ScanID, "scan ID") != nil {
+					continue
+				}
+				usage = append(usage, scanUsage{ScanID: s.ScanID, Target: s.Target, Status: s.Status})
+			}
+		}
+
+		stop := output.StartSpinner("Fetching scan stats...")
+		available, err := fetchScanUsage(c, usage)
+		stop()
+		if err != nil {
+			return err
+		}
+		if !available {
+			output.Warn("This server does not report per-scan resource usage (GET /api/scans/{id}/stats)")
+		}
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(usage)
+		case output.CSV:
+			rows := make([][]string, 0, len(usage))
+			for _, u := range usage {
+				rows = append(rows, []string{u.ScanID, u.Target, u.Status,
+					usageCell(u.Stats, usageCPUKeys), usageCell(u.Stats, usageMemoryKeys), usageCell(u.Stats, usageRequestsKeys)})
+			}
+			output.PrintCSV([]string{"scan_id", "target", "status", "cpu_percent", "memory_mb", "requests"}, rows)
+		default:
+			rows := make([][]string, 0, len(usage))
+			for _, u := range usage {
+				rows = append(rows, []string{truncID(u.ScanID), u.Target, colorStatus(u.Status),
+					usageCell(u.Stats, usageCPUKeys), usageCell(u.Stats, usageMemoryKeys), usageCell(u.Stats, usageRequestsKeys)})
+			}
+			output.PrintTable([]string{"ID", "Target", "Status", "CPU %", "Memory MB", "Requests"}, rows)
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanResourceUsageCmd.Flags().Bool("running", false, "Without a scan ID, only show scans that have not ended")
+
+	scanCmd.AddCommand(scanResourceUsageCmd)
+}
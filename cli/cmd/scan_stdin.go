@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// stdinScanResult is one NDJSON line printed by scan start --stdin. Line is
+// the input line number, so results can be matched to requests even though
+// scans started concurrently finish in any order.
+type stdinScanResult struct {
+	Line    int    `json:"line"`
+	Target  string `json:"target,omitempty"`
+	ScanID  string `json:"scan_id,omitempty"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// parseStdinScan decodes one NDJSON scan request, filling unset fields from
+// the scan start flags.
+func parseStdinScan(cmd *cobra.Command, text string) (scanStartReq, error) {
+	var req scanStartReq
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid request: %w", err)
+	}
+
+	req.Target = strings.TrimSpace(req.Target)
+	if req.Target == "" {
+		return req, fmt.Errorf("target is required")
+	}
+	if req.ScanName == "" {
+		req.ScanName = "CLI scan: " + req.Target
+	}
+	if req.ScanType == "" {
+		req.ScanType, _ = cmd.Flags().GetString("type")
+	}
+	if len(req.Modules) == 0 {
+		if modules, _ := cmd.Flags().GetString("modules"); modules != "" {
+			req.Modules = strings.Split(modules, ",")
+		}
+	}
+	if len(req.Tags) == 0 {
+		req.Tags = scanTags(cmd)
+	}
+	for k, v := range scanTuningConfig(cmd) {
+		if req.Config == nil {
+			req.Config = make(map[string]interface{})
+		}
+		if _, ok := req.Config[k]; !ok {
+			req.Config[k] = v
+		}
+	}
+	return req, nil
+}
+
+// startScansFromStdin starts one scan per NDJSON line read from r, at most
+// --concurrency at a time, and prints a stdinScanResult per line.
+func startScansFromStdin(cmd *cobra.Command, c *client.Client, r io.Reader) error {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+
+	var since time.Time
+	var existing []scanSummary
+	if recent, _ := cmd.Flags().GetString("skip-if-recent"); recent != "" {
+		var err error
+		if since, err = parseTimeBound(recent, time.Now()); err != nil {
+			return fmt.Errorf("invalid --skip-if-recent: %w", err)
+		}
+		if existing, err = fetchScanList(c, time.Time{}, time.Time{}); err != nil {
+			return err
+		}
+	}
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(os.Stdout)
+	total, failed := 0, 0
+	emit := func(res stdinScanResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if res.Error != "" {
+			failed++
+		}
+		_ = enc.Encode(res)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		total++
+
+		req, err := parseStdinScan(cmd, text)
+		if err != nil {
+			emit(stdinScanResult{Line: line, Target: req.Target, Error: err.Error()})
+			continue
+		}
+		if !noValidate {
+			for _, w := range validateTarget(req.Target, "") {
+				output.Warn("line %d: %s", line, w)
+			}
+		}
+		if s := recentScan(existing, req.Target, since); s != nil && !since.IsZero() {
+			emit(stdinScanResult{Line: line, Target: req.Target, ScanID: s.ScanID, Skipped: true})
+			continue
+		}
+
+		// Wait for a free slot before reading on, so no more than
+		// concurrency goroutines exist however long the input is.
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(line int, req scanStartReq) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := stdinScanResult{Line: line, Target: req.Target}
+			payload, err := json.Marshal(req)
+			if err == nil {
+				var resp map[string]interface{}
+				if err = c.Post("/api/scans", bytes.NewReader(payload), &resp); err == nil {
+					res.ScanID = fmt.Sprintf("%v", resp["scan_id"])
+				}
+			}
+			if err != nil {
+				res.Error = err.Error()
+			} else {
+				mu.Lock()
+				recordHistory(cmd, []string{req.Target}, res.ScanID)
+				mu.Unlock()
+			}
+			emit(res)
+		}(line, req)
+	}
+	wg.Wait()
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scans failed to start", failed, total)
+	}
+	return nil
+}
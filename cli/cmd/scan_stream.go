@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+)
+
+// streamFlushEvery is how many events are buffered before stdout is flushed.
+const streamFlushEvery = 100
+
+// jsonArrayWriter writes a JSON array one element at a time, so a consumer
+// sees the first events before the last are fetched. Elements are indented
+// like PrintJSON, nested under prefix, unless --compact is set.
+type jsonArrayWriter struct {
+	w      *bufio.Writer
+	n      int
+	indent bool
+	prefix string
+}
+
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	return &jsonArrayWriter{w: bufio.NewWriter(w), indent: !viper.GetBool("compact")}
+}
+
+func (a *jsonArrayWriter) write(v interface{}) error {
+	var data []byte
+	var err error
+	if a.indent {
+		data, err = json.MarshalIndent(v, a.prefix+"  ", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case a.n == 0 && a.indent:
+		a.w.WriteString("[\n" + a.prefix + "  ")
+	case a.n == 0:
+		a.w.WriteString("[")
+	case a.indent:
+		a.w.WriteString(",\n" + a.prefix + "  ")
+	default:
+		a.w.WriteString(",")
+	}
+	a.w.Write(data)
+	a.n++
+	if a.n%streamFlushEvery == 0 {
+		return a.w.Flush()
+	}
+	return nil
+}
+
+// close ends the array, writing [] if nothing was written.
+func (a *jsonArrayWriter) close() {
+	switch {
+	case a.n == 0:
+		a.w.WriteString("[]")
+	case a.indent:
+		a.w.WriteString("\n" + a.prefix + "]")
+	default:
+		a.w.WriteString("]")
+	}
+}
+
+// streamEvents writes a scan's events to stdout while they are fetched, as
+// {"events": [...], "total": N} like the buffered output: page by page with
+// batchSize, or else decoded one at a time from a single download, so memory
+// use does not grow with the scan. At most limit events are written (all when
+// limit is 0). Events are dropped unless filter, if set, matches them, and
+// annotated with type_description when descriptions is non-nil.
+func streamEvents(c *client.Client, scanID, path string, eventType string, batchSize, limit int, filter *eventFilter, descriptions map[string]string) error {
+	out := newJSONArrayWriter(os.Stdout)
+	if out.indent {
+		out.prefix = "  "
+		out.w.WriteString("{\n  \"events\": ")
+	} else {
+		out.w.WriteString(`{"events":`)
+	}
+	written := 0
+	emit := func(m map[string]interface{}) error {
+		if filter != nil && !filter.match(m) {
+			return nil
+		}
+		if descriptions != nil {
+			m["type_description"] = descriptions[fmt.Sprintf("%v", m["type"])]
+		}
+		if err := out.write(m); err != nil {
+			return err
+		}
+		if written++; limit > 0 && written >= limit {
+			return errStopEvents
+		}
+		return nil
+	}
+
+	var err error
+	if batchSize > 0 {
+		err = eachEventPage(c, scanID, eventType, batchSize, limit, func(page []map[string]interface{}) error {
+			for _, m := range page {
+				if err := emit(m); err != nil {
+					return err
+				}
+			}
+			return out.w.Flush()
+		})
+	} else {
+		// Like exports, a long stream is bounded by Ctrl-C rather than --timeout.
+		pr, pw := io.Pipe()
+		go func() {
+			_, err := c.Download(commandContext(), path, pw)
+			pw.CloseWithError(err)
+		}()
+		err = decodeEventStream(pr, emit)
+		pr.Close()
+		err = notFound(err, "scan", scanID)
+	}
+	if errors.Is(err, errStopEvents) {
+		err = nil
+	}
+	// Close the object even on failure, so what was written stays valid JSON.
+	out.close()
+	if out.indent {
+		fmt.Fprintf(out.w, ",\n  \"total\": %d\n}\n", out.n)
+	} else {
+		fmt.Fprintf(out.w, `,"total":%d}`+"\n", out.n)
+	}
+	if ferr := out.w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
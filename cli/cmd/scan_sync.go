@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// scanSyncFile returns the path of the per-server watermarks kept by
+// 'sf scan list --sync'.
+func scanSyncFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "scan-sync.json"), nil
+}
+
+// loadSyncWatermarks reads the sync watermarks, keyed by server URL, as
+// epoch seconds. A missing file means no server has been synced yet.
+func loadSyncWatermarks() (map[string]float64, error) {
+	marks := make(map[string]float64)
+	path, err := scanSyncFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return marks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading sync state: %w", err)
+	}
+	if err := json.Unmarshal(data, &marks); err != nil {
+		return nil, fmt.Errorf("reading sync state %s: %w", path, err)
+	}
+	return marks, nil
+}
+
+// saveSyncWatermark records mark as the watermark of server, keeping the
+// other servers' watermarks.
+func saveSyncWatermark(server string, mark float64) error {
+	marks, err := loadSyncWatermarks()
+	if err != nil {
+		return err
+	}
+	marks[server] = mark
+	data, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := scanSyncFile()
+	if err != nil {
+		return err
+	}
+	if err := writeBytesAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("saving sync state: %w", err)
+	}
+	return nil
+}
+
+// scansChangedAfter returns the scans that started or ended after the epoch
+// after, and the latest such time across all scans (after itself if none is
+// later), which becomes the next watermark. Using the server's own timestamps
+// rather than the local clock keeps clock skew from dropping scans.
+func scansChangedAfter(scans []scanSummary, after float64) ([]scanSummary, float64) {
+	changed := []scanSummary{}
+	mark := after
+	for _, s := range scans {
+		at := scanFinishedAt(s)
+		if at > after {
+			changed = append(changed, s)
+		}
+		mark = max(mark, at)
+	}
+	return changed, mark
+}
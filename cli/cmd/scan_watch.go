@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// scanProgressLine summarizes a scan's progress on one line, e.g.
+// "RUNNING  40%  modules 4/10  events 312  running for 5m02s".
+func scanProgressLine(s scanDetail, now time.Time) string {
+	line := fmt.Sprintf("%s  %s  modules %d/%d  events %d", colorStatus(s.Status),
+		output.ProgressBar(s.Progress, 20), s.ModulesDone, s.ModulesTotal, s.EventCount)
+	if label, d, ok := scanElapsed(s, now); ok {
+		line += fmt.Sprintf("  %s %s", strings.ToLower(label), humanDuration(d))
+	}
+	return line
+}
+
+// watchScan polls scan id every interval until it ends, calling render with
+// each detail fetched, and returns the final detail. It gives up with an
+// error after timeout, unless timeout is 0, and returns the context's error
+// when interrupted.
+func watchScan(ctx context.Context, c *client.Client, id string, interval, timeout time.Duration, render func(scanDetail)) (scanDetail, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var s scanDetail
+	for {
+		err := c.GetContext(ctx, fmt.Sprintf("/api/scans/%s", id), &s)
+		if err == nil {
+			render(s)
+			if scanDone(s.Status) {
+				return s, nil
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(interval):
+				continue
+			}
+		}
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			if s.Status == "" {
+				return s, fmt.Errorf("timed out after %s waiting for scan %s", timeout, id)
+			}
+			return s, fmt.Errorf("timed out after %s; scan %s is still %s (%d%%)", timeout, id, s.Status, s.Progress)
+		case ctx.Err() != nil:
+			return s, ctx.Err()
+		}
+		return s, notFound(err, "scan", id)
+	}
+}
+
+var scanWatchCmd = &cobra.Command{
+	Use:   "watch [scan-id]",
+	Short: "Follow a scan's progress until it finishes",
+	Long: `Follow a scan's progress until it finishes.
+
+Polls the scan every --interval and shows its status, progress, modules done
+and event count, redrawn in place on a terminal and printed as it changes
+otherwise. Exits 0 when the scan finishes and non-zero when it fails or is
+aborted, so CI jobs can block on a scan. --wait-timeout gives up after a
+while; the global --timeout still bounds each request.
+
+With -o json or -o yaml, only the final scan detail is printed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+		if err := validateSafeID(id, "scan ID"); err != nil {
+			return err
+		}
+		interval, _ := cmd.Flags().GetDuration("interval")
+		timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if timeout < 0 {
+			return fmt.Errorf("--wait-timeout must not be negative")
+		}
+
+		// Redraw one line in place on an interactive terminal; otherwise print
+		// a line whenever the progress changes.
+		quiet := output.Structured()
+		live := !quiet && output.Interactive(os.Stdout)
+		var drawn bool
+		var last scanDetail
+		render := func(s scanDetail) {
+			switch {
+			case quiet:
+			case live:
+				fmt.Printf("\r\033[K%s", scanProgressLine(s, time.Now()))
+				drawn = true
+			case !drawn || s.Status != last.Status || s.Progress != last.Progress ||
+				s.ModulesDone != last.ModulesDone || s.EventCount != last.EventCount:
+				fmt.Println(scanProgressLine(s, time.Now()))
+				drawn = true
+			}
+			last = s
+		}
+
+		s, err := watchScan(commandContext(), client.New(), id, interval, timeout, render)
+		if live && drawn {
+			fmt.Println()
+		}
+		if err != nil {
+			return err
+		}
+		if quiet {
+			output.PrintStructured(s)
+		}
+		if status := strings.ToUpper(s.Status); status != "FINISHED" && status != "COMPLETED" {
+			return fmt.Errorf("scan %s ended with status %s", id, s.Status)
+		}
+		if !quiet {
+			output.Success("Scan %s %s", id, s.Status)
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanWatchCmd.Flags().Duration("interval", 5*time.Second, "Polling interval")
+	scanWatchCmd.Flags().Duration("wait-timeout", 0, "Give up if the scan has not ended after this long (0 = wait indefinitely)")
+
+	scanCmd.AddCommand(scanWatchCmd)
+}
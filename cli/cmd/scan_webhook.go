@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// webhookAttempts is how many times a webhook delivery is tried before the
+// batch is given up on.
+const webhookAttempts = 3
+
+// eventForwarder POSTs events seen by --follow to a --webhook URL, as one
+// JSON object per request or, with a batch size above one, as JSON arrays.
+type eventForwarder struct {
+	url       string
+	header    http.Header
+	batch     int
+	http      *http.Client
+	delivered int
+	failed    int
+}
+
+// newEventForwarder builds a forwarder from the --webhook flags, or returns
+// nil when no webhook is set.
+func newEventForwarder(cmd *cobra.Command) (*eventForwarder, error) {
+	target, _ := cmd.Flags().GetString("webhook")
+	if target == "" {
+		return nil, nil
+	}
+	if !validWebhookURL(target) {
+		return nil, fmt.Errorf("invalid --webhook %q: must be an http or https URL", target)
+	}
+	batch, _ := cmd.Flags().GetInt("webhook-batch")
+	if batch < 1 {
+		return nil, fmt.Errorf("--webhook-batch must be at least 1")
+	}
+
+	header := make(http.Header)
+	headers, _ := cmd.Flags().GetStringArray("webhook-header")
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --webhook-header %q: use \"Name: value\"", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return &eventForwarder{
+		url:    target,
+		header: header,
+		batch:  batch,
+		http:   &http.Client{Timeout: viper.GetDuration("timeout")},
+	}, nil
+}
+
+// validWebhookURL reports whether target is an absolute http or https URL.
+func validWebhookURL(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// forward delivers events in batches. A batch that still fails after
+// webhookAttempts tries is reported and dropped so following carries on.
+func (f *eventForwarder) forward(ctx context.Context, events []map[string]interface{}) {
+	for start := 0; start < len(events); start += f.batch {
+		chunk := events[start:min(start+f.batch, len(events))]
+		var payload []byte
+		if f.batch == 1 {
+			payload, _ = json.Marshal(chunk[0])
+		} else {
+			payload, _ = json.Marshal(chunk)
+		}
+		if err := f.deliver(ctx, payload); err != nil {
+			f.failed += len(chunk)
+			if ctx.Err() == nil {
+				output.Warn("webhook delivery of %d event(s) failed: %v", len(chunk), err)
+			}
+			continue
+		}
+		f.delivered += len(chunk)
+	}
+}
+
+// deliver POSTs one payload, retrying network errors, 429 and 5xx responses
+// with a growing pause between attempts.
+func (f *eventForwarder) deliver(ctx context.Context, payload []byte) error {
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt-1) * time.Second):
+			}
+		}
+		var retry bool
+		if retry, err = f.post(ctx, payload); err == nil || !retry {
+			return err
+		}
+	}
+	return fmt.Errorf("%w (after %d attempts)", err, webhookAttempts)
+}
+
+// post makes a single delivery attempt, reporting whether a failure is worth
+// retrying.
+func (f *eventForwarder) post(ctx context.Context, payload []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	for name, values := range f.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SpiderFoot-CLI/"+version)
+
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+			fmt.Errorf("webhook answered HTTP %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// Values of scan start --notify-on.
+const (
+	notifyAll      = "all"
+	notifyFailed   = "failed"
+	notifyFinished = "finished"
+)
+
+// scanNotification is the payload scan start --notify-webhook POSTs when a
+// waited-for scan ends.
+type scanNotification struct {
+	ScanID          string  `json:"scan_id"`
+	Name            string  `json:"name"`
+	Target          string  `json:"target"`
+	Status          string  `json:"status"`
+	Succeeded       bool    `json:"succeeded"`
+	EventCount      int     `json:"event_count"`
+	Started         float64 `json:"started"`
+	Ended           float64 `json:"ended"`
+	DurationSeconds int64   `json:"duration_seconds"`
+	Duration        string  `json:"duration"`
+}
+
+// scanNotifier POSTs a scanNotification to a webhook when a scan ends, for
+// every outcome or only failed or finished ones.
+type scanNotifier struct {
+	fwd *eventForwarder
+	on  string
+}
+
+// newScanNotifier builds a notifier from --notify-webhook and --notify-on, or
+// returns nil when no webhook is set.
+func newScanNotifier(cmd *cobra.Command) (*scanNotifier, error) {
+	target, _ := cmd.Flags().GetString("notify-webhook")
+	on, _ := cmd.Flags().GetString("notify-on")
+	if target == "" {
+		if cmd.Flags().Changed("notify-on") {
+			return nil, fmt.Errorf("--notify-on requires --notify-webhook")
+		}
+		return nil, nil
+	}
+	if !validWebhookURL(target) {
+		return nil, fmt.Errorf("invalid --notify-webhook %q: must be an http or https URL", target)
+	}
+	switch on {
+	case notifyAll, notifyFailed, notifyFinished:
+	default:
+		return nil, fmt.Errorf("invalid --notify-on %q (valid: %s, %s, %s)", on, notifyAll, notifyFailed, notifyFinished)
+	}
+	return &scanNotifier{
+		fwd: &eventForwarder{url: target, header: make(http.Header), batch: 1, http: &http.Client{Timeout: viper.GetDuration("timeout")}},
+		on:  on,
+	}, nil
+}
+
+// notify reports the outcome of s, if --notify-on covers it. A delivery that
+// fails after retries is reported as a warning; the scan's own outcome decides
+// the exit status.
+func (n *scanNotifier) notify(ctx context.Context, s scanDetail) {
+	status := strings.ToUpper(s.Status)
+	succeeded := status == "FINISHED" || status == "COMPLETED"
+	if (n.on == notifyFailed && succeeded) || (n.on == notifyFinished && !succeeded) {
+		return
+	}
+	payload := scanNotification{
+		ScanID: s.ScanID, Name: s.Name, Target: s.Target, Status: s.Status, Succeeded: succeeded,
+		EventCount: s.EventCount, Started: s.StartedAt, Ended: s.EndedAt,
+	}
+	if label, d, ok := scanElapsed(s, time.Now()); ok && label == "Duration" {
+		payload.DurationSeconds = int64(d.Round(time.Second) / time.Second)
+		payload.Duration = humanDuration(d)
+	}
+	data, _ := json.Marshal(payload)
+	if err := n.fwd.deliver(ctx, data); err != nil && ctx.Err() == nil {
+		output.Warn("completion webhook delivery failed: %v", err)
+	}
+}
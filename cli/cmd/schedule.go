@@ -32,11 +32,11 @@ type schedulesResp struct {
 }
 
 type scheduleCreateReq struct {
-	Name          string  `json:"name"`
-	Target        string  `json:"target"`
-	IntervalHours float64 `json:"interval_hours"`
-	Enabled       bool    `json:"enabled"`
-	Description   string  `json:"description,omitempty"`
+	Name          string  `json:"name" yaml:"name"`
+	Target        string  `json:"target" yaml:"target"`
+	IntervalHours float64 `json:"interval_hours" yaml:"interval_hours"`
+	Enabled       bool    `json:"enabled" yaml:"enabled"`
+	Description   string  `json:"description,omitempty" yaml:"description"`
 }
 
 var scheduleCmd = &cobra.Command{
@@ -49,6 +49,11 @@ var scheduleListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all schedules",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cols, err := fieldColumns("schedule_list", scheduleListHeader, scheduleListKeys)
+		if err != nil {
+			return err
+		}
+
 		c := client.New()
 		var resp schedulesResp
 		if err := c.Get("/api/schedules", &resp); err != nil {
@@ -56,10 +61,9 @@ var scheduleListCmd = &cobra.Command{
 		}
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp.Schedules)
+		case output.JSON, output.YAML:
+			output.PrintStructured(selectJSONFields(resp.Schedules, scheduleListKeys, cols))
 		case output.CSV:
-			header := []string{"ID", "Name", "Target", "Interval", "Enabled", "Runs", "Next Run"}
 			rows := make([][]string, 0, len(resp.Schedules))
 			for _, s := range resp.Schedules {
 				nextRun := float64(0)
@@ -68,19 +72,15 @@ var scheduleListCmd = &cobra.Command{
 				}
 				rows = append(rows, []string{s.ID, s.Name, s.Target, fmt.Sprintf("%.1fh", s.IntervalHours), fmt.Sprintf("%v", s.Enabled), fmt.Sprintf("%d", s.RunsCompleted), formatEpoch(nextRun)})
 			}
-			output.PrintCSV(header, rows)
+			output.PrintCSV(selectColumns(scheduleListHeader, rows, cols))
 		default:
-			header := []string{"ID", "Name", "Target", "Interval", "Enabled", "Runs", "Next Run"}
 			rows := make([][]string, 0, len(resp.Schedules))
 			for _, s := range resp.Schedules {
 				enabled := "✓"
 				if !s.Enabled {
 					enabled = "✗"
 				}
-				interval := fmt.Sprintf("%.0fh", s.IntervalHours)
-				if s.IntervalHours >= 24 {
-					interval = fmt.Sprintf("%.0fd", s.IntervalHours/24)
-				}
+				interval := scheduleInterval(s.IntervalHours)
 				nextRun := float64(0)
 				if s.NextRunAt != nil {
 					nextRun = *s.NextRunAt
@@ -91,12 +91,28 @@ var scheduleListCmd = &cobra.Command{
 				}
 				rows = append(rows, []string{truncID(s.ID), s.Name, s.Target, interval, enabled, runs, formatEpoch(nextRun)})
 			}
-			output.PrintTable(header, rows)
+			output.PrintTable(selectColumns(scheduleListHeader, rows, cols))
 		}
 		return nil
 	},
 }
 
+// scheduleInterval formats a schedule interval in hours, or in days from a
+// day up.
+func scheduleInterval(hours float64) string {
+	if hours >= 24 {
+		return fmt.Sprintf("%.0fd", hours/24)
+	}
+	return fmt.Sprintf("%.0fh", hours)
+}
+
+// scheduleListHeader and scheduleListKeys are the schedule list columns and
+// their JSON field names, as accepted by --fields.
+var (
+	scheduleListHeader = []string{"ID", "Name", "Target", "Interval", "Enabled", "Runs", "Next Run"}
+	scheduleListKeys   = []string{"id", "name", "target", "interval_hours", "enabled", "runs_completed", "next_run_at"}
+)
+
 var scheduleCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new schedule",
@@ -127,10 +143,11 @@ var scheduleCreateCmd = &cobra.Command{
 		if err := c.Post("/api/schedules", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["id"]))
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Schedule created: %v", resp["id"])
 		}
@@ -181,12 +198,13 @@ var scheduleUpdateCmd = &cobra.Command{
 		c := client.New()
 		var resp map[string]interface{}
 		if err := c.Patch(fmt.Sprintf("/api/schedules/%s", args[0]), bytes.NewReader(payload), &resp); err != nil {
-			return err
+			return notFound(err, "schedule", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Schedule %s updated", args[0])
 		}
@@ -204,8 +222,9 @@ var scheduleDeleteCmd = &cobra.Command{
 		}
 		c := client.New()
 		if err := c.Delete(fmt.Sprintf("/api/schedules/%s", args[0]), nil); err != nil {
-			return err
+			return notFound(err, "schedule", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Schedule %s deleted", args[0])
 		return nil
 	},
@@ -222,8 +241,9 @@ var scheduleTriggerCmd = &cobra.Command{
 		c := client.New()
 		var resp map[string]interface{}
 		if err := c.Post(fmt.Sprintf("/api/schedules/%s/trigger", args[0]), nil, &resp); err != nil {
-			return err
+			return notFound(err, "schedule", args[0])
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Schedule triggered — %v", resp)
 		return nil
 	},
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+type scheduleApplyResult struct {
+	Name   string `json:"name"`
+	ID     string `json:"id,omitempty"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// parseScheduleFile reads schedule definitions from YAML or JSON. The file may
+// be a bare list or a mapping with a "schedules" key. Entries are enabled
+// unless they say otherwise.
+func parseScheduleFile(data []byte) ([]scheduleCreateReq, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing schedule file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("schedule file is empty")
+	}
+
+	list := doc.Content[0]
+	if list.Kind == yaml.MappingNode {
+		list = nil
+		for i := 0; i+1 < len(doc.Content[0].Content); i += 2 {
+			if doc.Content[0].Content[i].Value == "schedules" {
+				list = doc.Content[0].Content[i+1]
+			}
+		}
+	}
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("schedule file must be a list or contain a \"schedules\" list")
+	}
+
+	defs := make([]scheduleCreateReq, 0, len(list.Content))
+	seen := make(map[string]bool)
+	for i, node := range list.Content {
+		def := scheduleCreateReq{Enabled: true}
+		if err := node.Decode(&def); err != nil {
+			return nil, fmt.Errorf("schedule #%d: %w", i+1, err)
+		}
+		if def.Name == "" || def.Target == "" || def.IntervalHours <= 0 {
+			return nil, fmt.Errorf("schedule #%d: name, target, and interval_hours (>0) are required", i+1)
+		}
+		if seen[def.Name] {
+			return nil, fmt.Errorf("schedule #%d: duplicate name %q", i+1, def.Name)
+		}
+		seen[def.Name] = true
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// scheduleChanges returns the fields of def that differ from the existing schedule.
+func scheduleChanges(existing schedule, def scheduleCreateReq) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if existing.Target != def.Target {
+		updates["target"] = def.Target
+	}
+	if existing.IntervalHours != def.IntervalHours {
+		updates["interval_hours"] = def.IntervalHours
+	}
+	if existing.Enabled != def.Enabled {
+		updates["enabled"] = def.Enabled
+	}
+	if existing.Description != def.Description {
+		updates["description"] = def.Description
+	}
+	return updates
+}
+
+var scheduleApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update schedules from a YAML or JSON file",
+	Long: `Create or update schedules from a YAML or JSON file.
+
+Schedules are matched to existing ones by name: missing schedules are created,
+changed ones are updated, and the rest are left unchanged. Schedules that exist
+on the server but not in the file are not touched.
+
+Example file:
+
+  schedules:
+    - name: daily-example
+      target: example.com
+      interval_hours: 24
+      description: Daily recon
+    - name: weekly-org
+      target: example.org
+      interval_hours: 168
+      enabled: false`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		if file == "" {
+			return fmt.Errorf("--file is required")
+		}
+
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return fmt.Errorf("reading schedule file: %w", err)
+		}
+		defs, err := parseScheduleFile(data)
+		if err != nil {
+			return err
+		}
+
+		c := client.New()
+		var resp schedulesResp
+		if err := c.Get("/api/schedules", &resp); err != nil {
+			return err
+		}
+		byName := make(map[string]schedule, len(resp.Schedules))
+		for _, s := range resp.Schedules {
+			byName[s.Name] = s
+		}
+
+		results := make([]scheduleApplyResult, 0, len(defs))
+		failed := 0
+		for _, def := range defs {
+			res := scheduleApplyResult{Name: def.Name}
+			existing, ok := byName[def.Name]
+			switch {
+			case !ok:
+				payload, _ := json.Marshal(def)
+				var created map[string]interface{}
+				if err := c.Post("/api/schedules", bytes.NewReader(payload), &created); err != nil {
+					res.Result, res.Error = "failed", err.Error()
+					break
+				}
+				res.ID, res.Result = fmt.Sprintf("%v", created["id"]), "created"
+			default:
+				res.ID = existing.ID
+				updates := scheduleChanges(existing, def)
+				if len(updates) == 0 {
+					res.Result = "unchanged"
+					break
+				}
+				payload, _ := json.Marshal(updates)
+				if err := c.Patch(fmt.Sprintf("/api/schedules/%s", existing.ID), bytes.NewReader(payload), nil); err != nil {
+					res.Result, res.Error = "failed", err.Error()
+					break
+				}
+				res.Result = "updated"
+			}
+			if res.Error != "" {
+				failed++
+			}
+			results = append(results, res)
+		}
+		recordHistory(cmd, args, "")
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(results)
+		case output.CSV:
+			header := []string{"Name", "ID", "Result", "Error"}
+			rows := make([][]string, 0, len(results))
+			for _, r := range results {
+				rows = append(rows, []string{r.Name, r.ID, r.Result, r.Error})
+			}
+			output.PrintCSV(header, rows)
+		default:
+			header := []string{"Name", "ID", "Result"}
+			rows := make([][]string, 0, len(results))
+			counts := make(map[string]int)
+			for _, r := range results {
+				counts[r.Result]++
+				result := r.Result
+				switch r.Result {
+				case "created":
+					result = color.GreenString(r.Result)
+				case "updated":
+					result = color.YellowString(r.Result)
+				case "failed":
+					result = color.RedString("failed: " + r.Error)
+				}
+				rows = append(rows, []string{r.Name, truncID(r.ID), result})
+			}
+			output.PrintTable(header, rows)
+			fmt.Printf("\n%d created, %d updated, %d unchanged, %d failed\n",
+				counts["created"], counts["updated"], counts["unchanged"], counts["failed"])
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d schedules failed to apply", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	scheduleApplyCmd.Flags().StringP("file", "f", "", "YAML or JSON file of schedule definitions ('-' for stdin)")
+
+	scheduleCmd.AddCommand(scheduleApplyCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// scheduleNextRun returns when a schedule runs next: the server's next_run_at
+// if it reported one, else an interval after its last run or, if it never
+// ran, after its creation. ok is false for disabled schedules, schedules that
+// used up max_runs, and schedules without an interval to go by.
+func scheduleNextRun(s schedule) (at float64, ok bool) {
+	switch {
+	case !s.Enabled, s.MaxRuns > 0 && s.RunsCompleted >= s.MaxRuns:
+		return 0, false
+	case s.NextRunAt != nil && *s.NextRunAt > 0:
+		return *s.NextRunAt, true
+	case s.IntervalHours <= 0:
+		return 0, false
+	case s.LastRunAt != nil && *s.LastRunAt > 0:
+		return *s.LastRunAt + s.IntervalHours*3600, true
+	case s.CreatedAt > 0:
+		return s.CreatedAt + s.IntervalHours*3600, true
+	}
+	return 0, false
+}
+
+// upcomingSchedules returns the schedules due to run before now+within,
+// soonest first, with NextRunAt filled in. Overdue schedules are included.
+func upcomingSchedules(schedules []schedule, now time.Time, within time.Duration) []schedule {
+	end := float64(now.Add(within).UnixNano()) / float64(time.Second)
+	upcoming := []schedule{}
+	for _, s := range schedules {
+		at, ok := scheduleNextRun(s)
+		if !ok || at > end {
+			continue
+		}
+		s.NextRunAt = &at
+		upcoming = append(upcoming, s)
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return *upcoming[i].NextRunAt < *upcoming[j].NextRunAt
+	})
+	return upcoming
+}
+
+// scheduleDueIn describes how long until a run at epoch at, e.g. "in 3h05m".
+func scheduleDueIn(at float64, now time.Time) string {
+	d := time.Unix(0, int64(at*float64(time.Second))).Sub(now)
+	if d <= 0 {
+		return "overdue"
+	}
+	return "in " + humanDuration(d)
+}
+
+var scheduleNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Preview the scheduled runs coming up",
+	Long: `Preview the scheduled runs coming up.
+
+Lists the enabled schedules due to run within --within, soonest first. When
+the server does not report a schedule's next run, it is worked out from its
+last run (or creation) and interval. Schedules past due are shown as overdue.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		withinStr, _ := cmd.Flags().GetString("within")
+		within, err := parseWindow(withinStr)
+		if err != nil {
+			return fmt.Errorf("invalid --within: %w", err)
+		}
+
+		c := client.New()
+		var resp schedulesResp
+		if err := c.Get("/api/schedules", &resp); err != nil {
+			return err
+		}
+		now := time.Now()
+		upcoming := upcomingSchedules(resp.Schedules, now, within)
+
+		switch output.Current() {
+		case output.JSON, output.YAML:
+			output.PrintStructured(upcoming)
+		case output.CSV:
+			rows := make([][]string, 0, len(upcoming))
+			for _, s := range upcoming {
+				rows = append(rows, []string{s.ID, s.Name, s.Target, strconv.FormatFloat(s.IntervalHours, 'f', -1, 64), formatEpoch(*s.NextRunAt)})
+			}
+			output.PrintCSV([]string{"id", "name", "target", "interval_hours", "next_run_at"}, rows)
+		default:
+			if len(upcoming) == 0 {
+				fmt.Printf("No scheduled runs within %s.\n", withinStr)
+				return nil
+			}
+			rows := make([][]string, 0, len(upcoming))
+			for _, s := range upcoming {
+				rows = append(rows, []string{truncID(s.ID), s.Name, s.Target, scheduleInterval(s.IntervalHours),
+					formatEpoch(*s.NextRunAt), scheduleDueIn(*s.NextRunAt, now)})
+			}
+			output.PrintTable([]string{"ID", "Name", "Target", "Interval", "Next Run", "Due"}, rows)
+		}
+		return nil
+	},
+}
+
+func init() {
+	scheduleNextCmd.Flags().String("within", "24h", "Only runs due within this window (e.g. 90m, 24h, 7d)")
+
+	scheduleCmd.AddCommand(scheduleNextCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// schemaTypes maps the names accepted by 'sf schema' to the structs the CLI
+// decodes responses into (or, for requests, encodes request bodies from).
+var schemaTypes = map[string]interface{}{
+	"health":            healthResp{},
+	"scan":              scanDetail{},
+	"scans":             scansResp{},
+	"scan-start":        scanStartReq{},
+	"module":            moduleInfo{},
+	"schedule":          schedule{},
+	"schedules":         schedulesResp{},
+	"schedule-create":   scheduleCreateReq{},
+	"correlation-rule":  correlationRule{},
+	"correlation-rules": correlationRulesResp{},
+}
+
+// jsonSchema derives a JSON Schema for t from its Go type and json tags.
+// Fields without omitempty are listed as required; pointers are nullable.
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := jsonSchema(t.Elem())
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+		}
+		return s
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		var required []string
+		addStructFields(t, props, &required)
+		s := map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+	// interface{} and anything else accepts any JSON value.
+	return map[string]interface{}{}
+}
+
+// addStructFields adds the JSON-visible fields of struct type t to props,
+// flattening embedded structs the way encoding/json does.
+func addStructFields(t reflect.Type, props map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addStructFields(f.Type, props, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = jsonSchema(f.Type)
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaFor returns the JSON Schema document for a named schema type.
+func schemaFor(name string) (map[string]interface{}, error) {
+	v, ok := schemaTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema type %q (run 'sf schema' to list types)", name)
+	}
+	s := jsonSchema(reflect.TypeOf(v))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = name
+	return s, nil
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [type]",
+	Short: "Print the JSON schema the CLI expects for an API payload",
+	Long: `Print the JSON schema the CLI expects for an API payload.
+
+The schema is derived from the structs the CLI decodes responses into, so it
+documents exactly which fields each command reads. Run without arguments to
+list the available types.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			names := make([]string, 0, len(schemaTypes))
+			for name := range schemaTypes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			switch output.Current() {
+			case output.JSON, output.YAML:
+				output.PrintStructured(names)
+			default:
+				for _, name := range names {
+					fmt.Println(name)
+				}
+			}
+			return nil
+		}
+		s, err := schemaFor(args[0])
+		if err != nil {
+			return err
+		}
+		output.PrintJSON(s)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
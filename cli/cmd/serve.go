@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiderfoot/spiderfoot-cli/internal/client"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// isLoopback reports whether a listen address only accepts local connections.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newAuthProxy returns a reverse proxy to the client's server that replaces
+// any incoming credentials with the client's API key or token.
+func newAuthProxy(c *client.Client) (*httputil.ReverseProxy, error) {
+	target, err := url.Parse(c.BaseURL)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", c.BaseURL)
+	}
+	if err := c.CheckAuthTransport(); err != nil {
+		return nil, err
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+		req.Header.Del("Authorization")
+		req.Header.Del("X-API-Key")
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		} else if c.APIKey != "" {
+			req.Header.Set("X-API-Key", c.APIKey)
+		}
+	}
+	proxy.Transport = c.HTTPClient.Transport
+	return proxy, nil
+}
+
+// allowedHosts returns the Host headers the proxy answers to when listening
+// on addr: the address itself, localhost or 127.0.0.1 with its port, and the
+// extra hosts given with --allow-host, which take addr's port unless they
+// name one. Anything else may be a DNS rebinding attack from a web page.
+func allowedHosts(addr string, extra []string) map[string]bool {
+	hosts := map[string]bool{addr: true}
+	_, port, err := net.SplitHostPort(addr)
+	if err == nil {
+		hosts[net.JoinHostPort("localhost", port)] = true
+		hosts[net.JoinHostPort("127.0.0.1", port)] = true
+	}
+	for _, h := range extra {
+		if _, _, err := net.SplitHostPort(h); err != nil && port != "" {
+			h = net.JoinHostPort(h, port)
+		}
+		hosts[h] = true
+	}
+	return hosts
+}
+
+// guardProxy wraps next so that it rejects requests addressed to a host other
+// than those in hosts, or sent from a page of a different origin, before any
+// credentials are added.
+func guardProxy(next http.Handler, hosts map[string]bool, quiet bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, "80")
+		}
+		if !hosts[host] {
+			http.Error(w, "forbidden host "+r.Host, http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Scheme != "http" || u.Host != r.Host {
+				http.Error(w, "forbidden origin "+origin, http.StatusForbidden)
+				return
+			}
+		}
+		if !quiet {
+			log.Printf("%s %s", r.Method, r.URL.RequestURI())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local proxy to the server that injects credentials",
+	Long: `Run a local reverse proxy to the configured server that adds the API key or
+token to every request.
+
+Tools that cannot set custom headers (browsers, notebooks) can then call
+http://127.0.0.1:9000/api/... without credentials. Anyone who can reach the
+listen address gets your access, so it binds to localhost by default.
+Requests must name the listen address, localhost, 127.0.0.1 or a host given
+with --allow-host in their Host header, and requests from web pages of
+another origin are refused, so a site you visit cannot reach the proxy
+through DNS rebinding. Listening on every interface (0.0.0.0 or ::) needs
+--allow-host with the names or addresses remote clients connect to.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, _ := cmd.Flags().GetString("listen")
+		allow, _ := cmd.Flags().GetStringSlice("allow-host")
+		if host, _, err := net.SplitHostPort(listen); err == nil && len(allow) == 0 {
+			if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+				return fmt.Errorf("--listen %s accepts connections on every interface; name the hosts clients connect to with --allow-host", listen)
+			}
+		}
+
+		c := client.New()
+		proxy, err := newAuthProxy(c)
+		if err != nil {
+			return err
+		}
+		ln, err := net.Listen("tcp", listen)
+		if err != nil {
+			return fmt.Errorf("listening on %s: %w", listen, err)
+		}
+		hosts := allowedHosts(ln.Addr().String(), allow)
+		hosts[listen] = true
+		handler := guardProxy(proxy, hosts, viper.GetBool("quiet"))
+		if !isLoopback(listen) {
+			output.Warn("WARNING: %s is not a loopback address. Anyone who can reach it can use the API with your credentials.", listen)
+		}
+		output.Success("Proxying http://%s -> %s (Ctrl-C to stop)", ln.Addr(), c.BaseURL)
+
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		ctx := commandContext()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("listen", "127.0.0.1:9000", "Address to listen on")
+	serveCmd.Flags().StringSlice("allow-host", nil, "Also accept requests for this host or host:port in the Host header (repeatable)")
+
+	rootCmd.AddCommand(serveCmd)
+}
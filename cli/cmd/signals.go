@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
+)
+
+// exitInterrupted is the conventional exit code of a command ended by a
+// signal (128 + SIGINT).
+const exitInterrupted = 130
+
+// shutdownGrace is how long a command has to wind down after SIGINT or
+// SIGTERM before the process exits anyway.
+const shutdownGrace = 3 * time.Second
+
+// signalContext returns a context that is cancelled on the first SIGINT or
+// SIGTERM. Watch loops, streams and downloads stop on it and return normally,
+// so deferred cleanup and output flushing still run. A command that does not
+// watch the context is ended after shutdownGrace, or at once on a second
+// signal, once the terminal has been restored. stop releases the handler.
+func signalContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigs:
+		case <-ctx.Done():
+			return
+		}
+		cancel()
+		select {
+		case <-sigs:
+		case <-time.After(shutdownGrace):
+		}
+		output.FlushPending()
+		runInterruptCleanups()
+		output.RestoreTerminal()
+		os.Exit(exitInterrupted)
+	}()
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
+}
+
+// silenceUsageOnInterrupt wraps the RunE of c and its subcommands so that an
+// error returned after the command's context was cancelled, which is not a
+// usage error, is reported without the usage text. It runs on the command's
+// own goroutine, unlike the signal handler.
+func silenceUsageOnInterrupt(c *cobra.Command) {
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			err := run(cmd, args)
+			if ctx := cmd.Context(); err != nil && ctx != nil && ctx.Err() != nil {
+				cmd.SilenceUsage = true
+			}
+			return err
+		}
+	}
+	for _, sub := range c.Commands() {
+		silenceUsageOnInterrupt(sub)
+	}
+}
+
+// interruptCleanups run before the process exits on a signal, to undo work a
+// command could not wind down in time, such as removing temporary files.
+var (
+	cleanupMu         sync.Mutex
+	interruptCleanups = make(map[int]func())
+	nextCleanup       int
+)
+
+// onInterrupt registers fn to run if the process exits on a signal. remove
+// unregisters it once the work it cleans up after is done.
+func onInterrupt(fn func()) (remove func()) {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+	id := nextCleanup
+	nextCleanup++
+	interruptCleanups[id] = fn
+	return func() {
+		cleanupMu.Lock()
+		defer cleanupMu.Unlock()
+		delete(interruptCleanups, id)
+	}
+}
+
+func runInterruptCleanups() {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+	for _, fn := range interruptCleanups {
+		fn()
+	}
+}
+
+// commandContext returns the context of the running command, which is
+// cancelled on SIGINT or SIGTERM.
+func commandContext() context.Context {
+	if ctx := rootCmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
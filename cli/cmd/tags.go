@@ -37,8 +37,8 @@ var tagsListCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Name", "Color", "Parent"}
@@ -88,9 +88,10 @@ var tagsCreateCmd = &cobra.Command{
 		if err := c.Post("/api/tags", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["tag_id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Tag created: %v", resp["tag_id"])
 		}
@@ -110,6 +111,7 @@ var tagsDeleteCmd = &cobra.Command{
 		if err := c.Delete(fmt.Sprintf("/api/tags/%s", args[0]), nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Tag %s deleted", args[0])
 		return nil
 	},
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// targetTypes lists the target kinds accepted by --target-type.
+var targetTypes = []string{"domain", "ip", "netblock", "email", "url", "phone", "name", "asn"}
+
+var (
+	domainRe = regexp.MustCompile(`^(?i)([a-z0-9]([a-z0-9\-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}\.?$`)
+	emailRe  = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+	phoneRe  = regexp.MustCompile(`^\+[0-9 ]{6,20}$`)
+	asnRe    = regexp.MustCompile(`^(?i)(AS)?[0-9]{1,10}$`)
+	nameRe   = regexp.MustCompile(`^"[^"]+"$`)
+)
+
+// detectTargetType guesses what kind of target a string is, returning "" if
+// it matches none of the known kinds.
+func detectTargetType(target string) string {
+	switch {
+	case net.ParseIP(target) != nil:
+		return "ip"
+	case strings.Contains(target, "/") && !strings.Contains(target, "://"):
+		if _, _, err := net.ParseCIDR(target); err == nil {
+			return "netblock"
+		}
+		return ""
+	case strings.Contains(target, "://"):
+		return "url"
+	case emailRe.MatchString(target):
+		return "email"
+	case phoneRe.MatchString(target):
+		return "phone"
+	case nameRe.MatchString(target):
+		return "name"
+	case asnRe.MatchString(target):
+		return "asn"
+	case domainRe.MatchString(target):
+		return "domain"
+	}
+	return ""
+}
+
+// validateTarget checks that target looks like a plausible scan target and
+// returns human-readable warnings for anything suspicious. hint, if set, is
+// the target type the user expects.
+func validateTarget(target, hint string) []string {
+	var warnings []string
+
+	if trimmed := strings.TrimSpace(target); trimmed != target {
+		warnings = append(warnings, "target has leading or trailing whitespace")
+		target = trimmed
+	}
+
+	detected := detectTargetType(target)
+	switch detected {
+	case "":
+		warnings = append(warnings, fmt.Sprintf("%q does not look like a domain, IP, netblock, email, URL, phone number, name, or ASN", target))
+	case "url":
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			warnings = append(warnings, fmt.Sprintf("%q is not a valid URL", target))
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			warnings = append(warnings, fmt.Sprintf("unrecognized URL scheme %q (expected http or https)", u.Scheme))
+		}
+	case "email":
+		domain := target[strings.LastIndex(target, "@")+1:]
+		if !domainRe.MatchString(domain) {
+			warnings = append(warnings, fmt.Sprintf("email domain %q does not look valid", domain))
+		}
+	}
+
+	if hint != "" && detected != "" && !strings.EqualFold(hint, detected) {
+		warnings = append(warnings, fmt.Sprintf("target looks like a %s, not a %s", detected, strings.ToLower(hint)))
+	}
+	return warnings
+}
+
+// validTargetType reports whether t is an accepted --target-type value.
+func validTargetType(t string) bool {
+	for _, v := range targetTypes {
+		if strings.EqualFold(v, t) {
+			return true
+		}
+	}
+	return false
+}
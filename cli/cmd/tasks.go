@@ -45,8 +45,8 @@ var tasksListCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Type", "State", "Created"}
@@ -89,6 +89,9 @@ var tasksGetCmd = &cobra.Command{
 		if err := c.Get(fmt.Sprintf("/api/tasks/%s", args[0]), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -106,6 +109,7 @@ var tasksCancelCmd = &cobra.Command{
 		if err := c.Delete(fmt.Sprintf("/api/tasks/%s", args[0]), nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Task %s cancelled", args[0])
 		return nil
 	},
@@ -119,12 +123,14 @@ var tasksCleanCmd = &cobra.Command{
 		if err := c.Delete("/api/tasks/completed", nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, "")
 		output.Success("Completed tasks removed")
 		return nil
 	},
 }
 
 func init() {
+	addJSONPathFlag(tasksGetCmd)
 	tasksListCmd.Flags().String("state", "", "Filter by state")
 	tasksListCmd.Flags().String("type", "", "Filter by task type")
 	tasksListCmd.Flags().Int("limit", 50, "Maximum tasks to return")
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateEscapes expands the escapes most often typed in a shell-quoted
+// --template, where the shell passes them through literally.
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// templateFuncs are the helpers available to --template, in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"epoch": formatEpoch,
+	"short": truncID,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// renderRowTemplate executes text once per row and concatenates the results,
+// ending each row with a newline unless the template already does.
+func renderRowTemplate[T any](text string, rows []T) (string, error) {
+	text = templateEscapes.Replace(text)
+	tmpl, err := template.New("row").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid --template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, row := range rows {
+		if err := tmpl.Execute(&buf, row); err != nil {
+			return "", fmt.Errorf("executing --template: %w", err)
+		}
+		if !strings.HasSuffix(text, "\n") {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String(), nil
+}
@@ -5,15 +5,39 @@ import (
 	"runtime"
 
 	"github.com/spf13/cobra"
+	"github.com/spiderfoot/spiderfoot-cli/internal/output"
 )
 
+type versionInfo struct {
+	Version   string `json:"version"`
+	Go        string `json:"go"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("SpiderFoot CLI v%s\n", version)
-		fmt.Printf("  Go:       %s\n", runtime.Version())
-		fmt.Printf("  OS/Arch:  %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		info := versionInfo{
+			Version:   version,
+			Go:        runtime.Version(),
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			Commit:    commit,
+			BuildDate: date,
+		}
+		if output.Structured() {
+			output.PrintStructured(info)
+			return
+		}
+		fmt.Printf("SpiderFoot CLI v%s\n", info.Version)
+		fmt.Printf("  Go:       %s\n", info.Go)
+		fmt.Printf("  OS/Arch:  %s/%s\n", info.OS, info.Arch)
+		fmt.Printf("  Commit:   %s\n", info.Commit)
+		fmt.Printf("  Built:    %s\n", info.BuildDate)
 	},
 }
 
@@ -26,8 +26,8 @@ var webhooksListCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "URL", "Events", "Status"}
@@ -67,9 +67,10 @@ var webhooksCreateCmd = &cobra.Command{
 		if err := c.Post("/api/webhooks", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["webhook_id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Webhook created: %v", resp["webhook_id"])
 		}
@@ -90,6 +91,9 @@ var webhooksGetCmd = &cobra.Command{
 		if err := c.Get(fmt.Sprintf("/api/webhooks/%s", args[0]), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -107,6 +111,7 @@ var webhooksDeleteCmd = &cobra.Command{
 		if err := c.Delete(fmt.Sprintf("/api/webhooks/%s", args[0]), nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Webhook %s deleted", args[0])
 		return nil
 	},
@@ -125,9 +130,10 @@ var webhooksTestCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/webhooks/%s/test", args[0]), nil, &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Test event sent")
 		}
@@ -148,6 +154,7 @@ var webhooksEventTypesCmd = &cobra.Command{
 }
 
 func init() {
+	addJSONPathFlag(webhooksGetCmd)
 	webhooksCreateCmd.Flags().String("url", "", "Webhook URL (required)")
 
 	webhooksCmd.AddCommand(webhooksListCmd)
@@ -27,8 +27,8 @@ var workspaceListCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			if items, ok := resp.([]interface{}); ok {
 				header := []string{"ID", "Name", "Targets", "Scans", "Created"}
@@ -66,6 +66,9 @@ var workspaceGetCmd = &cobra.Command{
 		if err := c.Get(fmt.Sprintf("/api/workspaces/%s", args[0]), &resp); err != nil {
 			return err
 		}
+		if path, _ := cmd.Flags().GetString("json-path"); path != "" {
+			return printJSONPath(resp, path)
+		}
 		printGenericResponse(resp)
 		return nil
 	},
@@ -90,9 +93,10 @@ var workspaceCreateCmd = &cobra.Command{
 		if err := c.Post("/api/workspaces", bytes.NewReader(payload), &resp); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, fmt.Sprintf("%v", resp["id"]))
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			output.Success("Workspace created: %v", resp["id"])
 		}
@@ -112,6 +116,7 @@ var workspaceDeleteCmd = &cobra.Command{
 		if err := c.Delete(fmt.Sprintf("/api/workspaces/%s", args[0]), nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Workspace %s deleted", args[0])
 		return nil
 	},
@@ -129,6 +134,7 @@ var workspaceSetActiveCmd = &cobra.Command{
 		if err := c.Post(fmt.Sprintf("/api/workspaces/%s/set-active", args[0]), nil, nil); err != nil {
 			return err
 		}
+		recordHistory(cmd, args, args[0])
 		output.Success("Active workspace set to %s", args[0])
 		return nil
 	},
@@ -148,8 +154,8 @@ var workspaceTargetsCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -171,8 +177,8 @@ var workspaceScansCmd = &cobra.Command{
 			return err
 		}
 		switch output.Current() {
-		case output.JSON:
-			output.PrintJSON(resp)
+		case output.JSON, output.YAML:
+			output.PrintStructured(resp)
 		default:
 			printGenericResponse(resp)
 		}
@@ -181,6 +187,7 @@ var workspaceScansCmd = &cobra.Command{
 }
 
 func init() {
+	addJSONPathFlag(workspaceGetCmd)
 	workspaceCreateCmd.Flags().StringP("name", "n", "", "Workspace name (required)")
 	workspaceCreateCmd.Flags().StringP("description", "d", "", "Workspace description")
 
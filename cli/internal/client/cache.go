@@ -0,0 +1,73 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNotCached is returned in offline mode when a response is not cached.
+var ErrNotCached = errors.New("no cached response")
+
+// Cache stores successful GET responses on disk, keyed by server URL and
+// request path, so they can be reused while fresh or read back offline.
+type Cache struct {
+	Dir string
+	// TTL is how long a cached response is served without asking the
+	// server. Zero disables reading from the cache, except in Offline mode.
+	TTL time.Duration
+	// Offline serves every GET from the cache regardless of age and never
+	// contacts the server.
+	Offline bool
+	// Refresh skips reading the cache but still stores fresh responses.
+	Refresh bool
+}
+
+// file returns the cache file for a request path on baseURL.
+func (c *Cache) file(baseURL, path string) string {
+	sum := sha256.Sum256([]byte(baseURL + "\n" + path))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the cached response for path, if it may be served.
+func (c *Cache) load(baseURL, path string) ([]byte, error) {
+	if c.Refresh && !c.Offline {
+		return nil, ErrNotCached
+	}
+	name := c.file(baseURL, path)
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, ErrNotCached
+	}
+	if !c.Offline && time.Since(info.ModTime()) >= c.TTL {
+		return nil, ErrNotCached
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+	return data, nil
+}
+
+// store saves a response body. Failures are ignored: the cache is an
+// optimization and must never fail a request that succeeded.
+func (c *Cache) store(baseURL, path string, data []byte) {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || os.Rename(tmp.Name(), c.file(baseURL, path)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
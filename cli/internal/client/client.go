@@ -2,13 +2,24 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/spf13/viper"
@@ -17,140 +28,557 @@ import (
 // Version is set at build time via -ldflags and used in User-Agent headers.
 var Version = "dev"
 
+// BaseContext is the parent of every request not given its own context, so
+// cancelling it (on Ctrl-C) aborts requests in flight.
+var BaseContext = context.Background()
+
+// APIVersion is the server API version this client was built against and the
+// default it requests.
+const APIVersion = "v1"
+
+// VersionMismatch, if set, is called the first time a server reports serving
+// a different API version than the one requested.
+var VersionMismatch func(requested, served string)
+
+var versionWarned sync.Once
+
+// maxRetryWait caps how long a Retry-After header or the backoff can make a
+// retry wait.
+const maxRetryWait = time.Minute
+
+// DefaultRetryWait is the wait before the first retry when the server does
+// not send Retry-After; it doubles with each further retry.
+const DefaultRetryWait = time.Second
+
+// RetryNotify, if set, is called before each retry with the attempt that
+// failed, the retry budget, the status that caused it (0 for a connection
+// error) and the wait.
+var RetryNotify func(attempt, max, status int, wait time.Duration)
+
+// retries counts the retries made by all clients in this process.
+var retries atomic.Int64
+
+// RetryCount returns the number of retries made so far.
+func RetryCount() int64 {
+	return retries.Load()
+}
+
+// retryableMethod reports whether requests with method are idempotent, and
+// so safe to send again.
+func retryableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodDelete
+}
+
+// retryableStatus reports whether a response status is worth retrying: the
+// server failed in a way that may pass, as a load balancer answering 502 or
+// 503 does. 501 Not Implemented will not, and no 4xx is retried, 429
+// included.
+func retryableStatus(code int) bool {
+	return code >= 500 && code != http.StatusNotImplemented
+}
+
+// retryableError reports whether a failed request is worth retrying: the
+// connection could not be made, timed out or broke off. Anything else, such
+// as an unsupported scheme, a bad proxy URL or a server certificate that
+// failed verification, would fail again.
+func retryableError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return false
+	}
+	// Every error from http.Client.Do is a *url.Error, itself a net.Error,
+	// so look at what it wraps.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var opErr *net.OpError
+	var netErr net.Error
+	return errors.As(err, &opErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// retryWait returns how long to wait before retry number attempt. A
+// Retry-After header, given in seconds or as an HTTP date, is honoured;
+// otherwise the wait starts at base and doubles with each attempt, with
+// jitter so that many clients do not retry in step. Either way it is capped
+// at maxRetryWait.
+func retryWait(attempt int, base time.Duration, retryAfter string) time.Duration {
+	if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, maxRetryWait)
+	} else if t, err := http.ParseTime(retryAfter); err == nil {
+		return min(max(time.Until(t), 0), maxRetryWait)
+	}
+	wait := base
+	for i := 1; i < attempt && wait < maxRetryWait; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxRetryWait)
+	if wait <= 0 {
+		return 0
+	}
+	// Wait between half and all of the backoff.
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// Sentinel errors for common HTTP failure classes. Errors returned by the
+// client wrap these where applicable, so callers can test with errors.Is.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+	// ErrInsecureAuth is returned instead of sending credentials over plain
+	// HTTP to a host other than localhost.
+	ErrInsecureAuth = errors.New("refusing to send credentials over plain HTTP")
+)
+
+// HTTPError is returned when the server responds with a status >= 400.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	// Header holds the response headers, if known.
+	Header http.Header
+	// RequestID is the ID the server assigned the request, if it sent one;
+	// quoting it lets server operators find the request in their logs.
+	RequestID string
+}
+
+// newHTTPError builds the HTTPError for a failed response.
+func newHTTPError(resp *http.Response, body string) *HTTPError {
+	return &HTTPError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header, RequestID: RequestID(resp.Header)}
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("HTTP %d", e.StatusCode)
+	if e.Body != "" {
+		msg += ": " + truncate(e.Body, 200)
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// requestIDHeaders are the response headers a request ID is read from, in
+// order of preference. SpiderFoot sends X-Request-ID; proxies in front of it
+// may use the others.
+var requestIDHeaders = []string{"X-Request-ID", "X-Correlation-ID", "Request-Id"}
+
+// RequestID returns the request ID in response headers h, or "" if there is
+// none.
+func RequestID(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := strings.TrimSpace(h.Get(name)); id != "" {
+			return truncate(id, 128)
+		}
+	}
+	return ""
+}
+
+// RequestIDOf returns the request ID of the failed request behind err, or ""
+// if err is not an HTTP error or the server sent no ID.
+func RequestIDOf(err error) string {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.RequestID
+	}
+	return ""
+}
+
+// Unwrap maps the status code to one of the sentinel errors.
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
 // Client talks to the SpiderFoot API.
 type Client struct {
-	BaseURL    string
-	APIKey     string
-	Token      string
-	HTTPClient *http.Client
+	BaseURL string
+	APIKey  string
+	Token   string
+	// APIVersion is sent in the Accept-Version header when non-empty.
+	APIVersion string
+	// Retries is how many times a GET or DELETE that failed to connect or was
+	// answered with a 5xx is retried.
+	Retries int
+	// RetryWait is the backoff before the first retry; see retryWait.
+	RetryWait time.Duration
+	// MaxResponseSize caps the bytes read from a buffered response; 0 means
+	// no limit. Streamed responses (Stream, Download) are not limited.
+	MaxResponseSize int64
+	HTTPClient      *http.Client
+	// Cache, if set, is consulted and updated by GET requests made with Get.
+	Cache *Cache
+	// AllowInsecureAuth permits sending credentials over plain HTTP to hosts
+	// other than localhost.
+	AllowInsecureAuth bool
 }
 
 // New creates a Client from the current viper config.
 func New() *Client {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: viper.GetBool("insecure"),
+	}
+	// The file is validated when flags are parsed; see cmd/root.go.
+	if file := viper.GetString("ca_cert"); file != "" {
+		if pool, err := LoadCACerts(file, viper.GetBool("ca_cert_only")); err == nil {
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !tlsConfig.InsecureSkipVerify && viper.GetBool("skip_hostname_verification") {
+		// Disable the built-in verification and replace it with a chain-only check.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = verifyChainOnly(tlsConfig.RootCAs)
+	}
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: viper.GetBool("insecure"),
-		},
+		TLSClientConfig: tlsConfig,
+	}
+	// Entries are validated when flags are parsed; see cmd/root.go.
+	if overrides, err := ParseResolve(viper.GetStringSlice("resolve")); err == nil && len(overrides) > 0 {
+		transport.DialContext = resolveDialer(overrides)
 	}
+	// The size is validated when flags are parsed; see cmd/root.go.
+	maxResponseSize, _ := ParseSize(viper.GetString("max_response_size"))
 	return &Client{
-		BaseURL: strings.TrimRight(viper.GetString("server"), "/"),
-		APIKey:  viper.GetString("api_key"),
-		Token:   viper.GetString("token"),
+		BaseURL:         strings.TrimRight(viper.GetString("server"), "/"),
+		APIKey:          viper.GetString("api_key"),
+		Token:           viper.GetString("token"),
+		APIVersion:      viper.GetString("api_version"),
+		Retries:         viper.GetInt("retries"),
+		RetryWait:       viper.GetDuration("retry_wait"),
+		MaxResponseSize: maxResponseSize,
 		HTTPClient: &http.Client{
-			Timeout:   30 * time.Second,
+			Timeout:   viper.GetDuration("timeout"),
 			Transport: transport,
 		},
+		AllowInsecureAuth: viper.GetBool("allow_insecure_auth"),
+	}
+}
+
+// CheckAuthTransport returns an error wrapping ErrInsecureAuth when the client
+// has credentials and BaseURL is plain HTTP to a host other than localhost,
+// unless AllowInsecureAuth is set.
+func (c *Client) CheckAuthTransport() error {
+	if (c.Token == "" && c.APIKey == "") || c.AllowInsecureAuth {
+		return nil
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || !strings.EqualFold(u.Scheme, "http") {
+		return nil
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%w to %s: the API key or token would be readable on the network; use https, or pass --allow-insecure-auth if this is intended", ErrInsecureAuth, host)
+}
+
+// LoadCACerts returns a pool trusting the PEM certificates in file. They are
+// added to a copy of the system roots, or with only set make up the whole
+// pool. Every PEM block must be a parseable certificate.
+func LoadCACerts(file string, only bool) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificates: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !only {
+		if system, err := x509.SystemCertPool(); err == nil {
+			pool = system
+		}
+	}
+	n := 0
+	for rest := data; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("%s: PEM block %d is a %s, not a CERTIFICATE", file, n+1, block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: parsing certificate %d: %w", file, n+1, err)
+		}
+		pool.AddCert(cert)
+		n++
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%s: no PEM certificates found", file)
 	}
+	return pool, nil
 }
 
-// request builds and executes an HTTP request, returning the decoded JSON body.
-func (c *Client) request(method, path string, body io.Reader, result interface{}) error {
-	u, err := url.JoinPath(c.BaseURL, path)
+// verifyChainOnly returns a check that validates the server's certificate
+// chain against roots (the system roots when nil) without checking that the
+// certificate matches the server hostname.
+func verifyChainOnly(roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("tls: server presented no certificates")
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+			return fmt.Errorf("tls: verifying certificate chain: %w", err)
+		}
+		return nil
+	}
+}
+
+// newRequest builds an authenticated request for path, which may include a
+// query string.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	p, query, _ := strings.Cut(path, "?")
+	u, err := url.JoinPath(c.BaseURL, p)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if query != "" {
+		u += "?" + query
 	}
 
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	// Auth
+	if err := c.CheckAuthTransport(); err != nil {
+		return nil, err
+	}
 	if c.Token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.Token)
 	} else if c.APIKey != "" {
 		req.Header.Set("X-API-Key", c.APIKey)
 	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "SpiderFoot-CLI/"+Version)
+	if c.APIVersion != "" {
+		req.Header.Set("Accept-Version", c.APIVersion)
+	}
+	return req, nil
+}
 
+// do executes req and checks the API version the server reports serving.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	served := resp.Header.Get("X-API-Version")
+	if c.APIVersion != "" && served != "" && served != c.APIVersion && VersionMismatch != nil {
+		versionWarned.Do(func() { VersionMismatch(c.APIVersion, served) })
 	}
-	defer resp.Body.Close()
+	return resp, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
+// request builds and executes an HTTP request under ctx, returning the decoded
+// JSON body.
+func (c *Client) request(ctx context.Context, method, path string, body io.Reader, result interface{}) error {
+	cached := method == http.MethodGet && c.Cache != nil
+	if cached {
+		data, err := c.Cache.load(c.BaseURL, path)
+		switch {
+		case err == nil:
+			return decodeResult(data, result)
+		case c.Cache.Offline && errors.Is(err, ErrNotCached):
+			return fmt.Errorf("%w for %s (offline)", ErrNotCached, path)
+		case c.Cache.Offline:
+			return err
+		}
+	}
+
+	_, data, err := c.send(ctx, method, path, body, func(req *http.Request) {
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+	})
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return err
 	}
 
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(data), 200))
+	if err := decodeResult(data, result); err != nil {
+		return err
+	}
+	if cached {
+		c.Cache.store(c.BaseURL, path, data)
 	}
+	return nil
+}
 
-	if result != nil {
-		if err := json.Unmarshal(data, result); err != nil {
-			return fmt.Errorf("decoding response: %w", err)
-		}
+// decodeResult unmarshals a JSON response body into result, if non-nil. An
+// empty body leaves result unchanged.
+func decodeResult(data []byte, result interface{}) error {
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
 	}
 	return nil
 }
 
 // Get performs a GET request.
 func (c *Client) Get(path string, result interface{}) error {
-	return c.request(http.MethodGet, path, nil, result)
+	return c.request(BaseContext, http.MethodGet, path, nil, result)
+}
+
+// GetContext performs a GET request that is aborted, retries included, when
+// ctx is done.
+func (c *Client) GetContext(ctx context.Context, path string, result interface{}) error {
+	return c.request(ctx, http.MethodGet, path, nil, result)
 }
 
 // Post performs a POST request with a JSON body.
 func (c *Client) Post(path string, body io.Reader, result interface{}) error {
-	return c.request(http.MethodPost, path, body, result)
+	return c.request(BaseContext, http.MethodPost, path, body, result)
 }
 
 // Put performs a PUT request with a JSON body.
 func (c *Client) Put(path string, body io.Reader, result interface{}) error {
-	return c.request(http.MethodPut, path, body, result)
+	return c.request(BaseContext, http.MethodPut, path, body, result)
 }
 
 // Patch performs a PATCH request with a JSON body.
 func (c *Client) Patch(path string, body io.Reader, result interface{}) error {
-	return c.request(http.MethodPatch, path, body, result)
+	return c.request(BaseContext, http.MethodPatch, path, body, result)
 }
 
 // Delete performs a DELETE request.
 func (c *Client) Delete(path string, result interface{}) error {
-	return c.request(http.MethodDelete, path, nil, result)
+	return c.request(BaseContext, http.MethodDelete, path, nil, result)
 }
 
 // GetRaw performs a GET request returning raw bytes (for exports).
 func (c *Client) GetRaw(path string) ([]byte, string, error) {
-	u, err := url.JoinPath(c.BaseURL, path)
+	resp, data, err := c.send(BaseContext, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("invalid URL: %w", err)
+		return nil, "", err
 	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
 
-	req, err := http.NewRequest(http.MethodGet, u, nil)
-	if err != nil {
-		return nil, "", fmt.Errorf("creating request: %w", err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else if c.APIKey != "" {
-		req.Header.Set("X-API-Key", c.APIKey)
+// send performs a request and returns the response with its body read.
+// Idempotent requests (GET, DELETE) that fail to connect or are answered with
+// a 5xx are retried up to c.Retries times, backing off between
+// attempts; the error after the last attempt says how many were made. Bodies
+// larger than c.MaxResponseSize fail with ErrResponseTooLarge. prepare, if
+// non-nil, adjusts each request before it is sent.
+func (c *Client) send(ctx context.Context, method, path string, body io.Reader, prepare func(*http.Request)) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := c.newRequest(ctx, method, path, body)
+		if err != nil {
+			return nil, nil, err
+		}
+		if prepare != nil {
+			prepare(req)
+		}
+
+		// A failure worth retrying leaves err set and falls through, with the
+		// status (0 if the connection failed) and Retry-After to retry by.
+		var status int
+		var retryAfter string
+		resp, err := c.do(req)
+		if err == nil {
+			var data []byte
+			data, err = readLimited(resp.Body, c.MaxResponseSize, method, path)
+			resp.Body.Close()
+			switch {
+			case errors.Is(err, ErrResponseTooLarge):
+				return nil, nil, err
+			case err != nil:
+				err = fmt.Errorf("reading response: %w", err)
+			case resp.StatusCode < 400:
+				return resp, data, nil
+			case attempt > 1 && method == http.MethodDelete && resp.StatusCode == http.StatusNotFound:
+				// An earlier attempt deleted it before its answer was lost.
+				return resp, nil, nil
+			case !retryableStatus(resp.StatusCode):
+				err = newHTTPError(resp, string(data))
+				if attempt > 1 {
+					err = fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+				}
+				return nil, nil, err
+			default:
+				status, retryAfter = resp.StatusCode, resp.Header.Get("Retry-After")
+				err = newHTTPError(resp, string(data))
+			}
+		} else if !retryableError(err) {
+			return nil, nil, err
+		}
+
+		if !retryableMethod(method) || attempt > c.Retries || ctx.Err() != nil {
+			if attempt > 1 {
+				err = fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+			}
+			return nil, nil, err
+		}
+		wait := retryWait(attempt, c.RetryWait, retryAfter)
+		retries.Add(1)
+		if RetryNotify != nil {
+			RetryNotify(attempt, c.Retries, status, wait)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(wait):
+		}
 	}
-	req.Header.Set("User-Agent", "SpiderFoot-CLI/"+Version)
+}
 
-	resp, err := c.HTTPClient.Do(req)
+// Stream performs a request and returns the response without reading its
+// body, so that large request and response bodies can be streamed rather than
+// buffered in memory. The caller must close the response body.
+func (c *Client) Stream(method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := c.StreamRequest(method, path, body, contentType)
 	if err != nil {
-		return nil, "", fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	data, err := io.ReadAll(resp.Body)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
+	return resp, nil
+}
 
-	if resp.StatusCode >= 400 {
-		return nil, "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(data), 200))
+// StreamRequest builds, without sending, the authenticated request Stream
+// would make.
+func (c *Client) StreamRequest(method, path string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := c.newRequest(BaseContext, method, path, body)
+	if err != nil {
+		return nil, err
 	}
-
-	ct := resp.Header.Get("Content-Type")
-	return data, ct, nil
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	// Send regular files with a Content-Length rather than chunked encoding.
+	if f, ok := body.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+			req.ContentLength = fi.Size()
+		}
+	}
+	return req, nil
 }
 
 func truncate(s string, n int) string {
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResumeNotify, if set, is called before a failed download is tried again,
+// with the attempt that failed, the retry budget, the bytes kept from earlier
+// attempts and whether the rest is fetched with a range request (otherwise
+// the download starts over).
+var ResumeNotify func(attempt, max int, kept int64, ranged bool)
+
+// download tracks one transfer across attempts.
+type download struct {
+	w       io.Writer
+	written int64
+	// total is the full length announced by the server, or -1 if unknown.
+	total int64
+	// validator is the ETag or Last-Modified of the first response, sent back
+	// in If-Range so a changed export is downloaded afresh.
+	validator string
+	// ranged reports whether the server accepts byte range requests.
+	ranged bool
+}
+
+// restart discards what was received so far, failing if w cannot be emptied.
+func (d *download) restart() error {
+	if d.written > 0 {
+		switch w := d.w.(type) {
+		case *os.File:
+			if err := w.Truncate(0); err != nil {
+				return fmt.Errorf("discarding partial download: %w", err)
+			}
+			if _, err := w.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("discarding partial download: %w", err)
+			}
+		case interface{ Reset() }:
+			w.Reset()
+		default:
+			return errors.New("partial download cannot be discarded to start over")
+		}
+	}
+	d.written, d.total, d.validator, d.ranged = 0, -1, "", false
+	return nil
+}
+
+// Download streams the body of a GET request to w and returns the number of
+// bytes written. ctx bounds the whole transfer, including reading the body,
+// so cancelling it aborts a download in progress.
+//
+// A transfer that fails partway is tried again up to c.Retries times. When
+// the server accepts range requests the download continues from the last byte
+// received; otherwise it starts over, which needs w to be an *os.File or to
+// have a Reset method. The received length is checked against the length the
+// server announced.
+func (c *Client) Download(ctx context.Context, path string, w io.Writer) (int64, error) {
+	d := &download{w: w, total: -1}
+	for attempt := 1; ; attempt++ {
+		err := c.downloadAttempt(ctx, path, d)
+		if err == nil {
+			return d.written, nil
+		}
+		var herr *HTTPError
+		if ctx.Err() != nil || errors.As(err, &herr) || attempt > c.Retries {
+			return d.written, err
+		}
+		ranged := d.ranged && d.written > 0
+		if !ranged {
+			if rerr := d.restart(); rerr != nil {
+				return d.written, err
+			}
+		}
+		retries.Add(1)
+		if ResumeNotify != nil {
+			ResumeNotify(attempt, c.Retries, d.written, ranged)
+		}
+		select {
+		case <-ctx.Done():
+			return d.written, err
+		case <-time.After(retryWait(attempt, c.RetryWait, "")):
+		}
+	}
+}
+
+// downloadAttempt makes one request for the rest of d and copies the body
+// into it.
+func (c *Client) downloadAttempt(ctx context.Context, path string, d *download) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	if d.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.written))
+		if d.validator != "" {
+			req.Header.Set("If-Range", d.validator)
+		}
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != d.written || (d.total >= 0 && total >= 0 && total != d.total) {
+			d.ranged = false
+			return fmt.Errorf("server resumed at an unexpected range %q", resp.Header.Get("Content-Range"))
+		}
+		if total >= 0 {
+			d.total = total
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && d.written > 0:
+		d.ranged = false
+		return errors.New("server rejected the resume range")
+	case resp.StatusCode >= 400:
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return newHTTPError(resp, string(data))
+	default:
+		// A full response, either the first or because the server ignored
+		// the range or the export changed since.
+		if err := d.restart(); err != nil {
+			return err
+		}
+		d.total = resp.ContentLength
+		d.ranged = strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes")
+		if d.validator = resp.Header.Get("ETag"); d.validator == "" {
+			d.validator = resp.Header.Get("Last-Modified")
+		}
+	}
+
+	n, err := io.Copy(d.w, resp.Body)
+	d.written += n
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if d.total >= 0 && d.written != d.total {
+		return fmt.Errorf("incomplete download: received %d of %d bytes", d.written, d.total)
+	}
+	return nil
+}
+
+// parseContentRange parses a "bytes start-end/total" Content-Range header.
+// total is -1 when the server gives it as "*".
+func parseContentRange(h string) (start, total int64, ok bool) {
+	spec, found := strings.CutPrefix(h, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, total, true
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxResponseSize is the default --max-response-size.
+const DefaultMaxResponseSize = "100MB"
+
+// ErrResponseTooLarge is returned when a response body exceeds the client's
+// MaxResponseSize.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// sizeUnits are the suffixes ParseSize accepts, longest first so "MB" is
+// matched before "B".
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// ParseSize parses a byte size such as 512KB, 100MB or 2G (binary units,
+// case-insensitive) or a plain number of bytes. 0 means no limit.
+func ParseSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper, unit = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 || n > (1<<62)/unit {
+		return 0, fmt.Errorf("%q is not a valid size (expected e.g. 512KB, 100MB or 2GB)", s)
+	}
+	return n * unit, nil
+}
+
+// formatSize renders n bytes in the largest unit ParseSize accepts that
+// divides it exactly, e.g. "100MB".
+func formatSize(n int64) string {
+	for _, u := range sizeUnits[:3] {
+		if n%u.bytes == 0 {
+			return fmt.Sprintf("%d%s", n/u.bytes, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// readLimited reads all of r, failing with ErrResponseTooLarge once it has
+// read more than limit bytes. A limit of 0 or less reads without a limit.
+func readLimited(r io.Reader, limit int64, method, path string) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %s %s returned more than the --max-response-size of %s; raise the limit, or fetch large data with 'sf export' or 'sf scan events --stream'",
+			ErrResponseTooLarge, method, path, formatSize(limit))
+	}
+	return data, nil
+}
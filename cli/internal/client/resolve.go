@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ParseResolve parses --resolve entries of the form host:addr or
+// host:port:addr (as accepted by curl) into a map keyed by "host" or
+// "host:port". addr may be an IPv6 address in brackets.
+func ParseResolve(entries []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		host, rest, ok := strings.Cut(entry, ":")
+		if !ok || host == "" || rest == "" {
+			return nil, fmt.Errorf("invalid --resolve %q (expected host:ip or host:port:ip)", entry)
+		}
+		key := strings.ToLower(host)
+		addr := rest
+		if net.ParseIP(strings.Trim(rest, "[]")) == nil {
+			port, a, ok := strings.Cut(rest, ":")
+			if !ok || port == "" {
+				return nil, fmt.Errorf("invalid --resolve %q (expected host:ip or host:port:ip)", entry)
+			}
+			key = net.JoinHostPort(key, port)
+			addr = a
+		}
+		ip := net.ParseIP(strings.Trim(addr, "[]"))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid --resolve %q: %q is not an IP address", entry, addr)
+		}
+		overrides[key] = ip.String()
+	}
+	return overrides, nil
+}
+
+// resolveDialer returns a DialContext func that connects to the overridden
+// address for hosts in overrides and resolves everything else normally.
+func resolveDialer(overrides map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			host = strings.ToLower(host)
+			if ip, ok := overrides[net.JoinHostPort(host, port)]; ok {
+				addr = net.JoinHostPort(ip, port)
+			} else if ip, ok := overrides[host]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten converts an arbitrary decoded JSON value into a header and rows.
+// An array yields one row per element, as does an envelope object with
+// exactly one array of objects (such as {"scans": [...], "total": 2});
+// anything else yields a single row. Nested object keys become dotted column
+// names (config.max_threads), arrays of scalars are joined with "; ", and
+// arrays containing objects are indexed (hosts.0.name).
+func Flatten(v interface{}) ([]string, [][]string) {
+	items, ok := v.([]interface{})
+	if !ok {
+		items = envelopeItems(v)
+	}
+	if items == nil {
+		items = []interface{}{v}
+	}
+
+	flat := make([]map[string]string, 0, len(items))
+	seen := make(map[string]bool)
+	var header []string
+	for _, item := range items {
+		m := make(map[string]string)
+		flattenInto("", item, m)
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				header = append(header, k)
+			}
+		}
+		flat = append(flat, m)
+	}
+	sort.Strings(header)
+
+	rows := make([][]string, 0, len(flat))
+	for _, m := range flat {
+		row := make([]string, len(header))
+		for i, k := range header {
+			row[i] = m[k]
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+func flattenInto(prefix string, v interface{}, out map[string]string) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 && prefix != "" {
+			out[prefix] = ""
+		}
+		for k, child := range val {
+			flattenInto(key(k), child, out)
+		}
+	case []interface{}:
+		if allScalars(val) {
+			parts := make([]string, len(val))
+			for i, child := range val {
+				parts[i] = scalarString(child)
+			}
+			out[keyOrValue(prefix)] = strings.Join(parts, "; ")
+			return
+		}
+		for i, child := range val {
+			flattenInto(key(fmt.Sprint(i)), child, out)
+		}
+	default:
+		out[keyOrValue(prefix)] = scalarString(val)
+	}
+}
+
+// envelopeItems returns the array of objects held by an envelope object, or
+// nil if v is not one. An envelope has exactly one such array and otherwise
+// only numeric, boolean, or null metadata (total, page, has_next, ...); a
+// string field marks v as a detail object rather than a list wrapper.
+func envelopeItems(v interface{}) []interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var items []interface{}
+	for _, val := range m {
+		switch val := val.(type) {
+		case float64, bool, nil:
+			continue
+		case []interface{}:
+			if items != nil || allScalars(val) {
+				return nil
+			}
+			items = val
+		default:
+			return nil
+		}
+	}
+	return items
+}
+
+func allScalars(items []interface{}) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// keyOrValue names the column for a top-level scalar, which has no key.
+func keyOrValue(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return prefix
+}
+
+func scalarString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// PrintFlattened renders v via Flatten as CSV or, for any other format, a table.
+func PrintFlattened(v interface{}) {
+	header, rows := Flatten(v)
+	if Current() == CSV {
+		PrintCSV(header, rows)
+		return
+	}
+	PrintTable(header, rows)
+}
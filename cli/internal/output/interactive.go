@@ -0,0 +1,56 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/viper"
+)
+
+// uiMu serializes writes to the terminal between the spinner and status
+// messages. spinnerActive is set while a spinner owns the stderr line.
+var (
+	uiMu          sync.Mutex
+	spinnerActive bool
+)
+
+// Interactive reports whether animated UI (spinners, progress bars, live
+// redrawing tables) may be drawn on f. It is false when f is not a terminal,
+// with --quiet or --no-progress, and in CI (the CI environment variable is
+// set to anything but "false" or "0").
+func Interactive(f *os.File) bool {
+	if viper.GetBool("quiet") || viper.GetBool("no_progress") {
+		return false
+	}
+	if ci := os.Getenv("CI"); ci != "" && ci != "false" && ci != "0" {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// withTerminal runs print while holding the terminal, first clearing any
+// spinner line so the message starts at column 0. The spinner redraws itself
+// below the message on its next frame.
+func withTerminal(print func()) {
+	uiMu.Lock()
+	defer uiMu.Unlock()
+	if spinnerActive {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	print()
+}
+
+// RestoreTerminal undoes terminal state an interrupted command may leave
+// behind: it clears a spinner line, resets colors and shows the cursor.
+func RestoreTerminal() {
+	uiMu.Lock()
+	defer uiMu.Unlock()
+	if spinnerActive {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		fmt.Print("\033[0m\033[?25h")
+	}
+}
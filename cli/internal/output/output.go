@@ -1,4 +1,4 @@
-// Package output provides formatted output helpers (table, JSON, CSV).
+// Package output provides formatted output helpers (table, JSON, YAML, CSV).
 package output
 
 import (
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/viper"
 )
 
@@ -19,39 +23,217 @@ type Format string
 const (
 	Table Format = "table"
 	JSON  Format = "json"
+	YAML  Format = "yaml"
 	CSV   Format = "csv"
+	// Auto selects Table on a terminal and the "auto_output" format
+	// (JSON by default) when stdout is redirected.
+	Auto Format = "auto"
+	// DOT and GeoJSON are only valid for the commands that produce them
+	// ('modules tree' and 'scan events'), which pass them to CheckFormat;
+	// Current reports Table for them.
+	DOT     Format = "dot"
+	GeoJSON Format = "geojson"
 )
 
-// Current returns the user-selected output format.
+// CheckFormat validates the "output" and "auto_output" settings, so a typo
+// is reported, with the closest format suggested, instead of quietly
+// printing a table. extra lists the formats the running command supports
+// beyond the common ones.
+func CheckFormat(extra ...Format) error {
+	valid := append([]Format{Auto, Table, JSON, YAML, CSV}, extra...)
+	if err := checkFormat("output", viper.GetString("output"), valid); err != nil {
+		return err
+	}
+	return checkFormat("auto_output", viper.GetString("auto_output"), []Format{Table, JSON, YAML, CSV})
+}
+
+// parseFormat normalizes a format setting; "yml" is short for YAML.
+func parseFormat(value string) Format {
+	f := Format(strings.ToLower(value))
+	if f == "yml" {
+		return YAML
+	}
+	return f
+}
+
+func checkFormat(setting, value string, valid []Format) error {
+	f := parseFormat(value)
+	if f == "" {
+		return nil
+	}
+	names := make([]string, len(valid))
+	best, bestDist := "", 3
+	for i, v := range valid {
+		if v == f {
+			return nil
+		}
+		names[i] = string(v)
+		if d := editDistance(string(f), string(v)); d < bestDist {
+			best, bestDist = string(v), d
+		}
+	}
+	msg := fmt.Sprintf("invalid %s %q", setting, value)
+	if best != "" {
+		msg += fmt.Sprintf(": did you mean %q?", best)
+	}
+	return fmt.Errorf("%s (valid: %s)", msg, strings.Join(names, ", "))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// Current returns the user-selected output format, resolving Auto against
+// whether stdout is a terminal.
 func Current() Format {
-	f := strings.ToLower(viper.GetString("output"))
-	switch Format(f) {
-	case JSON, CSV:
-		return Format(f)
+	f := parseFormat(viper.GetString("output"))
+	if f == Auto || f == "" {
+		if isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+			return Table
+		}
+		f = parseFormat(viper.GetString("auto_output"))
+		if f == "" {
+			f = JSON
+		}
+	}
+	switch f {
+	case JSON, YAML, CSV:
+		return f
 	default:
 		return Table
 	}
 }
 
-// PrintJSON marshals v to indented JSON and prints it.
+// PrintJSON marshals v to JSON and prints it. Output is indented unless
+// --compact is set, in which case it is written on a single line.
 func PrintJSON(v interface{}) {
 	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
+	if !viper.GetBool("compact") {
+		enc.SetIndent("", "  ")
+	}
 	_ = enc.Encode(v)
 }
 
-// PrintCSV writes header + rows as CSV.
+// errorResp is the JSON shape of a failed command.
+type errorResp struct {
+	Error     string `json:"error"`
+	Code      int    `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// PrintError reports a failed command. In JSON and YAML mode the error is
+// written to stdout as {"error": "...", "code": N, "request_id": "..."} so
+// scripts can parse success and failure uniformly; request_id is left out when
+// the server sent none. Otherwise it is written to stderr as plain text.
+func PrintError(err error, code int, requestID string) {
+	if Structured() {
+		PrintStructured(errorResp{Error: err.Error(), Code: code, RequestID: requestID})
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// csvMu guards csvOut, the writer PrintCSV is writing through, so that an
+// interrupt can flush it between rows.
+var (
+	csvMu  sync.Mutex
+	csvOut *csv.Writer
+)
+
+// PrintCSV writes header + rows as CSV. If the command is interrupted while
+// rows are being written, FlushPending ends the output at a complete row.
 func PrintCSV(header []string, rows [][]string) {
 	w := csv.NewWriter(os.Stdout)
-	_ = w.Write(header)
-	for _, r := range rows {
+	csvMu.Lock()
+	csvOut = w
+	csvMu.Unlock()
+	defer func() {
+		csvMu.Lock()
+		defer csvMu.Unlock()
+		if csvOut == w {
+			w.Flush()
+			csvOut = nil
+		}
+	}()
+
+	for _, r := range append([][]string{header}, rows...) {
+		csvMu.Lock()
+		if csvOut != w {
+			csvMu.Unlock()
+			return
+		}
 		_ = w.Write(r)
+		csvMu.Unlock()
+	}
+}
+
+// FlushPending flushes CSV output still being written when the process is
+// about to exit on a signal, so it ends at a complete row rather than
+// mid-buffer, and stops any further rows.
+func FlushPending() {
+	csvMu.Lock()
+	defer csvMu.Unlock()
+	if csvOut != nil {
+		csvOut.Flush()
+		csvOut = nil
 	}
-	w.Flush()
 }
 
-// PrintTable renders a simple aligned table to stdout.
+// Table alignments accepted by the "align" setting.
+const (
+	AlignAuto  = "auto"
+	AlignLeft  = "left"
+	AlignRight = "right"
+)
+
+// CheckTableSettings validates the table formatting settings, which come from
+// flags (--max-col-width, --no-headers, --align, --wrap) or the config file.
+func CheckTableSettings() error {
+	if viper.GetInt("max_col_width") < 0 {
+		return fmt.Errorf("max_col_width must not be negative")
+	}
+	switch a := strings.ToLower(viper.GetString("align")); a {
+	case "", AlignAuto, AlignLeft, AlignRight:
+		return nil
+	default:
+		return fmt.Errorf("invalid align %q: use %s, %s or %s", a, AlignAuto, AlignLeft, AlignRight)
+	}
+}
+
+// PrintTable renders a simple aligned table to stdout. Columns whose values
+// are all numeric are right-aligned and everything else is left-aligned,
+// unless the "align" setting forces one side. Columns are capped at
+// max_col_width characters when set. On an interactive terminal (see
+// Interactive) the widest columns are also narrowed until the table fits its
+// width, unless --full-width is set; piped output keeps full widths. With --wrap, long cells wrap onto
+// continuation lines within their row; otherwise cells wider than their
+// column are shortened with "…". no_headers leaves out the header and its
+// separator.
 func PrintTable(header []string, rows [][]string) {
+	PrintTableFooter(header, rows, nil)
+}
+
+// PrintTableFooter is PrintTable with footer rows, such as totals, printed
+// below a rule after the data rows. Footer cells count towards column widths
+// but not towards deciding which columns are numeric.
+func PrintTableFooter(header []string, rows, footer [][]string) {
 	if len(rows) == 0 {
 		fmt.Println("No results.")
 		return
@@ -60,41 +242,157 @@ func PrintTable(header []string, rows [][]string) {
 	// Calculate column widths
 	widths := make([]int, len(header))
 	for i, h := range header {
-		widths[i] = len(h)
+		widths[i] = visibleLen(h)
 	}
-	for _, row := range rows {
+	for _, row := range append(rows[:len(rows):len(rows)], footer...) {
 		for i := 0; i < len(row) && i < len(widths); i++ {
-			if len(row[i]) > widths[i] {
-				widths[i] = len(row[i])
+			if n := visibleLen(row[i]); n > widths[i] {
+				widths[i] = n
 			}
 		}
 	}
 
-	// Print header
-	noColor := viper.GetBool("no_color")
-	printRow(os.Stdout, header, widths, !noColor)
-	printSep(os.Stdout, widths)
+	var right []bool
+	switch strings.ToLower(viper.GetString("align")) {
+	case AlignLeft:
+		right = make([]bool, len(header))
+	case AlignRight:
+		right = make([]bool, len(header))
+		for i := range right {
+			right[i] = true
+		}
+	default:
+		right = numericColumns(len(header), rows)
+	}
+	wrap := Wrap()
+	if limit := viper.GetInt("max_col_width"); limit > 0 {
+		for i := range widths {
+			widths[i] = min(widths[i], max(limit, visibleLen(header[i])))
+		}
+	}
+	if wrap || (!viper.GetBool("full_width") && Interactive(os.Stdout)) {
+		fitWidths(header, widths, right, terminalWidth())
+	}
+
+	if !viper.GetBool("no_headers") {
+		noColor := viper.GetBool("no_color")
+		printRow(os.Stdout, header, widths, right, !noColor)
+		printSep(os.Stdout, widths)
+	}
 	for _, row := range rows {
-		printRow(os.Stdout, row, widths, false)
+		if wrap {
+			printWrappedRow(os.Stdout, row, widths, right)
+			continue
+		}
+		printRow(os.Stdout, clipRow(row, widths), widths, right, false)
+	}
+	if len(footer) > 0 {
+		printSep(os.Stdout, widths)
+		for _, row := range footer {
+			printRow(os.Stdout, clipRow(row, widths), widths, right, !viper.GetBool("no_color"))
+		}
 	}
 }
 
-func printRow(w io.Writer, cols []string, widths []int, bold bool) {
+// clipRow shortens cells wider than their column, ending them with "…".
+// Colored cells are left alone.
+func clipRow(row []string, widths []int) []string {
+	var out []string
+	for i, cell := range row {
+		if i >= len(widths) || visibleLen(cell) <= widths[i] || strings.Contains(cell, "\x1b") {
+			continue
+		}
+		if out == nil {
+			out = append([]string(nil), row...)
+		}
+		out[i] = Truncate(cell, widths[i])
+	}
+	if out == nil {
+		return row
+	}
+	return out
+}
+
+// printWrappedRow prints a row whose cells may span several lines, keeping
+// each continuation line aligned with its column.
+func printWrappedRow(w io.Writer, cols []string, widths []int, right []bool) {
+	cells := make([][]string, len(cols))
+	height := 1
+	for i, col := range cols {
+		width := 0
+		if i < len(widths) {
+			width = widths[i]
+		}
+		cells[i] = wrapCell(col, width)
+		height = max(height, len(cells[i]))
+	}
+	for l := 0; l < height; l++ {
+		line := make([]string, len(cols))
+		for i, cell := range cells {
+			if l < len(cell) {
+				line[i] = cell[l]
+			}
+		}
+		printRow(w, line, widths, right, false)
+	}
+}
+
+func printRow(w io.Writer, cols []string, widths []int, right []bool, bold bool) {
 	for i, col := range cols {
 		width := 12
 		if i < len(widths) {
 			width = widths[i]
 		}
+		pad := strings.Repeat(" ", max(0, width-visibleLen(col)))
 		if bold {
-			s := color.New(color.Bold).Sprintf("%-*s", width, col)
-			fmt.Fprintf(w, "  %s", s)
+			col = color.New(color.Bold).Sprint(col)
+		}
+		if i < len(right) && right[i] {
+			fmt.Fprintf(w, "  %s%s", pad, col)
 		} else {
-			fmt.Fprintf(w, "  %-*s", width, col)
+			fmt.Fprintf(w, "  %s%s", col, pad)
 		}
 	}
 	fmt.Fprintln(w)
 }
 
+// numericRe matches counts, decimals, percentages, and ratios such as "3/10".
+var numericRe = regexp.MustCompile(`^[-+]?[0-9][0-9,]*(\.[0-9]+)?%?(/[0-9]+)?$`)
+
+// numericColumns reports, for each of n columns, whether every non-blank cell
+// is numeric. Blank and placeholder ("—", "-") cells are ignored, but a column
+// needs at least one numeric value to count.
+func numericColumns(n int, rows [][]string) []bool {
+	numeric := make([]bool, n)
+	seen := make([]bool, n)
+	for i := range numeric {
+		numeric[i] = true
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < n; i++ {
+			cell := strings.TrimSpace(ansiRe.ReplaceAllString(row[i], ""))
+			if cell == "" || cell == "—" || cell == "-" {
+				continue
+			}
+			seen[i] = true
+			if !numericRe.MatchString(cell) {
+				numeric[i] = false
+			}
+		}
+	}
+	for i := range numeric {
+		numeric[i] = numeric[i] && seen[i]
+	}
+	return numeric
+}
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// visibleLen returns the display width of s, ignoring ANSI color codes.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiRe.ReplaceAllString(s, ""))
+}
+
 func printSep(w io.Writer, widths []int) {
 	for _, width := range widths {
 		fmt.Fprintf(w, "  %s", strings.Repeat("─", width))
@@ -104,27 +402,44 @@ func printSep(w io.Writer, widths []int) {
 
 // Success prints a green success message.
 func Success(msg string, args ...interface{}) {
-	if viper.GetBool("no_color") {
-		fmt.Printf("✓ "+msg+"\n", args...)
-		return
-	}
-	color.Green("✓ "+msg, args...)
+	withTerminal(func() {
+		if viper.GetBool("no_color") {
+			fmt.Printf("✓ "+msg+"\n", args...)
+			return
+		}
+		color.Green("✓ "+msg, args...)
+	})
 }
 
 // Error prints a red error message.
 func Error(msg string, args ...interface{}) {
-	if viper.GetBool("no_color") {
-		fmt.Fprintf(os.Stderr, "✗ "+msg+"\n", args...)
-		return
-	}
-	color.Red("✗ "+msg, args...)
+	withTerminal(func() {
+		if viper.GetBool("no_color") {
+			fmt.Fprintf(os.Stderr, "✗ "+msg+"\n", args...)
+			return
+		}
+		color.Red("✗ "+msg, args...)
+	})
 }
 
 // Warn prints a yellow warning message.
 func Warn(msg string, args ...interface{}) {
-	if viper.GetBool("no_color") {
-		fmt.Printf("⚠ "+msg+"\n", args...)
+	withTerminal(func() {
+		if viper.GetBool("no_color") {
+			fmt.Printf("⚠ "+msg+"\n", args...)
+			return
+		}
+		color.Yellow("⚠ "+msg, args...)
+	})
+}
+
+// Note prints an informational message to stderr, so it never mixes with
+// command output. It is suppressed by --quiet.
+func Note(msg string, args ...interface{}) {
+	if viper.GetBool("quiet") {
 		return
 	}
-	color.Yellow("⚠ "+msg, args...)
+	withTerminal(func() {
+		fmt.Fprintf(os.Stderr, msg+"\n", args...)
+	})
 }
@@ -0,0 +1,27 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+)
+
+// ProgressBar renders percent as "[████████░░] 80%" when stdout is an
+// Interactive color terminal, or as the bare "80%" otherwise.
+func ProgressBar(percent, width int) string {
+	label := fmt.Sprintf("%d%%", percent)
+	if viper.GetBool("no_color") || color.NoColor || !Interactive(os.Stdout) {
+		return label
+	}
+
+	filled := min(max(percent, 0), 100) * width / 100
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	c := color.New(color.FgYellow)
+	if percent >= 100 {
+		c = color.New(color.FgGreen)
+	}
+	return fmt.Sprintf("[%s] %s", c.Sprint(bar), label)
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// StartSpinner shows an animated spinner followed by msg on stderr until the
+// returned stop function is called. It does nothing unless stderr is
+// Interactive, or while another spinner is running. stop is safe to call more
+// than once.
+func StartSpinner(msg string) (stop func()) {
+	if !Interactive(os.Stderr) {
+		return func() {}
+	}
+	uiMu.Lock()
+	if spinnerActive {
+		uiMu.Unlock()
+		return func() {}
+	}
+	spinnerActive = true
+	uiMu.Unlock()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			uiMu.Lock()
+			fmt.Fprintf(os.Stderr, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], msg)
+			uiMu.Unlock()
+			select {
+			case <-done:
+				// Clear the spinner line.
+				uiMu.Lock()
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				spinnerActive = false
+				uiMu.Unlock()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// minWrapWidth is the narrowest a column is shrunk to when wrapping.
+const minWrapWidth = 10
+
+// Wrap reports whether tables wrap long cells onto extra lines (--wrap).
+// Commands that shorten cells for display should leave them whole when set.
+func Wrap() bool {
+	return viper.GetBool("wrap")
+}
+
+// Truncate shortens s to at most n characters, ending it with "…" when it
+// was cut.
+func Truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(r[:max(n, 0)])
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// terminalWidth returns the width of the terminal on stdout, falling back to
+// $COLUMNS and then 80 when stdout is not a terminal.
+func terminalWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 80
+}
+
+// TerminalHeight returns the height of the terminal on stdout, falling back
+// to $LINES and then 24 when stdout is not a terminal.
+func TerminalHeight() int {
+	if _, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil && h > 0 {
+		return h
+	}
+	if h, err := strconv.Atoi(os.Getenv("LINES")); err == nil && h > 0 {
+		return h
+	}
+	return 24
+}
+
+// fitWidths narrows the widest left-aligned columns, one character at a time,
+// until the table fits in total characters. Columns never shrink below their
+// header or minWrapWidth, so a very narrow terminal may still overflow.
+func fitWidths(header []string, widths []int, right []bool, total int) {
+	avail := total - 2*len(widths)
+	for {
+		sum := 0
+		for _, w := range widths {
+			sum += w
+		}
+		if sum <= avail {
+			return
+		}
+		widest := -1
+		for i, w := range widths {
+			if right[i] || w <= max(visibleLen(header[i]), minWrapWidth) {
+				continue
+			}
+			if widest < 0 || w > widths[widest] {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+	}
+}
+
+// wrapCell splits s into lines of at most width characters, breaking between
+// words where possible. Cells containing ANSI color codes are not wrapped.
+func wrapCell(s string, width int) []string {
+	if width <= 0 || visibleLen(s) <= width || strings.Contains(s, "\x1b") {
+		return []string{s}
+	}
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		for len(w) > width {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(w) == 0:
+		case len(line) == 0:
+			line = w
+		case len(line)+1+len(w) <= width:
+			line = append(append(line, ' '), w...)
+		default:
+			lines = append(lines, string(line))
+			line = w
+		}
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
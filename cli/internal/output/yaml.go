@@ -0,0 +1,61 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrintYAML prints v as YAML. v goes through its JSON encoding first, so the
+// keys and their order match PrintJSON: struct fields in declaration order
+// under their json names, map keys sorted. Multi-line strings are written as
+// block scalars.
+func PrintYAML(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	// JSON is YAML, and decoding into a node keeps the key order.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	blockStyle(&doc)
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	_ = enc.Encode(&doc)
+	_ = enc.Close()
+}
+
+// blockStyle turns the flow style and quoting decoded from JSON into plain
+// block YAML, quoting only where the encoder needs to.
+func blockStyle(n *yaml.Node) {
+	switch {
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!str" && strings.Contains(n.Value, "\n"):
+		n.Style = yaml.LiteralStyle
+	default:
+		n.Style = 0
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// PrintStructured prints v in the selected structured format: YAML with
+// -o yaml, JSON otherwise.
+func PrintStructured(v interface{}) {
+	if Current() == YAML {
+		PrintYAML(v)
+		return
+	}
+	PrintJSON(v)
+}
+
+// Structured reports whether the selected format is JSON or YAML, for which
+// commands print their data rather than messages and tables.
+func Structured() bool {
+	f := Current()
+	return f == JSON || f == YAML
+}